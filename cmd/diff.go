@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/lovelaze/nebula-sync/internal/service"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var diffFormat string
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show what a sync would change on the replicas",
+	Run: func(cmd *cobra.Command, args []string) {
+		readEnvFile()
+
+		if err := config.ValidateFormat(diffFormat); err != nil {
+			log.Fatal().Err(err).Msg("Invalid output format")
+		}
+
+		service, err := service.Init(configFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to initialize service")
+		}
+
+		if err = service.Diff(cmd.Context(), os.Stdout, diffFormat); err != nil {
+			log.Fatal().Err(err).Msg("Failed to diff replicas")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&envFile, "env-file", "", "Read env from `.env` file")
+	diffCmd.Flags().StringVar(&configFile, "config", "", "Read config from a YAML or TOML `file`, env vars take precedence")
+	diffCmd.Flags().StringVar(&diffFormat, "format", config.FormatText, "Output format, `text` or json")
+}
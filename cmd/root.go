@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"github.com/lovelaze/nebula-sync/internal/log"
 	"github.com/lovelaze/nebula-sync/version"
 	"github.com/spf13/cobra"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 var rootCmd = &cobra.Command{
@@ -11,8 +15,13 @@ var rootCmd = &cobra.Command{
 	Version: version.Version,
 }
 
+// Execute runs the command until it is done or the process receives an interrupt or termination signal,
+// which cancels the context of the command.
 func Execute() {
-	rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
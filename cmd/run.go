@@ -7,7 +7,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var envFile string
+var (
+	envFile    string
+	configFile string
+)
 
 var runCmd = &cobra.Command{
 	Use:   "run",
@@ -15,12 +18,12 @@ var runCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		readEnvFile()
 
-		service, err := service.Init()
+		service, err := service.Init(configFile)
 		if err != nil {
 			log.Fatal().Err(err).Msg("Failed to initialize service")
 		}
 
-		if err = service.Run(); err != nil {
+		if err = service.Run(cmd.Context()); err != nil {
 			log.Fatal().Err(err).Msg("Failed to run service")
 		}
 	},
@@ -30,6 +33,7 @@ func init() {
 	rootCmd.AddCommand(runCmd)
 
 	runCmd.Flags().StringVar(&envFile, "env-file", "", "Read env from `.env` file")
+	runCmd.Flags().StringVar(&configFile, "config", "", "Read config from a YAML or TOML `file`, env vars take precedence")
 }
 
 func readEnvFile() {
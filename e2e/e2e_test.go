@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"context"
 	"github.com/lovelaze/nebula-sync/internal/service"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -27,9 +28,9 @@ func (suite *testSuite) Test_FullSync() {
 	suite.T().Setenv("REPLICAS", suite.ph2.EnvString())
 	suite.T().Setenv("FULL_SYNC", "true")
 
-	s, err := service.Init()
+	s, err := service.Init("")
 	require.NoError(suite.T(), err)
-	err = s.Run()
+	err = s.Run(context.Background())
 	require.NoError(suite.T(), err)
 }
 
@@ -54,8 +55,8 @@ func (suite *testSuite) Test_ManualSync() {
 	suite.T().Setenv("SYNC_GRAVITY_CLIENT", "true")
 	suite.T().Setenv("SYNC_GRAVITY_CLIENT_BY_GROUP", "true")
 
-	s, err := service.Init()
+	s, err := service.Init("")
 	require.NoError(suite.T(), err)
-	err = s.Run()
+	err = s.Run(context.Background())
 	require.NoError(suite.T(), err)
 }
@@ -1,21 +1,193 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/lovelaze/nebula-sync/internal/pihole/model"
 	"github.com/rs/zerolog/log"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
 )
 
 type Config struct {
-	Primary      model.PiHole   `required:"true" envconfig:"PRIMARY"`
-	Replicas     []model.PiHole `required:"true" envconfig:"REPLICAS"`
-	FullSync     bool           `required:"true" envconfig:"FULL_SYNC"`
-	Cron         *string        `envconfig:"CRON"`
-	SyncSettings *SyncSettings  `ignored:"true"`
+	Primary              model.PiHole   `envconfig:"PRIMARY"`
+	Replicas             []model.PiHole `envconfig:"REPLICAS"`
+	FullSync             bool           `envconfig:"FULL_SYNC"`
+	PrimaryPasswordFile  string         `envconfig:"PRIMARY_PASSWORD_FILE"`
+	ReplicaPasswordsFile string         `envconfig:"REPLICA_PASSWORDS_FILE"`
+	Cron                 *string        `envconfig:"CRON"`
+	Concurrency          int            `default:"4" envconfig:"SYNC_CONCURRENCY"`
+	FailurePolicy        string         `default:"fail-fast" envconfig:"FAILURE_POLICY"`
+	FailureQuorum        int            `envconfig:"FAILURE_QUORUM"`
+	GravityStrategy      string         `default:"teleporter" envconfig:"GRAVITY_SYNC_STRATEGY"`
+	BlockingSyncInterval time.Duration  `envconfig:"BLOCKING_SYNC_INTERVAL"`
+	DryRun               bool           `default:"false" envconfig:"DRY_RUN"`
+	DryRunFormat         string         `default:"text" envconfig:"DRY_RUN_FORMAT"`
+	ChangeDetect         bool           `default:"false" envconfig:"CHANGE_DETECTION"`
+	StateDir             string         `envconfig:"STATE_DIR"`
+	Backup               bool           `default:"false" envconfig:"BACKUP"`
+	BackupDir            string         `envconfig:"BACKUP_DIR"`
+	BackupKeep           int            `default:"5" envconfig:"BACKUP_KEEP"`
+	Verify               bool           `default:"false" envconfig:"VERIFY"`
+	Overrides            Overrides      `envconfig:"REPLICA_OVERRIDES"`
+	ServerAddr           string         `envconfig:"SERVER_ADDR"`
+	ReadyTolerance       time.Duration  `envconfig:"READY_TOLERANCE"`
+	ApiToken             string         `envconfig:"API_TOKEN"`
+	ApiSyncPolicy        string         `default:"reject" envconfig:"API_SYNC_POLICY"`
+	Retry                Retry          `envconfig:"RETRY"`
+	Timeouts             Timeouts       `envconfig:"TIMEOUT"`
+	Session              Session        `envconfig:"SESSION"`
+	PostSync             PostSync       `envconfig:"POST_SYNC"`
+	Notify               Notify         `envconfig:"NOTIFY"`
+	SyncSettings         *SyncSettings  `ignored:"true"`
 }
 
+// Retry configures how failed Pi-hole API requests are retried.
+type Retry struct {
+	MaxAttempts int           `default:"3" envconfig:"MAX_ATTEMPTS"`
+	BaseDelay   time.Duration `default:"1s" envconfig:"BASE_DELAY"`
+	Jitter      float64       `default:"0.2" envconfig:"JITTER"`
+}
+
+func (r *Retry) validate() error {
+	if r.MaxAttempts < 1 {
+		return fmt.Errorf("RETRY_MAX_ATTEMPTS: must be at least 1, got %d", r.MaxAttempts)
+	}
+	if r.BaseDelay < 0 {
+		return fmt.Errorf("RETRY_BASE_DELAY: must not be negative, got %s", r.BaseDelay)
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return fmt.Errorf("RETRY_JITTER: must be between 0 and 1, got %g", r.Jitter)
+	}
+	return nil
+}
+
+// Session configures how Pi-hole API sessions are reused.
+type Session struct {
+	Reuse bool   `default:"true" envconfig:"REUSE"`
+	Dir   string `envconfig:"DIR"`
+}
+
+// Timeouts are the default timeouts of Pi-hole API requests, instances can set their own in the config file.
+type Timeouts struct {
+	Connect    time.Duration `default:"5s" envconfig:"CONNECT"`
+	Request    time.Duration `default:"30s" envconfig:"REQUEST"`
+	Teleporter time.Duration `default:"5m" envconfig:"TELEPORTER"`
+}
+
+func (t *Timeouts) validate() error {
+	if t.Connect < 0 || t.Request < 0 || t.Teleporter < 0 {
+		return errors.New("TIMEOUT_*: timeouts must not be negative")
+	}
+	return nil
+}
+
+// apply sets the timeouts of piHole that it does not set itself.
+func (t *Timeouts) apply(piHole *model.PiHole) {
+	if piHole.Timeouts.Connect == 0 {
+		piHole.Timeouts.Connect = t.Connect
+	}
+	if piHole.Timeouts.Request == 0 {
+		piHole.Timeouts.Request = t.Request
+	}
+	if piHole.Timeouts.Teleporter == 0 {
+		piHole.Timeouts.Teleporter = t.Teleporter
+	}
+}
+
+// PostSync are the default actions run on each replica after it was synced, replicas can set their own in the config file.
+type PostSync struct {
+	Gravity        string        `default:"never" envconfig:"GRAVITY"`
+	RestartDNS     string        `default:"never" envconfig:"RESTART_DNS"`
+	FlushNetwork   string        `default:"never" envconfig:"FLUSH_NETWORK"`
+	FlushLogs      string        `default:"never" envconfig:"FLUSH_LOGS"`
+	GravityTimeout time.Duration `default:"10m" envconfig:"GRAVITY_TIMEOUT"`
+	Timeout        time.Duration `default:"1m" envconfig:"TIMEOUT"`
+}
+
+func (p *PostSync) validate() error {
+	postSync := model.PostSync(*p)
+	if err := postSync.Validate(); err != nil {
+		return fmt.Errorf("POST_SYNC_*: %w", err)
+	}
+	return nil
+}
+
+// apply sets the actions of piHole that it does not set itself.
+func (p *PostSync) apply(piHole *model.PiHole) {
+	postSync := &piHole.PostSync
+	if postSync.Gravity == "" {
+		postSync.Gravity = p.Gravity
+	}
+	if postSync.RestartDNS == "" {
+		postSync.RestartDNS = p.RestartDNS
+	}
+	if postSync.FlushNetwork == "" {
+		postSync.FlushNetwork = p.FlushNetwork
+	}
+	if postSync.FlushLogs == "" {
+		postSync.FlushLogs = p.FlushLogs
+	}
+	if postSync.GravityTimeout == 0 {
+		postSync.GravityTimeout = p.GravityTimeout
+	}
+	if postSync.Timeout == 0 {
+		postSync.Timeout = p.Timeout
+	}
+}
+
+// Overrides maps a replica, by url as given in REPLICAS or by name, to its config overrides.
+type Overrides map[string]ReplicaOverrides
+
+type ReplicaOverrides struct {
+	Overrides map[string]interface{} `json:"overrides"`
+	Vars      map[string]string      `json:"vars"`
+}
+
+func (o *Overrides) Decode(value string) error {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(o)
+}
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+const (
+	// FailFast stops syncing replicas after the first replica fails.
+	FailFast = "fail-fast"
+	// FailContinue syncs every replica and fails if any replica failed.
+	FailContinue = "continue"
+	// FailQuorum syncs every replica and fails if fewer than FailureQuorum replicas succeeded.
+	FailQuorum = "quorum"
+)
+
+const (
+	// GravityTeleporter syncs gravity by importing the teleporter archive of the primary, replacing the gravity tables.
+	GravityTeleporter = "teleporter"
+	// GravityAPI syncs gravity through the REST API, applying only the items that differ.
+	GravityAPI = "api"
+)
+
+const (
+	// SyncPolicyReject rejects API sync requests while a sync is running.
+	SyncPolicyReject = "reject"
+	// SyncPolicyQueue queues API sync requests while a sync is running.
+	SyncPolicyQueue = "queue"
+)
+
+// ConfigSections are the Pi-hole config sections that can be synced.
+var ConfigSections = []string{"dns", "dhcp", "ntp", "resolver", "database", "webserver", "files", "misc", "debug"}
+
 type ManualGravity struct {
 	DHCPLeases        bool `default:"false" envconfig:"SYNC_GRAVITY_DHCP_LEASES"`
 	Group             bool `default:"false" envconfig:"SYNC_GRAVITY_GROUP"`
@@ -33,10 +205,15 @@ type ManualConfig struct {
 	NTP       bool `default:"false" envconfig:"SYNC_CONFIG_NTP"`
 	Resolver  bool `default:"false" envconfig:"SYNC_CONFIG_RESOLVER"`
 	Database  bool `default:"false" envconfig:"SYNC_CONFIG_DATABASE"`
-	Webserver bool `default:"false" ignored:"true"` // ignore for now
-	Files     bool `default:"false" ignored:"true"` // ignore for now
+	Webserver bool `default:"false" envconfig:"SYNC_CONFIG_WEBSERVER"`
+	Files     bool `default:"false" envconfig:"SYNC_CONFIG_FILES"`
 	Misc      bool `default:"false" envconfig:"SYNC_CONFIG_MISC"`
 	Debug     bool `default:"false" envconfig:"SYNC_CONFIG_DEBUG"`
+
+	Include []string `envconfig:"SYNC_CONFIG_INCLUDE"`
+	Exclude []string `envconfig:"SYNC_CONFIG_EXCLUDE"`
+	// Sensitive opts in to syncing the model.SensitivePaths it matches.
+	Sensitive []string `envconfig:"SYNC_CONFIG_SENSITIVE"`
 }
 
 type SyncSettings struct {
@@ -44,20 +221,197 @@ type SyncSettings struct {
 	Config  *ManualConfig  `ignored:"true"`
 }
 
+// Load loads the config from env vars.
 func (c *Config) Load() error {
+	return c.LoadFile("")
+}
+
+// LoadFile loads the config from a YAML or TOML file, if filename is not empty, and env vars.
+// Env vars take precedence over values in the file.
+func (c *Config) LoadFile(filename string) error {
 	if err := envconfig.Process("", c); err != nil {
 		return fmt.Errorf("env vars: %w", err)
 	}
 
+	var file *fileConfig
+	if filename != "" {
+		var err error
+		if file, err = readConfigFile(filename); err != nil {
+			return fmt.Errorf("config file %s: %w", filename, err)
+		}
+		if err := file.apply(c); err != nil {
+			return fmt.Errorf("config file %s: %w", filename, err)
+		}
+	}
+
+	if err := c.validateRequired(file); err != nil {
+		return err
+	}
+
+	if err := c.validatePasswordParts(); err != nil {
+		return err
+	}
+
+	if err := c.applyPasswordFiles(); err != nil {
+		return err
+	}
+
+	if err := ValidateFormat(c.DryRunFormat); err != nil {
+		return fmt.Errorf("DRY_RUN_FORMAT: %w", err)
+	}
+
+	if c.ApiToken != "" && c.ServerAddr == "" {
+		return fmt.Errorf("API_TOKEN: requires SERVER_ADDR")
+	}
+
+	if c.ApiSyncPolicy != SyncPolicyReject && c.ApiSyncPolicy != SyncPolicyQueue {
+		return fmt.Errorf("API_SYNC_POLICY: invalid policy %q, expected %q or %q", c.ApiSyncPolicy, SyncPolicyReject, SyncPolicyQueue)
+	}
+
+	if err := c.validateFailurePolicy(); err != nil {
+		return err
+	}
+
+	if c.GravityStrategy != GravityTeleporter && c.GravityStrategy != GravityAPI {
+		return fmt.Errorf("GRAVITY_SYNC_STRATEGY: invalid strategy %q, expected %q or %q", c.GravityStrategy, GravityTeleporter, GravityAPI)
+	}
+
+	if err := c.validateBlockingSync(); err != nil {
+		return err
+	}
+
+	if err := c.Retry.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Notify.validate(); err != nil {
+		return err
+	}
+
+	if err := c.applyOverrides(); err != nil {
+		return fmt.Errorf("REPLICA_OVERRIDES: %w", err)
+	}
+
+	if err := c.Timeouts.validate(); err != nil {
+		return err
+	}
+	c.Timeouts.apply(&c.Primary)
+	for i := range c.Replicas {
+		c.Timeouts.apply(&c.Replicas[i])
+	}
+
+	if err := c.PostSync.validate(); err != nil {
+		return err
+	}
+	for i := range c.Replicas {
+		c.PostSync.apply(&c.Replicas[i])
+	}
+
 	if !c.FullSync {
-		if err := c.loadSyncSettings(); err != nil {
+		if err := c.loadSyncSettings(file); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (c *Config) loadSyncSettings() error {
+// validateRequired checks that the required keys are set by env vars or the config file.
+func (c *Config) validateRequired(file *fileConfig) error {
+	if !envSet("PRIMARY") && (file == nil || file.Primary == nil) {
+		return fmt.Errorf("required key PRIMARY missing value")
+	}
+	if !envSet("REPLICAS") && (file == nil || file.Replicas == nil) {
+		return fmt.Errorf("required key REPLICAS missing value")
+	}
+	if !envSet("FULL_SYNC") && (file == nil || file.Sync == nil || file.Sync.Full == nil) {
+		return fmt.Errorf("required key FULL_SYNC missing value")
+	}
+	return nil
+}
+
+// validateBlockingSync checks that the blocking state can be polled, which needs a long-running service and sessions
+// that are kept between polls instead of logging in on every poll.
+func (c *Config) validateBlockingSync() error {
+	if c.BlockingSyncInterval == 0 {
+		return nil
+	}
+	if c.BlockingSyncInterval < time.Second {
+		return fmt.Errorf("BLOCKING_SYNC_INTERVAL: must be at least 1s, got %s", c.BlockingSyncInterval)
+	}
+	if c.Cron == nil {
+		return fmt.Errorf("BLOCKING_SYNC_INTERVAL: requires CRON")
+	}
+	if !c.Session.Reuse {
+		return fmt.Errorf("BLOCKING_SYNC_INTERVAL: requires SESSION_REUSE")
+	}
+	return nil
+}
+
+func (c *Config) validateFailurePolicy() error {
+	switch c.FailurePolicy {
+	case FailFast, FailContinue:
+		return nil
+	case FailQuorum:
+		if c.FailureQuorum < 1 || c.FailureQuorum > len(c.Replicas) {
+			return fmt.Errorf("FAILURE_QUORUM: must be between 1 and the number of replicas (%d), got %d", len(c.Replicas), c.FailureQuorum)
+		}
+		return nil
+	default:
+		return fmt.Errorf("FAILURE_POLICY: invalid policy %q, expected %q, %q or %q", c.FailurePolicy, FailFast, FailContinue, FailQuorum)
+	}
+}
+
+// validatePasswordParts checks that PRIMARY and REPLICAS have a |password part, which may only be left out if the
+// password is read from PRIMARY_PASSWORD_FILE or REPLICA_PASSWORDS_FILE.
+func (c *Config) validatePasswordParts() error {
+	if primary, ok := os.LookupEnv("PRIMARY"); ok && c.PrimaryPasswordFile == "" && !strings.Contains(primary, "|") {
+		return errors.New("PRIMARY: invalid pihole format, expected <url>|<password> or PRIMARY_PASSWORD_FILE")
+	}
+
+	if replicas, ok := os.LookupEnv("REPLICAS"); ok && c.ReplicaPasswordsFile == "" {
+		for _, replica := range strings.Split(replicas, ",") {
+			if !strings.Contains(replica, "|") {
+				return fmt.Errorf("REPLICAS: invalid pihole format %q, expected <url>|<password> or REPLICA_PASSWORDS_FILE", replica)
+			}
+		}
+	}
+	return nil
+}
+
+// applyPasswordFiles sets the passwords to references to the password files, which are read on every sync.
+func (c *Config) applyPasswordFiles() error {
+	if c.PrimaryPasswordFile != "" {
+		c.Primary.Password = model.FileSecret(c.PrimaryPasswordFile)
+		c.Primary.PasswordLine = 0
+	}
+
+	if c.ReplicaPasswordsFile != "" {
+		content, err := os.ReadFile(c.ReplicaPasswordsFile)
+		if err != nil {
+			return fmt.Errorf("REPLICA_PASSWORDS_FILE: %w", err)
+		}
+		if lines := model.SecretLines(string(content)); len(lines) != len(c.Replicas) {
+			return fmt.Errorf("REPLICA_PASSWORDS_FILE: found %d passwords, expected one per line for each of the %d replicas", len(lines), len(c.Replicas))
+		}
+
+		for i := range c.Replicas {
+			c.Replicas[i].Password = model.FileSecret(c.ReplicaPasswordsFile)
+			c.Replicas[i].PasswordLine = i + 1
+		}
+	}
+
+	if err := c.Primary.ValidateAuth(); err != nil {
+		return fmt.Errorf("primary %w", err)
+	}
+	for _, replica := range c.Replicas {
+		if err := replica.ValidateAuth(); err != nil {
+			return fmt.Errorf("replica %s %w", replica.Name, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) loadSyncSettings(file *fileConfig) error {
 	manualGravity := ManualGravity{}
 	if err := envconfig.Process("", &manualGravity); err != nil {
 		return fmt.Errorf("gravity env vars: %w", err)
@@ -68,11 +422,94 @@ func (c *Config) loadSyncSettings() error {
 		return fmt.Errorf("config env vars: %w", err)
 	}
 
-	c.SyncSettings = &SyncSettings{
+	settings := SyncSettings{
 		Gravity: &manualGravity,
 		Config:  &manualConfig,
 	}
+	if file != nil {
+		file.applySyncSettings(&settings)
+	}
+
+	if err := validatePatterns(manualConfig.Include); err != nil {
+		return fmt.Errorf("SYNC_CONFIG_INCLUDE: %w", err)
+	}
+	if err := validatePatterns(manualConfig.Exclude); err != nil {
+		return fmt.Errorf("SYNC_CONFIG_EXCLUDE: %w", err)
+	}
+	if err := validatePatterns(manualConfig.Sensitive); err != nil {
+		return fmt.Errorf("SYNC_CONFIG_SENSITIVE: %w", err)
+	}
+
+	c.SyncSettings = &settings
+	return nil
+}
 
+// applyOverrides attaches the overrides to their replicas.
+func (c *Config) applyOverrides() error {
+	for key, overrides := range c.Overrides {
+		i := slices.IndexFunc(c.Replicas, func(replica model.PiHole) bool {
+			return replica.Name == key || (replica.Url != nil && strings.TrimSuffix(replica.Url.String(), "/") == strings.TrimSuffix(key, "/"))
+		})
+		if i < 0 {
+			return fmt.Errorf("unknown replica %s", key)
+		}
+
+		if err := validateOverrides(overrides.Overrides); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+
+		c.Replicas[i].Overrides = overrides.Overrides
+		c.Replicas[i].Vars = overrides.Vars
+	}
+	return nil
+}
+
+func validateOverrides(overrides map[string]interface{}) error {
+	for path, value := range overrides {
+		segments := strings.Split(path, ".")
+		if len(segments) < 2 || slices.Contains(segments, "") {
+			return fmt.Errorf("invalid override path %q", path)
+		}
+		if !slices.Contains(ConfigSections, segments[0]) {
+			return fmt.Errorf("invalid override path %q: %q is not a syncable config section", path, segments[0])
+		}
+
+		if text, ok := value.(string); ok {
+			if _, err := template.New(path).Parse(text); err != nil {
+				return fmt.Errorf("invalid override template %q: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validatePatterns checks that config path patterns are well-formed and start with a syncable section.
+func validatePatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		segments := strings.Split(strings.TrimPrefix(pattern, "!"), ".")
+		for _, segment := range segments {
+			if segment == "" {
+				return fmt.Errorf("invalid pattern %q: empty path segment", pattern)
+			}
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+
+		if !slices.ContainsFunc(ConfigSections, func(section string) bool {
+			matched, _ := path.Match(segments[0], section)
+			return matched
+		}) {
+			return fmt.Errorf("invalid pattern %q: no syncable config section matches %q", pattern, segments[0])
+		}
+	}
+	return nil
+}
+
+func ValidateFormat(format string) error {
+	if format != FormatText && format != FormatJSON {
+		return fmt.Errorf("invalid format %q, expected %q or %q", format, FormatText, FormatJSON)
+	}
 	return nil
 }
 
@@ -102,5 +539,5 @@ func (c *Config) String() string {
 		}
 	}
 
-	return fmt.Sprintf("primary=%s, replicas=%s, fullSync=%t, cron=%s, syncSettings=%s", c.Primary.Url, replicas, c.FullSync, cron, syncSettings)
+	return fmt.Sprintf("primary=%s, replicas=%s, fullSync=%t, cron=%s, concurrency=%d, failurePolicy=%s, failureQuorum=%d, gravityStrategy=%s, blockingSyncInterval=%s, dryRun=%t, changeDetection=%t, stateDir=%s, backup=%t, backupDir=%s, backupKeep=%d, verify=%t, retry=%+v, timeouts=%+v, session=%+v, postSync=%+v, serverAddr=%s, readyTolerance=%s, api=%t, apiSyncPolicy=%s, syncSettings=%s", c.Primary.Url, replicas, c.FullSync, cron, c.Concurrency, c.FailurePolicy, c.FailureQuorum, c.GravityStrategy, c.BlockingSyncInterval, c.DryRun, c.ChangeDetect, c.StateDir, c.Backup, c.BackupDir, c.BackupKeep, c.Verify, c.Retry, c.Timeouts, c.Session, c.PostSync, c.ServerAddr, c.ReadyTolerance, c.ApiToken != "", c.ApiSyncPolicy, syncSettings)
 }
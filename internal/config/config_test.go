@@ -1,10 +1,13 @@
 package config
 
 import (
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestConfig_Load(t *testing.T) {
@@ -26,6 +29,64 @@ func TestConfig_Load(t *testing.T) {
 	assert.Equal(t, true, conf.FullSync)
 	assert.Equal(t, "* * * * *", *conf.Cron)
 	assert.Nil(t, conf.SyncSettings)
+	assert.Equal(t, 4, conf.Concurrency)
+	assert.Equal(t, FailFast, conf.FailurePolicy)
+	assert.False(t, conf.DryRun)
+	assert.Equal(t, FormatText, conf.DryRunFormat)
+}
+
+func TestConfig_Load_invalidDryRunFormat(t *testing.T) {
+	conf := Config{}
+
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+	t.Setenv("DRY_RUN", "true")
+	t.Setenv("DRY_RUN_FORMAT", "yaml")
+
+	err := conf.Load()
+	assert.ErrorContains(t, err, "DRY_RUN_FORMAT")
+}
+
+func TestConfig_Load_overrides(t *testing.T) {
+	conf := Config{}
+
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty,http://localhost:1339|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+	t.Setenv("REPLICA_OVERRIDES", `{
+		"http://localhost:1338": {"vars": {"subnet": "192.168.2"}, "overrides": {"dhcp.router": "{{ .Vars.subnet }}.1", "dhcp.active": false}},
+		"localhost:1339": {"overrides": {"dns.reply.host.IPv4": "192.168.3.2"}}
+	}`)
+
+	err := conf.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"dhcp.router": "{{ .Vars.subnet }}.1", "dhcp.active": false}, conf.Replicas[0].Overrides)
+	assert.Equal(t, map[string]string{"subnet": "192.168.2"}, conf.Replicas[0].Vars)
+	assert.Equal(t, map[string]interface{}{"dns.reply.host.IPv4": "192.168.3.2"}, conf.Replicas[1].Overrides)
+}
+
+func TestConfig_Load_invalidOverrides(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+
+	tests := map[string]string{
+		`{"http://localhost:9999": {"overrides": {"dhcp.router": "192.168.2.1"}}}`:     "unknown replica",
+		`{"http://localhost:1338": {"overrides": {"api.port": "8080"}}}`:               "not a syncable config section",
+		`{"http://localhost:1338": {"overrides": {"dhcp": "192.168.2.1"}}}`:            "invalid override path",
+		`{"http://localhost:1338": {"overrides": {"dhcp.router": "{{ .Vars.subnet"}}}`: "invalid override template",
+		`{"http://localhost:1338": {"values": {}}}`:                                    "unknown field",
+	}
+
+	for value, expected := range tests {
+		t.Setenv("REPLICA_OVERRIDES", value)
+
+		conf := Config{}
+		err := conf.Load()
+		assert.ErrorContains(t, err, expected, value)
+	}
 }
 
 func TestConfig_loadSyncSettings(t *testing.T) {
@@ -49,7 +110,7 @@ func TestConfig_loadSyncSettings(t *testing.T) {
 	t.Setenv("SYNC_GRAVITY_CLIENT", "true")
 	t.Setenv("SYNC_GRAVITY_CLIENT_BY_GROUP", "true")
 
-	err := conf.loadSyncSettings()
+	err := conf.loadSyncSettings(nil)
 	require.NoError(t, err)
 
 	assert.NotNil(t, conf.SyncSettings.Config)
@@ -73,6 +134,26 @@ func TestConfig_loadSyncSettings(t *testing.T) {
 	assert.True(t, conf.SyncSettings.Gravity.ClientByGroup)
 }
 
+func TestConfig_loadSyncSettings_patterns(t *testing.T) {
+	conf := Config{}
+
+	t.Setenv("SYNC_CONFIG_INCLUDE", "dns.*,!dns.interface,dhcp.hosts")
+	t.Setenv("SYNC_CONFIG_EXCLUDE", "dhcp.active")
+
+	err := conf.loadSyncSettings(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"dns.*", "!dns.interface", "dhcp.hosts"}, conf.SyncSettings.Config.Include)
+	assert.Equal(t, []string{"dhcp.active"}, conf.SyncSettings.Config.Exclude)
+}
+
+func Test_validatePatterns(t *testing.T) {
+	assert.NoError(t, validatePatterns([]string{"dns.*", "!dns.interface", "*.hosts", "d*"}))
+	assert.ErrorContains(t, validatePatterns([]string{"dns..hosts"}), "empty path segment")
+	assert.ErrorContains(t, validatePatterns([]string{"dns.[a"}), "syntax error")
+	assert.ErrorContains(t, validatePatterns([]string{"foo.bar"}), "no syncable config section")
+}
+
 func TestConfig_LoadEnvFile(t *testing.T) {
 	os.Clearenv()
 	err := LoadEnvFile("../../testdata/.env")
@@ -113,3 +194,274 @@ func TestConfig_LoadEnvFile_precedence(t *testing.T) {
 
 	assert.Equal(t, "0 0 * * *", os.Getenv("CRON"))
 }
+
+func TestConfig_Load_passwordFiles(t *testing.T) {
+	dir := t.TempDir()
+	primaryFile := filepath.Join(dir, "primary")
+	replicasFile := filepath.Join(dir, "replicas")
+	require.NoError(t, os.WriteFile(primaryFile, []byte("asdf\n"), 0o600))
+	require.NoError(t, os.WriteFile(replicasFile, []byte("qwerty\nzxcv\n"), 0o600))
+
+	t.Setenv("PRIMARY", "http://localhost:1337")
+	t.Setenv("REPLICAS", "http://localhost:1338,http://localhost:1339")
+	t.Setenv("FULL_SYNC", "true")
+	t.Setenv("PRIMARY_PASSWORD_FILE", primaryFile)
+	t.Setenv("REPLICA_PASSWORDS_FILE", replicasFile)
+
+	conf := Config{}
+	err := conf.Load()
+	require.NoError(t, err)
+
+	password, err := conf.Primary.ResolvePassword()
+	require.NoError(t, err)
+	assert.Equal(t, "asdf", password)
+
+	password, err = conf.Replicas[1].ResolvePassword()
+	require.NoError(t, err)
+	assert.Equal(t, "zxcv", password)
+
+	t.Setenv("REPLICAS", "http://localhost:1338")
+	err = conf.Load()
+	assert.ErrorContains(t, err, "REPLICA_PASSWORDS_FILE: found 2 passwords")
+}
+
+func TestConfig_Load_missingPassword(t *testing.T) {
+	primaryFile := filepath.Join(t.TempDir(), "primary")
+	require.NoError(t, os.WriteFile(primaryFile, []byte("asdf\n"), 0o600))
+
+	t.Setenv("PRIMARY", "http://localhost:1337")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty,http://localhost:1339")
+	t.Setenv("FULL_SYNC", "true")
+
+	conf := Config{}
+	err := conf.Load()
+	assert.ErrorContains(t, err, "PRIMARY: invalid pihole format")
+
+	t.Setenv("PRIMARY_PASSWORD_FILE", primaryFile)
+	err = conf.Load()
+	assert.ErrorContains(t, err, `REPLICAS: invalid pihole format "http://localhost:1339"`)
+
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty,http://localhost:1339|")
+	err = conf.Load()
+	require.NoError(t, err)
+	password, err := conf.Primary.ResolvePassword()
+	require.NoError(t, err)
+	assert.Equal(t, "asdf", password)
+	assert.Empty(t, conf.Replicas[1].Password)
+}
+
+func TestConfig_Load_passwordReference(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|env:PH1_PASSWORD")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+
+	conf := Config{}
+	err := conf.Load()
+	assert.ErrorContains(t, err, "secret env var PH1_PASSWORD not set")
+
+	t.Setenv("PH1_PASSWORD", "asdf")
+	err = conf.Load()
+	assert.NoError(t, err)
+}
+
+func TestConfig_Load_notify(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+	t.Setenv("NOTIFY_NTFY_URL", "https://ntfy.sh/nebula-sync")
+	t.Setenv("NOTIFY_NTFY_ON", "always")
+	t.Setenv("NOTIFY_SMTP_HOST", "smtp.example.com")
+	t.Setenv("NOTIFY_SMTP_FROM", "nebula-sync@example.com")
+	t.Setenv("NOTIFY_SMTP_TO", "admin@example.com,ops@example.com")
+
+	conf := Config{}
+	err := conf.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://ntfy.sh/nebula-sync", conf.Notify.Ntfy.URL)
+	assert.Equal(t, []string{NotifyAlways}, conf.Notify.Ntfy.On)
+	assert.Equal(t, []string{NotifyFailure, NotifyRecovery}, conf.Notify.Webhook.On)
+	assert.Equal(t, 587, conf.Notify.SMTP.Port)
+	assert.Equal(t, []string{"admin@example.com", "ops@example.com"}, conf.Notify.SMTP.To)
+
+	t.Setenv("NOTIFY_NTFY_ON", "sometimes")
+	err = conf.Load()
+	assert.ErrorContains(t, err, `NOTIFY_NTFY_ON: invalid trigger "sometimes"`)
+
+	t.Setenv("NOTIFY_NTFY_ON", "failure")
+	t.Setenv("NOTIFY_SMTP_TO", "")
+	err = conf.Load()
+	assert.ErrorContains(t, err, "NOTIFY_SMTP_TO are required")
+}
+
+func TestConfig_Load_failurePolicy(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty,http://localhost:1339|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+	t.Setenv("FAILURE_POLICY", "quorum")
+	t.Setenv("FAILURE_QUORUM", "1")
+
+	conf := Config{}
+	err := conf.Load()
+	require.NoError(t, err)
+	assert.Equal(t, FailQuorum, conf.FailurePolicy)
+	assert.Equal(t, 1, conf.FailureQuorum)
+
+	t.Setenv("FAILURE_QUORUM", "3")
+	err = conf.Load()
+	assert.ErrorContains(t, err, "FAILURE_QUORUM: must be between 1 and the number of replicas (2), got 3")
+
+	t.Setenv("FAILURE_POLICY", "sometimes")
+	err = conf.Load()
+	assert.ErrorContains(t, err, `FAILURE_POLICY: invalid policy "sometimes"`)
+}
+
+func TestConfig_Load_gravityStrategy(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+
+	conf := Config{}
+	err := conf.Load()
+	require.NoError(t, err)
+	assert.Equal(t, GravityTeleporter, conf.GravityStrategy)
+
+	t.Setenv("GRAVITY_SYNC_STRATEGY", "api")
+	err = conf.Load()
+	require.NoError(t, err)
+	assert.Equal(t, GravityAPI, conf.GravityStrategy)
+
+	t.Setenv("GRAVITY_SYNC_STRATEGY", "rsync")
+	err = conf.Load()
+	assert.ErrorContains(t, err, `GRAVITY_SYNC_STRATEGY: invalid strategy "rsync"`)
+}
+
+func TestConfig_Load_blockingSync(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+	t.Setenv("BLOCKING_SYNC_INTERVAL", "5s")
+
+	conf := Config{}
+	err := conf.Load()
+	assert.ErrorContains(t, err, "BLOCKING_SYNC_INTERVAL: requires CRON")
+
+	t.Setenv("CRON", "0 * * * *")
+	err = conf.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, conf.BlockingSyncInterval)
+
+	t.Setenv("SESSION_REUSE", "false")
+	err = conf.Load()
+	assert.ErrorContains(t, err, "BLOCKING_SYNC_INTERVAL: requires SESSION_REUSE")
+
+	t.Setenv("BLOCKING_SYNC_INTERVAL", "100ms")
+	err = conf.Load()
+	assert.ErrorContains(t, err, "BLOCKING_SYNC_INTERVAL: must be at least 1s")
+}
+
+func TestConfig_Load_apiToken(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+	t.Setenv("API_TOKEN", "s3cr3t")
+
+	conf := Config{}
+	err := conf.Load()
+	assert.ErrorContains(t, err, "API_TOKEN: requires SERVER_ADDR")
+
+	t.Setenv("SERVER_ADDR", ":8080")
+	err = conf.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", conf.ApiToken)
+}
+
+func TestConfig_Load_retry(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+
+	conf := Config{}
+	err := conf.Load()
+	require.NoError(t, err)
+	assert.Equal(t, Retry{MaxAttempts: 3, BaseDelay: time.Second, Jitter: 0.2}, conf.Retry)
+
+	t.Setenv("RETRY_MAX_ATTEMPTS", "0")
+	err = conf.Load()
+	assert.ErrorContains(t, err, "RETRY_MAX_ATTEMPTS: must be at least 1")
+
+	t.Setenv("RETRY_MAX_ATTEMPTS", "5")
+	t.Setenv("RETRY_JITTER", "1.5")
+	err = conf.Load()
+	assert.ErrorContains(t, err, "RETRY_JITTER: must be between 0 and 1")
+}
+
+func TestConfig_Load_timeouts(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+	t.Setenv("TIMEOUT_TELEPORTER", "15m")
+
+	conf := Config{}
+	err := conf.Load()
+	require.NoError(t, err)
+
+	expected := model.Timeouts{Connect: 5 * time.Second, Request: 30 * time.Second, Teleporter: 15 * time.Minute}
+	assert.Equal(t, expected, conf.Primary.Timeouts)
+	assert.Equal(t, expected, conf.Replicas[0].Timeouts)
+
+	t.Setenv("TIMEOUT_REQUEST", "-1s")
+	err = conf.Load()
+	assert.ErrorContains(t, err, "timeouts must not be negative")
+}
+
+func TestConfig_Load_postSync(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+	t.Setenv("POST_SYNC_GRAVITY", "changed")
+
+	conf := Config{}
+	err := conf.Load()
+	require.NoError(t, err)
+
+	expected := model.PostSync{Gravity: model.ActionChanged, RestartDNS: model.ActionNever, FlushNetwork: model.ActionNever, FlushLogs: model.ActionNever, GravityTimeout: 10 * time.Minute, Timeout: time.Minute}
+	assert.Equal(t, expected, conf.Replicas[0].PostSync)
+
+	t.Setenv("POST_SYNC_RESTART_DNS", "sometimes")
+	err = conf.Load()
+	assert.ErrorContains(t, err, `POST_SYNC_*: restart_dns: invalid mode "sometimes"`)
+}
+
+func TestConfig_Load_session(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+
+	conf := Config{}
+	err := conf.Load()
+	require.NoError(t, err)
+	assert.Equal(t, Session{Reuse: true}, conf.Session)
+
+	t.Setenv("SESSION_REUSE", "false")
+	t.Setenv("SESSION_DIR", "/data/sessions")
+	err = conf.Load()
+	require.NoError(t, err)
+	assert.Equal(t, Session{Reuse: false, Dir: "/data/sessions"}, conf.Session)
+}
+
+func TestConfig_Load_appPassword(t *testing.T) {
+	t.Setenv("PRIMARY", "http://localhost:1337?app_password=true|asdf")
+	t.Setenv("REPLICAS", "http://localhost:1338?totp_secret=JBSWY3DPEHPK3PXP|qwerty")
+	t.Setenv("FULL_SYNC", "true")
+
+	conf := Config{}
+	err := conf.Load()
+	assert.ErrorContains(t, err, "primary password: not a Pi-hole app password")
+
+	t.Setenv("PRIMARY", "http://localhost:1337?app_password=true|Z3G0xQ2v0Ykq9c4mW3kXl5yH8bT1nR7eJ6aP2sU4dF0=")
+	err = conf.Load()
+	require.NoError(t, err)
+	assert.True(t, conf.Primary.AppPassword)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", conf.Replicas[0].TotpSecret)
+}
@@ -0,0 +1,572 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// fileConfig is the structure of a YAML or TOML config file. Fields are pointers so that values
+// not set in the file can be told apart from zero values, env vars take precedence over set values.
+type fileConfig struct {
+	Primary  *fileInstance  `yaml:"primary" toml:"primary"`
+	Replicas []fileInstance `yaml:"replicas" toml:"replicas"`
+	Schedule *fileSchedule  `yaml:"schedule" toml:"schedule"`
+	Sync     *fileSync      `yaml:"sync" toml:"sync"`
+	Server   *fileServer    `yaml:"server" toml:"server"`
+	Retry    *fileRetry     `yaml:"retry" toml:"retry"`
+	Timeouts *fileTimeouts  `yaml:"timeouts" toml:"timeouts"`
+	Session  *fileSession   `yaml:"session" toml:"session"`
+	PostSync *filePostSync  `yaml:"post_sync" toml:"post_sync"`
+	Notify   *fileNotify    `yaml:"notify" toml:"notify"`
+}
+
+type fileTimeouts struct {
+	Connect    *string `yaml:"connect" toml:"connect"`
+	Request    *string `yaml:"request" toml:"request"`
+	Teleporter *string `yaml:"teleporter" toml:"teleporter"`
+}
+
+type fileRetry struct {
+	MaxAttempts *int     `yaml:"max_attempts" toml:"max_attempts"`
+	BaseDelay   *string  `yaml:"base_delay" toml:"base_delay"`
+	Jitter      *float64 `yaml:"jitter" toml:"jitter"`
+}
+
+type fileSession struct {
+	Reuse *bool   `yaml:"reuse" toml:"reuse"`
+	Dir   *string `yaml:"dir" toml:"dir"`
+}
+
+type filePostSync struct {
+	Gravity        *string `yaml:"gravity" toml:"gravity"`
+	RestartDNS     *string `yaml:"restart_dns" toml:"restart_dns"`
+	FlushNetwork   *string `yaml:"flush_network" toml:"flush_network"`
+	FlushLogs      *string `yaml:"flush_logs" toml:"flush_logs"`
+	GravityTimeout *string `yaml:"gravity_timeout" toml:"gravity_timeout"`
+	Timeout        *string `yaml:"timeout" toml:"timeout"`
+}
+
+type fileNotify struct {
+	Webhook *fileWebhookNotifier `yaml:"webhook" toml:"webhook"`
+	Slack   *fileWebhookNotifier `yaml:"slack" toml:"slack"`
+	Ntfy    *fileTokenNotifier   `yaml:"ntfy" toml:"ntfy"`
+	Gotify  *fileTokenNotifier   `yaml:"gotify" toml:"gotify"`
+	SMTP    *fileSMTPNotifier    `yaml:"smtp" toml:"smtp"`
+}
+
+type fileWebhookNotifier struct {
+	URL *string   `yaml:"url" toml:"url"`
+	On  *[]string `yaml:"on" toml:"on"`
+}
+
+type fileTokenNotifier struct {
+	URL   *string   `yaml:"url" toml:"url"`
+	Token *string   `yaml:"token" toml:"token"`
+	On    *[]string `yaml:"on" toml:"on"`
+}
+
+type fileSMTPNotifier struct {
+	Host     *string   `yaml:"host" toml:"host"`
+	Port     *int      `yaml:"port" toml:"port"`
+	Username *string   `yaml:"username" toml:"username"`
+	Password *string   `yaml:"password" toml:"password"`
+	From     *string   `yaml:"from" toml:"from"`
+	To       *[]string `yaml:"to" toml:"to"`
+	On       *[]string `yaml:"on" toml:"on"`
+}
+
+type fileServer struct {
+	Addr           *string `yaml:"addr" toml:"addr"`
+	ReadyTolerance *string `yaml:"ready_tolerance" toml:"ready_tolerance"`
+	ApiToken       *string `yaml:"api_token" toml:"api_token"`
+	ApiSyncPolicy  *string `yaml:"api_sync_policy" toml:"api_sync_policy"`
+}
+
+type fileInstance struct {
+	Name         string                 `yaml:"name" toml:"name"`
+	Url          string                 `yaml:"url" toml:"url"`
+	Password     string                 `yaml:"password" toml:"password"`
+	PasswordFile string                 `yaml:"password_file" toml:"password_file"`
+	AppPassword  bool                   `yaml:"app_password" toml:"app_password"`
+	TotpSecret   string                 `yaml:"totp_secret" toml:"totp_secret"`
+	Overrides    map[string]interface{} `yaml:"overrides" toml:"overrides"`
+	Vars         map[string]string      `yaml:"vars" toml:"vars"`
+	Timeouts     *fileTimeouts          `yaml:"timeouts" toml:"timeouts"`
+	TLS          *fileTLS               `yaml:"tls" toml:"tls"`
+	PostSync     *filePostSync          `yaml:"post_sync" toml:"post_sync"`
+}
+
+type fileTLS struct {
+	CAFile             string `yaml:"ca_file" toml:"ca_file"`
+	CertFile           string `yaml:"cert_file" toml:"cert_file"`
+	KeyFile            string `yaml:"key_file" toml:"key_file"`
+	ServerName         string `yaml:"server_name" toml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" toml:"insecure_skip_verify"`
+}
+
+type fileSchedule struct {
+	Cron *string `yaml:"cron" toml:"cron"`
+}
+
+type fileSync struct {
+	Full             *bool        `yaml:"full" toml:"full"`
+	Concurrency      *int         `yaml:"concurrency" toml:"concurrency"`
+	FailurePolicy    *string      `yaml:"failure_policy" toml:"failure_policy"`
+	FailureQuorum    *int         `yaml:"failure_quorum" toml:"failure_quorum"`
+	GravityStrategy  *string      `yaml:"gravity_strategy" toml:"gravity_strategy"`
+	BlockingInterval *string      `yaml:"blocking_interval" toml:"blocking_interval"`
+	DryRun           *bool        `yaml:"dry_run" toml:"dry_run"`
+	DryRunFormat     *string      `yaml:"dry_run_format" toml:"dry_run_format"`
+	ChangeDetection  *bool        `yaml:"change_detection" toml:"change_detection"`
+	StateDir         *string      `yaml:"state_dir" toml:"state_dir"`
+	Backup           *fileBackup  `yaml:"backup" toml:"backup"`
+	Verify           *bool        `yaml:"verify" toml:"verify"`
+	Config           *fileConfigs `yaml:"config" toml:"config"`
+	Gravity          *fileGravity `yaml:"gravity" toml:"gravity"`
+}
+
+type fileBackup struct {
+	Enabled *bool   `yaml:"enabled" toml:"enabled"`
+	Dir     *string `yaml:"dir" toml:"dir"`
+	Keep    *int    `yaml:"keep" toml:"keep"`
+}
+
+type fileConfigs struct {
+	DNS       *bool    `yaml:"dns" toml:"dns"`
+	DHCP      *bool    `yaml:"dhcp" toml:"dhcp"`
+	NTP       *bool    `yaml:"ntp" toml:"ntp"`
+	Resolver  *bool    `yaml:"resolver" toml:"resolver"`
+	Database  *bool    `yaml:"database" toml:"database"`
+	Webserver *bool    `yaml:"webserver" toml:"webserver"`
+	Files     *bool    `yaml:"files" toml:"files"`
+	Misc      *bool    `yaml:"misc" toml:"misc"`
+	Debug     *bool    `yaml:"debug" toml:"debug"`
+	Include   []string `yaml:"include" toml:"include"`
+	Exclude   []string `yaml:"exclude" toml:"exclude"`
+	Sensitive []string `yaml:"sensitive" toml:"sensitive"`
+}
+
+type fileGravity struct {
+	DHCPLeases        *bool `yaml:"dhcp_leases" toml:"dhcp_leases"`
+	Group             *bool `yaml:"group" toml:"group"`
+	Adlist            *bool `yaml:"ad_list" toml:"ad_list"`
+	AdlistByGroup     *bool `yaml:"ad_list_by_group" toml:"ad_list_by_group"`
+	Domainlist        *bool `yaml:"domain_list" toml:"domain_list"`
+	DomainlistByGroup *bool `yaml:"domain_list_by_group" toml:"domain_list_by_group"`
+	Client            *bool `yaml:"client" toml:"client"`
+	ClientByGroup     *bool `yaml:"client_by_group" toml:"client_by_group"`
+}
+
+func readConfigFile(filename string) (*fileConfig, error) {
+	log.Debug().Msgf("Loading config file: %s", filename)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	file := fileConfig{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	case ".toml":
+		metadata, err := toml.Decode(string(content), &file)
+		if err != nil {
+			return nil, err
+		}
+		if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, len(undecoded))
+			for i, key := range undecoded {
+				keys[i] = key.String()
+			}
+			return nil, fmt.Errorf("unknown keys: %s", strings.Join(keys, ", "))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file type %q, expected .yaml, .yml or .toml", filepath.Ext(filename))
+	}
+
+	if err := file.validate(); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (file *fileConfig) validate() error {
+	if file.Primary != nil {
+		if err := file.Primary.validate(); err != nil {
+			return fmt.Errorf("primary: %w", err)
+		}
+	}
+
+	names := make(map[string]bool, len(file.Replicas))
+	for i, replica := range file.Replicas {
+		if err := replica.validate(); err != nil {
+			return fmt.Errorf("replicas[%d]: %w", i, err)
+		}
+		if err := validateOverrides(replica.Overrides); err != nil {
+			return fmt.Errorf("replicas[%d]: %w", i, err)
+		}
+		if replica.Name != "" {
+			if names[replica.Name] {
+				return fmt.Errorf("replicas[%d]: duplicate name %q", i, replica.Name)
+			}
+			names[replica.Name] = true
+		}
+	}
+	return nil
+}
+
+func (instance *fileInstance) validate() error {
+	if instance.Url == "" {
+		return errors.New("url is required")
+	}
+	if instance.Password != "" && instance.PasswordFile != "" {
+		return errors.New("password and password_file are mutually exclusive")
+	}
+	if _, err := instance.timeouts(); err != nil {
+		return err
+	}
+	if _, err := instance.postSync(); err != nil {
+		return err
+	}
+	_, options, err := instance.parseUrl()
+	if err != nil {
+		return err
+	}
+	if options.AppPassword && options.TotpSecret != "" {
+		return errors.New("totp_secret cannot be used with app_password")
+	}
+	return nil
+}
+
+// parseUrl parses the url of the instance, with the options of its query overridden by the fields of the instance.
+func (instance *fileInstance) parseUrl() (*url.URL, model.UrlOptions, error) {
+	u, options, err := model.ParseUrl(instance.Url)
+	if err != nil {
+		return u, options, err
+	}
+
+	options.AppPassword = options.AppPassword || instance.AppPassword
+	if instance.TotpSecret != "" {
+		options.TotpSecret = instance.TotpSecret
+	}
+	if instance.TLS == nil {
+		return u, options, nil
+	}
+
+	tls := &options.TLS
+
+	if instance.TLS.CAFile != "" {
+		tls.CAFile = instance.TLS.CAFile
+	}
+	if instance.TLS.CertFile != "" {
+		tls.CertFile = instance.TLS.CertFile
+	}
+	if instance.TLS.KeyFile != "" {
+		tls.KeyFile = instance.TLS.KeyFile
+	}
+	if instance.TLS.ServerName != "" {
+		tls.ServerName = instance.TLS.ServerName
+	}
+	tls.InsecureSkipVerify = tls.InsecureSkipVerify || instance.TLS.InsecureSkipVerify
+
+	if err := tls.Validate(); err != nil {
+		return nil, options, fmt.Errorf("tls: %w", err)
+	}
+	return u, options, nil
+}
+
+// timeouts parses the timeouts of the instance, unset timeouts are zero and default to the global timeouts.
+func (instance *fileInstance) timeouts() (model.Timeouts, error) {
+	timeouts := model.Timeouts{}
+	if instance.Timeouts == nil {
+		return timeouts, nil
+	}
+
+	durations := []struct {
+		dst   *time.Duration
+		value *string
+		name  string
+	}{
+		{&timeouts.Connect, instance.Timeouts.Connect, "timeouts.connect"},
+		{&timeouts.Request, instance.Timeouts.Request, "timeouts.request"},
+		{&timeouts.Teleporter, instance.Timeouts.Teleporter, "timeouts.teleporter"},
+	}
+	for _, duration := range durations {
+		if duration.value == nil {
+			continue
+		}
+		d, err := time.ParseDuration(*duration.value)
+		if err != nil {
+			return timeouts, fmt.Errorf("%s: %w", duration.name, err)
+		}
+		if d <= 0 {
+			return timeouts, fmt.Errorf("%s: must be positive", duration.name)
+		}
+		*duration.dst = d
+	}
+	return timeouts, nil
+}
+
+// postSync parses the post-sync actions of the instance, unset values default to the global actions.
+func (instance *fileInstance) postSync() (model.PostSync, error) {
+	postSync := model.PostSync{}
+	if instance.PostSync == nil {
+		return postSync, nil
+	}
+
+	file := instance.PostSync
+	postSync.Gravity = valueOf(file.Gravity)
+	postSync.RestartDNS = valueOf(file.RestartDNS)
+	postSync.FlushNetwork = valueOf(file.FlushNetwork)
+	postSync.FlushLogs = valueOf(file.FlushLogs)
+	if file.GravityTimeout != nil {
+		d, err := time.ParseDuration(*file.GravityTimeout)
+		if err != nil {
+			return postSync, fmt.Errorf("post_sync.gravity_timeout: %w", err)
+		}
+		postSync.GravityTimeout = d
+	}
+	if file.Timeout != nil {
+		d, err := time.ParseDuration(*file.Timeout)
+		if err != nil {
+			return postSync, fmt.Errorf("post_sync.timeout: %w", err)
+		}
+		postSync.Timeout = d
+	}
+
+	if err := postSync.Validate(); err != nil {
+		return postSync, fmt.Errorf("post_sync.%w", err)
+	}
+	return postSync, nil
+}
+
+func valueOf[T any](value *T) T {
+	var zero T
+	if value == nil {
+		return zero
+	}
+	return *value
+}
+
+func (instance *fileInstance) piHole() model.PiHole {
+	piHole := model.NewPiHole(instance.Url, instance.Password)
+	u, options, _ := instance.parseUrl()
+	piHole.Url = u
+	piHole.TLS = options.TLS
+	piHole.AppPassword = options.AppPassword
+	piHole.TotpSecret = options.TotpSecret
+	if instance.PasswordFile != "" {
+		piHole.Password = model.FileSecret(instance.PasswordFile)
+	}
+	if instance.Name != "" {
+		piHole.Name = instance.Name
+	}
+	piHole.Overrides = instance.Overrides
+	piHole.Vars = instance.Vars
+	piHole.Timeouts, _ = instance.timeouts()
+	piHole.PostSync, _ = instance.postSync()
+	return piHole
+}
+
+// apply sets the values of the file on the config, unless they are set by env vars.
+func (file *fileConfig) apply(c *Config) error {
+	if file.Primary != nil && !envSet("PRIMARY") {
+		c.Primary = file.Primary.piHole()
+	}
+
+	if file.Replicas != nil && !envSet("REPLICAS") {
+		c.Replicas = make([]model.PiHole, len(file.Replicas))
+		for i, replica := range file.Replicas {
+			c.Replicas[i] = replica.piHole()
+		}
+	}
+
+	if file.Schedule != nil && file.Schedule.Cron != nil && !envSet("CRON") {
+		c.Cron = file.Schedule.Cron
+	}
+
+	if sync := file.Sync; sync != nil {
+		fileValue(&c.FullSync, sync.Full, "FULL_SYNC")
+		fileValue(&c.Concurrency, sync.Concurrency, "SYNC_CONCURRENCY")
+		fileValue(&c.FailurePolicy, sync.FailurePolicy, "FAILURE_POLICY")
+		fileValue(&c.FailureQuorum, sync.FailureQuorum, "FAILURE_QUORUM")
+		fileValue(&c.GravityStrategy, sync.GravityStrategy, "GRAVITY_SYNC_STRATEGY")
+		if err := fileDuration(&c.BlockingSyncInterval, sync.BlockingInterval, "BLOCKING_SYNC_INTERVAL", "sync.blocking_interval"); err != nil {
+			return err
+		}
+		fileValue(&c.DryRun, sync.DryRun, "DRY_RUN")
+		fileValue(&c.DryRunFormat, sync.DryRunFormat, "DRY_RUN_FORMAT")
+		fileValue(&c.ChangeDetect, sync.ChangeDetection, "CHANGE_DETECTION")
+		fileValue(&c.StateDir, sync.StateDir, "STATE_DIR")
+		fileValue(&c.Verify, sync.Verify, "VERIFY")
+
+		if backup := sync.Backup; backup != nil {
+			fileValue(&c.Backup, backup.Enabled, "BACKUP")
+			fileValue(&c.BackupDir, backup.Dir, "BACKUP_DIR")
+			fileValue(&c.BackupKeep, backup.Keep, "BACKUP_KEEP")
+		}
+	}
+
+	if server := file.Server; server != nil {
+		fileValue(&c.ServerAddr, server.Addr, "SERVER_ADDR")
+		fileValue(&c.ApiToken, server.ApiToken, "API_TOKEN")
+		fileValue(&c.ApiSyncPolicy, server.ApiSyncPolicy, "API_SYNC_POLICY")
+		if err := fileDuration(&c.ReadyTolerance, server.ReadyTolerance, "READY_TOLERANCE", "server.ready_tolerance"); err != nil {
+			return err
+		}
+	}
+
+	if timeouts := file.Timeouts; timeouts != nil {
+		if err := fileDuration(&c.Timeouts.Connect, timeouts.Connect, "TIMEOUT_CONNECT", "timeouts.connect"); err != nil {
+			return err
+		}
+		if err := fileDuration(&c.Timeouts.Request, timeouts.Request, "TIMEOUT_REQUEST", "timeouts.request"); err != nil {
+			return err
+		}
+		if err := fileDuration(&c.Timeouts.Teleporter, timeouts.Teleporter, "TIMEOUT_TELEPORTER", "timeouts.teleporter"); err != nil {
+			return err
+		}
+	}
+
+	if retry := file.Retry; retry != nil {
+		fileValue(&c.Retry.MaxAttempts, retry.MaxAttempts, "RETRY_MAX_ATTEMPTS")
+		fileValue(&c.Retry.Jitter, retry.Jitter, "RETRY_JITTER")
+		if err := fileDuration(&c.Retry.BaseDelay, retry.BaseDelay, "RETRY_BASE_DELAY", "retry.base_delay"); err != nil {
+			return err
+		}
+	}
+
+	if session := file.Session; session != nil {
+		fileValue(&c.Session.Reuse, session.Reuse, "SESSION_REUSE")
+		fileValue(&c.Session.Dir, session.Dir, "SESSION_DIR")
+	}
+
+	if postSync := file.PostSync; postSync != nil {
+		fileValue(&c.PostSync.Gravity, postSync.Gravity, "POST_SYNC_GRAVITY")
+		fileValue(&c.PostSync.RestartDNS, postSync.RestartDNS, "POST_SYNC_RESTART_DNS")
+		fileValue(&c.PostSync.FlushNetwork, postSync.FlushNetwork, "POST_SYNC_FLUSH_NETWORK")
+		fileValue(&c.PostSync.FlushLogs, postSync.FlushLogs, "POST_SYNC_FLUSH_LOGS")
+		if err := fileDuration(&c.PostSync.GravityTimeout, postSync.GravityTimeout, "POST_SYNC_GRAVITY_TIMEOUT", "post_sync.gravity_timeout"); err != nil {
+			return err
+		}
+		if err := fileDuration(&c.PostSync.Timeout, postSync.Timeout, "POST_SYNC_TIMEOUT", "post_sync.timeout"); err != nil {
+			return err
+		}
+	}
+
+	if notify := file.Notify; notify != nil {
+		notify.apply(&c.Notify)
+	}
+	return nil
+}
+
+func (notify *fileNotify) apply(n *Notify) {
+	if webhook := notify.Webhook; webhook != nil {
+		fileValue(&n.Webhook.URL, webhook.URL, "NOTIFY_WEBHOOK_URL")
+		fileValue(&n.Webhook.On, webhook.On, "NOTIFY_WEBHOOK_ON")
+	}
+	if slack := notify.Slack; slack != nil {
+		fileValue(&n.Slack.URL, slack.URL, "NOTIFY_SLACK_URL")
+		fileValue(&n.Slack.On, slack.On, "NOTIFY_SLACK_ON")
+	}
+	if ntfy := notify.Ntfy; ntfy != nil {
+		fileValue(&n.Ntfy.URL, ntfy.URL, "NOTIFY_NTFY_URL")
+		fileValue(&n.Ntfy.Token, ntfy.Token, "NOTIFY_NTFY_TOKEN")
+		fileValue(&n.Ntfy.On, ntfy.On, "NOTIFY_NTFY_ON")
+	}
+	if gotify := notify.Gotify; gotify != nil {
+		fileValue(&n.Gotify.URL, gotify.URL, "NOTIFY_GOTIFY_URL")
+		fileValue(&n.Gotify.Token, gotify.Token, "NOTIFY_GOTIFY_TOKEN")
+		fileValue(&n.Gotify.On, gotify.On, "NOTIFY_GOTIFY_ON")
+	}
+	if smtp := notify.SMTP; smtp != nil {
+		fileValue(&n.SMTP.Host, smtp.Host, "NOTIFY_SMTP_HOST")
+		fileValue(&n.SMTP.Port, smtp.Port, "NOTIFY_SMTP_PORT")
+		fileValue(&n.SMTP.Username, smtp.Username, "NOTIFY_SMTP_USERNAME")
+		fileValue(&n.SMTP.Password, smtp.Password, "NOTIFY_SMTP_PASSWORD")
+		fileValue(&n.SMTP.From, smtp.From, "NOTIFY_SMTP_FROM")
+		fileValue(&n.SMTP.To, smtp.To, "NOTIFY_SMTP_TO")
+		fileValue(&n.SMTP.On, smtp.On, "NOTIFY_SMTP_ON")
+	}
+}
+
+// applySyncSettings sets the manual sync settings of the file, unless they are set by env vars.
+func (file *fileConfig) applySyncSettings(settings *SyncSettings) {
+	if file.Sync == nil {
+		return
+	}
+
+	if config := file.Sync.Config; config != nil {
+		fileValue(&settings.Config.DNS, config.DNS, "SYNC_CONFIG_DNS")
+		fileValue(&settings.Config.DHCP, config.DHCP, "SYNC_CONFIG_DHCP")
+		fileValue(&settings.Config.NTP, config.NTP, "SYNC_CONFIG_NTP")
+		fileValue(&settings.Config.Resolver, config.Resolver, "SYNC_CONFIG_RESOLVER")
+		fileValue(&settings.Config.Database, config.Database, "SYNC_CONFIG_DATABASE")
+		fileValue(&settings.Config.Webserver, config.Webserver, "SYNC_CONFIG_WEBSERVER")
+		fileValue(&settings.Config.Files, config.Files, "SYNC_CONFIG_FILES")
+		fileValue(&settings.Config.Misc, config.Misc, "SYNC_CONFIG_MISC")
+		fileValue(&settings.Config.Debug, config.Debug, "SYNC_CONFIG_DEBUG")
+		if config.Include != nil {
+			fileValue(&settings.Config.Include, &config.Include, "SYNC_CONFIG_INCLUDE")
+		}
+		if config.Exclude != nil {
+			fileValue(&settings.Config.Exclude, &config.Exclude, "SYNC_CONFIG_EXCLUDE")
+		}
+		if config.Sensitive != nil {
+			fileValue(&settings.Config.Sensitive, &config.Sensitive, "SYNC_CONFIG_SENSITIVE")
+		}
+	}
+
+	if gravity := file.Sync.Gravity; gravity != nil {
+		fileValue(&settings.Gravity.DHCPLeases, gravity.DHCPLeases, "SYNC_GRAVITY_DHCP_LEASES")
+		fileValue(&settings.Gravity.Group, gravity.Group, "SYNC_GRAVITY_GROUP")
+		fileValue(&settings.Gravity.Adlist, gravity.Adlist, "SYNC_GRAVITY_AD_LIST")
+		fileValue(&settings.Gravity.AdlistByGroup, gravity.AdlistByGroup, "SYNC_GRAVITY_AD_LIST_BY_GROUP")
+		fileValue(&settings.Gravity.Domainlist, gravity.Domainlist, "SYNC_GRAVITY_DOMAIN_LIST")
+		fileValue(&settings.Gravity.DomainlistByGroup, gravity.DomainlistByGroup, "SYNC_GRAVITY_DOMAIN_LIST_BY_GROUP")
+		fileValue(&settings.Gravity.Client, gravity.Client, "SYNC_GRAVITY_CLIENT")
+		fileValue(&settings.Gravity.ClientByGroup, gravity.ClientByGroup, "SYNC_GRAVITY_CLIENT_BY_GROUP")
+	}
+}
+
+// fileValue sets dst to value if the file sets it and env var key is not set.
+func fileValue[T any](dst *T, value *T, key string) {
+	if value == nil || envSet(key) {
+		return
+	}
+	*dst = *value
+}
+
+// fileDuration parses value into dst if the file sets it and env var key is not set, name is the key in the file.
+func fileDuration(dst *time.Duration, value *string, key, name string) error {
+	if value == nil || envSet(key) {
+		return nil
+	}
+
+	duration, err := time.ParseDuration(*value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	*dst = duration
+	return nil
+}
+
+func envSet(key string) bool {
+	_, ok := os.LookupEnv(key)
+	return ok
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfig_LoadFile(t *testing.T) {
+	unsetEnv(t)
+
+	for _, filename := range []string{"../../testdata/config.yaml", "../../testdata/config.toml"} {
+		t.Run(filepath.Ext(filename), func(t *testing.T) {
+			conf := Config{}
+
+			err := conf.LoadFile(filename)
+			require.NoError(t, err)
+
+			assert.Equal(t, "http://ph1.example.com", conf.Primary.Url.String())
+			assert.Equal(t, "password", conf.Primary.Password)
+			require.Len(t, conf.Replicas, 2)
+			assert.Equal(t, "ph2", conf.Replicas[0].Name)
+			assert.Equal(t, "http://ph2.example.com", conf.Replicas[0].Url.String())
+			assert.Equal(t, "JBSWY3DPEHPK3PXP", conf.Replicas[0].TotpSecret)
+			assert.Equal(t, map[string]interface{}{"dhcp.router": "{{ .Vars.subnet }}.1"}, conf.Replicas[0].Overrides)
+			assert.Equal(t, map[string]string{"subnet": "192.168.2"}, conf.Replicas[0].Vars)
+			assert.Equal(t, "ph3.example.com", conf.Replicas[1].Name)
+			assert.Equal(t, model.Timeouts{Connect: 5 * time.Second, Request: time.Minute, Teleporter: 5 * time.Minute}, conf.Replicas[0].Timeouts)
+			assert.Equal(t, model.Timeouts{Connect: 5 * time.Second, Request: time.Minute, Teleporter: 10 * time.Minute}, conf.Replicas[1].Timeouts)
+			assert.Equal(t, model.TLS{CAFile: "/etc/ssl/internal-ca.pem", ServerName: "ph3.lan"}, conf.Replicas[1].TLS)
+			assert.Equal(t, "* * * * *", *conf.Cron)
+			assert.False(t, conf.FullSync)
+			assert.Equal(t, 2, conf.Concurrency)
+			assert.Equal(t, FailContinue, conf.FailurePolicy)
+			assert.Equal(t, GravityAPI, conf.GravityStrategy)
+			assert.Equal(t, 10*time.Second, conf.BlockingSyncInterval)
+			assert.True(t, conf.ChangeDetect)
+			assert.True(t, conf.Backup)
+			assert.Equal(t, 3, conf.BackupKeep)
+			assert.Equal(t, FormatText, conf.DryRunFormat)
+			assert.Equal(t, ":8080", conf.ServerAddr)
+			assert.Equal(t, 2*time.Hour, conf.ReadyTolerance)
+			assert.Equal(t, Retry{MaxAttempts: 5, BaseDelay: 500 * time.Millisecond, Jitter: 0.2}, conf.Retry)
+			assert.Equal(t, Session{Reuse: true, Dir: "/data/sessions"}, conf.Session)
+			assert.Equal(t, model.PostSync{Gravity: model.ActionChanged, RestartDNS: model.ActionNever, FlushNetwork: model.ActionNever, FlushLogs: model.ActionNever, GravityTimeout: 20 * time.Minute, Timeout: time.Minute}, conf.Replicas[0].PostSync)
+			assert.Equal(t, model.ActionAlways, conf.Replicas[1].PostSync.RestartDNS)
+			assert.Equal(t, "https://hooks.slack.com/services/T000/B000/XXXX", conf.Notify.Slack.URL)
+			assert.Equal(t, []string{NotifyFailure, NotifyRecovery}, conf.Notify.Slack.On)
+			assert.Equal(t, "file:/run/secrets/gotify", conf.Notify.Gotify.Token)
+
+			require.NotNil(t, conf.SyncSettings)
+			assert.True(t, conf.SyncSettings.Config.DNS)
+			assert.False(t, conf.SyncSettings.Config.DHCP)
+			assert.Equal(t, []string{"dhcp.hosts"}, conf.SyncSettings.Config.Include)
+			assert.Equal(t, []string{"dns.interface"}, conf.SyncSettings.Config.Exclude)
+			assert.True(t, conf.SyncSettings.Config.Webserver)
+			assert.Equal(t, []string{"webserver.tls"}, conf.SyncSettings.Config.Sensitive)
+			assert.True(t, conf.SyncSettings.Gravity.Group)
+			assert.True(t, conf.SyncSettings.Gravity.Adlist)
+			assert.False(t, conf.SyncSettings.Gravity.Client)
+		})
+	}
+}
+
+func TestConfig_LoadFile_envPrecedence(t *testing.T) {
+	unsetEnv(t)
+
+	conf := Config{}
+
+	t.Setenv("REPLICAS", "http://ph4.example.com|qwerty")
+	t.Setenv("SYNC_CONCURRENCY", "8")
+	t.Setenv("SYNC_CONFIG_DNS", "false")
+	t.Setenv("SYNC_CONFIG_INCLUDE", "ntp.*")
+
+	err := conf.LoadFile("../../testdata/config.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://ph1.example.com", conf.Primary.Url.String())
+	require.Len(t, conf.Replicas, 1)
+	assert.Equal(t, "http://ph4.example.com", conf.Replicas[0].Url.String())
+	assert.Equal(t, 8, conf.Concurrency)
+	assert.Equal(t, 3, conf.BackupKeep)
+	assert.False(t, conf.SyncSettings.Config.DNS)
+	assert.Equal(t, []string{"ntp.*"}, conf.SyncSettings.Config.Include)
+	assert.Equal(t, []string{"dns.interface"}, conf.SyncSettings.Config.Exclude)
+}
+
+func TestConfig_LoadFile_overridesByName(t *testing.T) {
+	unsetEnv(t)
+
+	conf := Config{}
+
+	t.Setenv("REPLICA_OVERRIDES", `{"ph3.example.com": {"overrides": {"dns.domain": "ph3.lan"}}}`)
+
+	err := conf.LoadFile("../../testdata/config.toml")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"dns.domain": "ph3.lan"}, conf.Replicas[1].Overrides)
+}
+
+func TestConfig_LoadFile_invalid(t *testing.T) {
+	unsetEnv(t)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"config.yaml", "sync:\n  full: true\n  concurency: 2\n", "field concurency not found"},
+		{"config.toml", "[sync]\nfull = true\nconcurency = 2\n", "unknown keys: sync.concurency"},
+		{"config.yml", "primary:\n  password: password\n", "primary: url is required"},
+		{"config.json", "{}", "unsupported file type"},
+		{"server.yaml", "server:\n  ready_tolerance: soon\n", "server.ready_tolerance"},
+		{"retry.yaml", "retry:\n  base_delay: 1\n", "retry.base_delay"},
+		{"tls.yaml", "replicas:\n  - url: https://a?server_name=a.lan\n    tls: {cert_file: /etc/ssl/client.pem}\n", "replicas[0]: tls: cert_file and key_file must be set together"},
+		{"auth.yaml", "primary:\n  url: https://a?app_password=true\n  totp_secret: JBSWY3DPEHPK3PXP\n", "primary: totp_secret cannot be used with app_password"},
+		{"options.yaml", "replicas:\n  - url: https://a?verify=false\n", `replicas[0]: unknown option "verify"`},
+		{"timeouts.yaml", "replicas:\n  - url: http://a\n    timeouts: {request: 0s}\n", "replicas[0]: timeouts.request: must be positive"},
+		{"post_sync.yaml", "replicas:\n  - url: http://a\n    post_sync: {gravity: sometimes}\n", `replicas[0]: post_sync.gravity: invalid mode "sometimes"`},
+		{"dupes.yaml", "replicas:\n  - {name: a, url: http://a}\n  - {name: a, url: http://b}\n", "duplicate name"},
+		{"paths.yaml", "replicas:\n  - url: http://a\n    overrides: {api.port: 80}\n", "not a syncable config section"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), tt.name)
+			require.NoError(t, os.WriteFile(filename, []byte(tt.content), 0o644))
+
+			conf := Config{}
+			err := conf.LoadFile(filename)
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestConfig_LoadFile_required(t *testing.T) {
+	unsetEnv(t)
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("primary:\n  url: http://ph1.example.com\n"), 0o644))
+
+	conf := Config{}
+	err := conf.LoadFile(filename)
+	assert.ErrorContains(t, err, "required key REPLICAS missing value")
+
+	t.Setenv("REPLICAS", "http://ph2.example.com|password")
+	err = conf.LoadFile(filename)
+	assert.ErrorContains(t, err, "required key FULL_SYNC missing value")
+
+	t.Setenv("FULL_SYNC", "true")
+	err = conf.LoadFile(filename)
+	assert.NoError(t, err)
+}
+
+func TestConfig_Load_required(t *testing.T) {
+	unsetEnv(t)
+
+	conf := Config{}
+	err := conf.Load()
+	assert.ErrorContains(t, err, "required key PRIMARY missing value")
+}
+
+// unsetEnv unsets the env vars that are set in the file, they are restored after the test.
+func unsetEnv(t *testing.T) {
+	for _, key := range []string{"PRIMARY", "REPLICAS", "FULL_SYNC", "CRON", "SYNC_CONFIG_DNS", "SYNC_CONFIG_DHCP", "SYNC_GRAVITY_GROUP", "SYNC_GRAVITY_AD_LIST", "SYNC_GRAVITY_CLIENT"} {
+		t.Setenv(key, "")
+		require.NoError(t, os.Unsetenv(key))
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	// NotifyFailure notifies when a sync fails.
+	NotifyFailure = "failure"
+	// NotifyRecovery notifies when a sync succeeds after a failed sync.
+	NotifyRecovery = "recovery"
+	// NotifyAlways notifies on every sync.
+	NotifyAlways = "always"
+)
+
+// Notify configures the notifiers, a notifier is enabled if its url, or host for SMTP, is set.
+type Notify struct {
+	Webhook WebhookNotifier `envconfig:"WEBHOOK"`
+	Slack   WebhookNotifier `envconfig:"SLACK"`
+	Ntfy    TokenNotifier   `envconfig:"NTFY"`
+	Gotify  TokenNotifier   `envconfig:"GOTIFY"`
+	SMTP    SMTPNotifier    `envconfig:"SMTP"`
+}
+
+type WebhookNotifier struct {
+	URL string   `envconfig:"URL"`
+	On  []string `default:"failure,recovery" envconfig:"ON"`
+}
+
+type TokenNotifier struct {
+	URL   string   `envconfig:"URL"`
+	Token string   `envconfig:"TOKEN"`
+	On    []string `default:"failure,recovery" envconfig:"ON"`
+}
+
+type SMTPNotifier struct {
+	Host     string   `envconfig:"HOST"`
+	Port     int      `default:"587" envconfig:"PORT"`
+	Username string   `envconfig:"USERNAME"`
+	Password string   `envconfig:"PASSWORD"`
+	From     string   `envconfig:"FROM"`
+	To       []string `envconfig:"TO"`
+	On       []string `default:"failure,recovery" envconfig:"ON"`
+}
+
+func (n *Notify) validate() error {
+	for name, on := range map[string][]string{
+		"NOTIFY_WEBHOOK_ON": n.Webhook.On,
+		"NOTIFY_SLACK_ON":   n.Slack.On,
+		"NOTIFY_NTFY_ON":    n.Ntfy.On,
+		"NOTIFY_GOTIFY_ON":  n.Gotify.On,
+		"NOTIFY_SMTP_ON":    n.SMTP.On,
+	} {
+		for _, trigger := range on {
+			if !slices.Contains([]string{NotifyFailure, NotifyRecovery, NotifyAlways}, trigger) {
+				return fmt.Errorf("%s: invalid trigger %q, expected %q, %q or %q", name, trigger, NotifyFailure, NotifyRecovery, NotifyAlways)
+			}
+		}
+	}
+
+	if n.SMTP.Host != "" && (n.SMTP.From == "" || len(n.SMTP.To) == 0) {
+		return fmt.Errorf("NOTIFY_SMTP_FROM and NOTIFY_SMTP_TO are required with NOTIFY_SMTP_HOST")
+	}
+	return nil
+}
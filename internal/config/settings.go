@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+)
+
+// GravityTables are the gravity tables that can be synced, named as in the config file.
+var GravityTables = []string{"dhcp_leases", "group", "ad_list", "ad_list_by_group", "domain_list", "domain_list_by_group", "client", "client_by_group"}
+
+// NewSyncSettings returns sync settings that sync the given config sections and gravity tables.
+func NewSyncSettings(sections, gravity []string) (*SyncSettings, error) {
+	manualConfig := ManualConfig{}
+	for _, section := range sections {
+		switch section {
+		case "dns":
+			manualConfig.DNS = true
+		case "dhcp":
+			manualConfig.DHCP = true
+		case "ntp":
+			manualConfig.NTP = true
+		case "resolver":
+			manualConfig.Resolver = true
+		case "database":
+			manualConfig.Database = true
+		case "webserver":
+			manualConfig.Webserver = true
+		case "files":
+			manualConfig.Files = true
+		case "misc":
+			manualConfig.Misc = true
+		case "debug":
+			manualConfig.Debug = true
+		default:
+			return nil, fmt.Errorf("unknown config section %q, expected one of %v", section, ConfigSections)
+		}
+	}
+
+	manualGravity := ManualGravity{}
+	for _, table := range gravity {
+		switch table {
+		case "dhcp_leases":
+			manualGravity.DHCPLeases = true
+		case "group":
+			manualGravity.Group = true
+		case "ad_list":
+			manualGravity.Adlist = true
+		case "ad_list_by_group":
+			manualGravity.AdlistByGroup = true
+		case "domain_list":
+			manualGravity.Domainlist = true
+		case "domain_list_by_group":
+			manualGravity.DomainlistByGroup = true
+		case "client":
+			manualGravity.Client = true
+		case "client_by_group":
+			manualGravity.ClientByGroup = true
+		default:
+			return nil, fmt.Errorf("unknown gravity table %q, expected one of %v", table, GravityTables)
+		}
+	}
+
+	return &SyncSettings{
+		Gravity: &manualGravity,
+		Config:  &manualConfig,
+	}, nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNewSyncSettings(t *testing.T) {
+	settings, err := NewSyncSettings([]string{"dns", "dhcp"}, []string{"group", "ad_list"})
+	require.NoError(t, err)
+
+	assert.Equal(t, ManualConfig{DNS: true, DHCP: true}, *settings.Config)
+	assert.Equal(t, ManualGravity{Group: true, Adlist: true}, *settings.Gravity)
+
+	settings, err = NewSyncSettings([]string{"webserver", "files"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, ManualConfig{Webserver: true, Files: true}, *settings.Config)
+
+	_, err = NewSyncSettings([]string{"api"}, nil)
+	assert.ErrorContains(t, err, `unknown config section "api"`)
+
+	_, err = NewSyncSettings(nil, []string{"gravity"})
+	assert.ErrorContains(t, err, `unknown gravity table "gravity"`)
+}
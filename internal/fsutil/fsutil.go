@@ -0,0 +1,46 @@
+// Package fsutil contains file helpers shared by the packages that persist state to disk.
+package fsutil
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// URLName turns a url into a file or directory name, e.g. http://ph2.example.com:8080 into ph2.example.com_8080.
+// Values that are not urls are used as is, with unsafe characters replaced.
+func URLName(raw string) string {
+	name := raw
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		name = u.Host + u.Path
+	}
+	return strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_")
+}
+
+// WriteFileAtomic writes data to a temporary file next to filename and renames it into place, so readers never see a
+// partially written file. Missing parent directories are created.
+func WriteFileAtomic(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
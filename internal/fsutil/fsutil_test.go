@@ -0,0 +1,30 @@
+package fsutil
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestURLName(t *testing.T) {
+	assert.Equal(t, "ph2.example.com", URLName("http://ph2.example.com"))
+	assert.Equal(t, "ph2.example.com_8080_pihole", URLName("https://ph2.example.com:8080/pihole/"))
+	assert.Equal(t, "not_a_url", URLName("not a url"))
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "nested", "file.json")
+
+	require.NoError(t, WriteFileAtomic(filename, []byte("first")))
+	require.NoError(t, WriteFileAtomic(filename, []byte("second")))
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"context"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+)
+
+// client records the size of the teleporter archives of a pihole.Client. Requests are recorded by Transport.
+type client struct {
+	pihole.Client
+}
+
+func NewClient(c pihole.Client) pihole.Client {
+	return &client{Client: c}
+}
+
+func (c *client) GetTeleporter(ctx context.Context) ([]byte, error) {
+	payload, err := c.Client.GetTeleporter(ctx)
+	if err == nil {
+		teleporterSize.WithLabelValues(c.String()).Set(float64(len(payload)))
+	}
+	return payload, err
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"context"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestClient_teleporterSize(t *testing.T) {
+	const instance = "http://client-teleporter.example.com"
+	inner := piholemock.NewClient(t)
+	inner.EXPECT().String().Return(instance)
+	inner.EXPECT().GetTeleporter(mock.Anything).Return(make([]byte, 1024), nil)
+
+	_, err := NewClient(inner).GetTeleporter(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1024.0, testutil.ToFloat64(teleporterSize.WithLabelValues(instance)))
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+const namespace = "nebula_sync"
+
+const (
+	ModeFull   = "full"
+	ModeManual = "manual"
+
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	syncRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sync_runs_total",
+		Help:      "Number of sync runs by mode and outcome.",
+	}, []string{"mode", "outcome"})
+
+	syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Duration of sync runs by mode and outcome.",
+		Buckets:   []float64{1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"mode", "outcome"})
+
+	replicaDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "replica_sync_duration_seconds",
+		Help:      "Duration of replica syncs.",
+		Buckets:   []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"replica", "outcome"})
+
+	replicaLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "replica_last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful replica sync.",
+	}, []string{"replica"})
+
+	replicaFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "replica_failures_total",
+		Help:      "Number of failed replica syncs.",
+	}, []string{"replica"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "pihole_request_duration_seconds",
+		Help:      "Duration of Pi-hole API requests by instance and endpoint, including retries and re-authentication.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"instance", "endpoint"})
+
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pihole_requests_total",
+		Help:      "Number of Pi-hole API requests sent by instance, endpoint and status code, including retries and re-authentication, error if no response was received.",
+	}, []string{"instance", "endpoint", "code"})
+
+	teleporterSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "teleporter_archive_size_bytes",
+		Help:      "Size of the last teleporter archive exported from an instance.",
+	}, []string{"instance"})
+
+	configDrift = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "config_drift",
+		Help:      "Number of config paths that differ between the primary and a replica, as detected by the last diff, 0 after a successful sync.",
+	}, []string{"replica"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		syncRuns,
+		syncDuration,
+		replicaDuration,
+		replicaLastSuccess,
+		replicaFailures,
+		requestDuration,
+		requests,
+		teleporterSize,
+		configDrift,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
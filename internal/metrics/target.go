@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/lovelaze/nebula-sync/internal/sync"
+	"time"
+)
+
+// target records the outcome of the syncs and diffs of a sync.Target.
+type target struct {
+	sync.Target
+}
+
+func NewTarget(t sync.Target) sync.Target {
+	return &target{Target: t}
+}
+
+func (t *target) FullSync(ctx context.Context) (*sync.SyncResult, error) {
+	start := time.Now()
+	result, err := t.Target.FullSync(ctx)
+	observeSync(ModeFull, start, result, err)
+	return result, err
+}
+
+func (t *target) ManualSync(ctx context.Context, syncSettings *config.SyncSettings) (*sync.SyncResult, error) {
+	start := time.Now()
+	result, err := t.Target.ManualSync(ctx, syncSettings)
+	observeSync(ModeManual, start, result, err)
+	return result, err
+}
+
+func (t *target) WithReplicas(replicas []string) (sync.Target, error) {
+	filtered, err := t.Target.WithReplicas(replicas)
+	if err != nil {
+		return nil, err
+	}
+	return NewTarget(filtered), nil
+}
+
+func (t *target) Diff(ctx context.Context, syncSettings *config.SyncSettings) (*sync.DiffResult, error) {
+	result, err := t.Target.Diff(ctx, syncSettings)
+	if err != nil {
+		return result, err
+	}
+
+	for _, replica := range result.Replicas {
+		if replica.Error == "" {
+			configDrift.WithLabelValues(replica.Replica).Set(float64(len(replica.Changes)))
+		}
+	}
+	return result, nil
+}
+
+func observeSync(mode string, start time.Time, result *sync.SyncResult, err error) {
+	outcome := OutcomeSuccess
+	if err != nil || result == nil || result.Err() != nil {
+		outcome = OutcomeFailure
+	}
+
+	duration := time.Since(start)
+	if result != nil {
+		duration = result.Duration
+	}
+	syncRuns.WithLabelValues(mode, outcome).Inc()
+	syncDuration.WithLabelValues(mode, outcome).Observe(duration.Seconds())
+
+	if result == nil {
+		return
+	}
+
+	for _, replica := range result.Replicas {
+		if replica.Success() {
+			// The replica matches the primary after a successful sync, until the next diff detects drift.
+			configDrift.WithLabelValues(replica.Replica).Set(0)
+			replicaDuration.WithLabelValues(replica.Replica, OutcomeSuccess).Observe(replica.Duration.Seconds())
+			replicaLastSuccess.WithLabelValues(replica.Replica).SetToCurrentTime()
+		} else {
+			replicaDuration.WithLabelValues(replica.Replica, OutcomeFailure).Observe(replica.Duration.Seconds())
+			replicaFailures.WithLabelValues(replica.Replica).Inc()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	syncmock "github.com/lovelaze/nebula-sync/internal/mocks/sync"
+	"github.com/lovelaze/nebula-sync/internal/sync"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTarget_FullSync(t *testing.T) {
+	inner := syncmock.NewTarget(t)
+	inner.EXPECT().FullSync(mock.Anything).Return(&sync.SyncResult{
+		Duration: 2 * time.Second,
+		Replicas: []sync.ReplicaResult{
+			{Replica: "http://target-full-ok.example.com", Duration: time.Second},
+			{Replica: "http://target-full-failed.example.com", Duration: time.Second, Err: errors.New("connection refused")},
+		},
+	}, nil)
+
+	successRuns := testutil.ToFloat64(syncRuns.WithLabelValues(ModeFull, OutcomeSuccess))
+	failureRuns := testutil.ToFloat64(syncRuns.WithLabelValues(ModeFull, OutcomeFailure))
+
+	_, err := NewTarget(inner).FullSync(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, successRuns, testutil.ToFloat64(syncRuns.WithLabelValues(ModeFull, OutcomeSuccess)))
+	assert.Equal(t, failureRuns+1, testutil.ToFloat64(syncRuns.WithLabelValues(ModeFull, OutcomeFailure)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(replicaFailures.WithLabelValues("http://target-full-failed.example.com")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(replicaFailures.WithLabelValues("http://target-full-ok.example.com")))
+	assert.InDelta(t, float64(time.Now().Unix()), testutil.ToFloat64(replicaLastSuccess.WithLabelValues("http://target-full-ok.example.com")), 5)
+}
+
+func TestTarget_FullSync_resetsDrift(t *testing.T) {
+	configDrift.WithLabelValues("http://target-drift-ok.example.com").Set(3)
+	configDrift.WithLabelValues("http://target-drift-failed.example.com").Set(2)
+
+	inner := syncmock.NewTarget(t)
+	inner.EXPECT().FullSync(mock.Anything).Return(&sync.SyncResult{Replicas: []sync.ReplicaResult{
+		{Replica: "http://target-drift-ok.example.com"},
+		{Replica: "http://target-drift-failed.example.com", Err: errors.New("connection refused")},
+	}}, nil)
+
+	_, err := NewTarget(inner).FullSync(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.0, testutil.ToFloat64(configDrift.WithLabelValues("http://target-drift-ok.example.com")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(configDrift.WithLabelValues("http://target-drift-failed.example.com")))
+}
+
+func TestTarget_ManualSync_error(t *testing.T) {
+	inner := syncmock.NewTarget(t)
+	inner.EXPECT().ManualSync(mock.Anything, (*config.SyncSettings)(nil)).Return(nil, errors.New("primary unreachable"))
+
+	failureRuns := testutil.ToFloat64(syncRuns.WithLabelValues(ModeManual, OutcomeFailure))
+
+	_, err := NewTarget(inner).ManualSync(context.Background(), nil)
+	require.Error(t, err)
+
+	assert.Equal(t, failureRuns+1, testutil.ToFloat64(syncRuns.WithLabelValues(ModeManual, OutcomeFailure)))
+}
+
+func TestTarget_Diff(t *testing.T) {
+	configDrift.Reset()
+	inner := syncmock.NewTarget(t)
+	inner.EXPECT().Diff(mock.Anything, (*config.SyncSettings)(nil)).Return(&sync.DiffResult{Replicas: []sync.ReplicaDiff{
+		{Replica: "http://target-diff.example.com", Changes: []sync.Change{{Path: "dns.port"}, {Path: "dns.domain"}}},
+		{Replica: "http://target-diff-failed.example.com", Error: "connection refused"},
+	}}, nil)
+
+	_, err := NewTarget(inner).Diff(context.Background(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(configDrift.WithLabelValues("http://target-diff.example.com")))
+	assert.Equal(t, 1, testutil.CollectAndCount(configDrift), "failed diffs do not set drift")
+}
+
+func TestHandler(t *testing.T) {
+	syncRuns.WithLabelValues(ModeFull, OutcomeSuccess).Add(0)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `nebula_sync_sync_runs_total{mode="full",outcome="success"}`)
+}
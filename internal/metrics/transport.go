@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// endpoints are templates of the Pi-hole API paths with variable segments, so that names of groups, lists, domains
+// and clients do not end up in labels.
+var endpoints = []string{
+	"/api/groups/{name}",
+	"/api/lists/{list}",
+	"/api/domains/{type}/{kind}",
+	"/api/domains/{type}/{kind}/{domain}",
+	"/api/clients/{client}",
+}
+
+// transport records the latency and status code of every request sent to a Pi-hole instance. It replaces instrumenting
+// the methods of pihole.Client, which cannot see the retries and re-authentication inside a call, and would record
+// calls such as Close that send no request.
+type transport struct {
+	next     http.RoundTripper
+	instance string
+}
+
+// Transport returns a wrapper for the transport of the http client of instance, see pihole.Options.
+func Transport(instance string) func(http.RoundTripper) http.RoundTripper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return &transport{next: next, instance: instance}
+	}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	label := endpoint(req)
+	requestDuration.WithLabelValues(t.instance, label).Observe(time.Since(start).Seconds())
+	requests.WithLabelValues(t.instance, label, responseCode(resp, err)).Inc()
+	return resp, err
+}
+
+// endpoint returns the method and the path template of req, e.g. GET /api/groups/{name}. The path of the instance url,
+// if any, is left out.
+func endpoint(req *http.Request) string {
+	path := req.URL.EscapedPath()
+	if i := strings.Index(path, "/api/"); i >= 0 {
+		path = path[i:]
+	}
+
+	segments := strings.Split(path, "/")
+	for _, template := range endpoints {
+		if matchTemplate(strings.Split(template, "/"), segments) {
+			path = template
+			break
+		}
+	}
+	return req.Method + " " + path
+}
+
+func matchTemplate(template, segments []string) bool {
+	if len(template) != len(segments) {
+		return false
+	}
+	for i, segment := range template {
+		if !strings.HasPrefix(segment, "{") && segment != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// responseCode returns the status code of resp, or error if no response was received.
+func responseCode(resp *http.Response, err error) string {
+	if err != nil {
+		return "error"
+	}
+	return strconv.Itoa(resp.StatusCode)
+}
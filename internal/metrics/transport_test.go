@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransport_requests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	const instance = "http://transport-requests.example.com"
+	client := &http.Client{Transport: Transport(instance)(http.DefaultTransport)}
+
+	for _, request := range []struct{ method, url string }{
+		{http.MethodGet, server.URL + "/pihole/api/config"},
+		{http.MethodPatch, server.URL + "/pihole/api/config"},
+		{http.MethodPatch, server.URL + "/pihole/api/config"},
+		{http.MethodPut, server.URL + "/api/groups/iot"},
+		{http.MethodDelete, closed.URL + "/api/auth"},
+	} {
+		req, err := http.NewRequest(request.method, request.url, nil)
+		require.NoError(t, err)
+		if resp, err := client.Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(requests.WithLabelValues(instance, "GET /api/config", "200")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(requests.WithLabelValues(instance, "PATCH /api/config", "401")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(requests.WithLabelValues(instance, "PUT /api/groups/{name}", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(requests.WithLabelValues(instance, "DELETE /api/auth", "error")))
+}
+
+func Test_endpoint(t *testing.T) {
+	tests := map[string]string{
+		"http://ph.example.com/api/auth":                                                 "POST /api/auth",
+		"http://ph.example.com/api/groups:batchDelete":                                   "POST /api/groups:batchDelete",
+		"http://ph.example.com/api/lists/https:%2F%2Fexample.com%2Fhosts.txt?type=block": "POST /api/lists/{list}",
+		"http://ph.example.com/api/domains/deny/regex":                                   "POST /api/domains/{type}/{kind}",
+		"http://ph.example.com/api/domains/deny/regex/%28%5C.%7C%5E%29example":           "POST /api/domains/{type}/{kind}/{domain}",
+		"http://ph.example.com/api/clients/192.168.1.0%2F24":                             "POST /api/clients/{client}",
+		"http://ph.example.com/api/action/flush/network":                                 "POST /api/action/flush/network",
+	}
+
+	for raw, expected := range tests {
+		req, err := http.NewRequest(http.MethodPost, raw, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, endpoint(req), raw)
+	}
+}
@@ -3,6 +3,8 @@
 package pihole
 
 import (
+	context "context"
+
 	model "github.com/lovelaze/nebula-sync/internal/pihole/model"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -66,17 +68,17 @@ func (_c *Client_ApiPath_Call) RunAndReturn(run func(string) string) *Client_Api
 	return _c
 }
 
-// Authenticate provides a mock function with given fields:
-func (_m *Client) Authenticate() error {
-	ret := _m.Called()
+// Authenticate provides a mock function with given fields: ctx
+func (_m *Client) Authenticate(ctx context.Context) error {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Authenticate")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,13 +92,14 @@ type Client_Authenticate_Call struct {
 }
 
 // Authenticate is a helper method to define mock.On call
-func (_e *Client_Expecter) Authenticate() *Client_Authenticate_Call {
-	return &Client_Authenticate_Call{Call: _e.mock.On("Authenticate")}
+//   - ctx context.Context
+func (_e *Client_Expecter) Authenticate(ctx interface{}) *Client_Authenticate_Call {
+	return &Client_Authenticate_Call{Call: _e.mock.On("Authenticate", ctx)}
 }
 
-func (_c *Client_Authenticate_Call) Run(run func()) *Client_Authenticate_Call {
+func (_c *Client_Authenticate_Call) Run(run func(ctx context.Context)) *Client_Authenticate_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
@@ -106,22 +109,22 @@ func (_c *Client_Authenticate_Call) Return(_a0 error) *Client_Authenticate_Call
 	return _c
 }
 
-func (_c *Client_Authenticate_Call) RunAndReturn(run func() error) *Client_Authenticate_Call {
+func (_c *Client_Authenticate_Call) RunAndReturn(run func(context.Context) error) *Client_Authenticate_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteSession provides a mock function with given fields:
-func (_m *Client) DeleteSession() error {
-	ret := _m.Called()
+// Close provides a mock function with given fields: ctx
+func (_m *Client) Close(ctx context.Context) error {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteSession")
+		panic("no return value specified for Close")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -129,56 +132,57 @@ func (_m *Client) DeleteSession() error {
 	return r0
 }
 
-// Client_DeleteSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteSession'
-type Client_DeleteSession_Call struct {
+// Client_Close_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Close'
+type Client_Close_Call struct {
 	*mock.Call
 }
 
-// DeleteSession is a helper method to define mock.On call
-func (_e *Client_Expecter) DeleteSession() *Client_DeleteSession_Call {
-	return &Client_DeleteSession_Call{Call: _e.mock.On("DeleteSession")}
+// Close is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) Close(ctx interface{}) *Client_Close_Call {
+	return &Client_Close_Call{Call: _e.mock.On("Close", ctx)}
 }
 
-func (_c *Client_DeleteSession_Call) Run(run func()) *Client_DeleteSession_Call {
+func (_c *Client_Close_Call) Run(run func(ctx context.Context)) *Client_Close_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *Client_DeleteSession_Call) Return(_a0 error) *Client_DeleteSession_Call {
+func (_c *Client_Close_Call) Return(_a0 error) *Client_Close_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Client_DeleteSession_Call) RunAndReturn(run func() error) *Client_DeleteSession_Call {
+func (_c *Client_Close_Call) RunAndReturn(run func(context.Context) error) *Client_Close_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetConfig provides a mock function with given fields:
-func (_m *Client) GetConfig() (*model.ConfigResponse, error) {
-	ret := _m.Called()
+// CreateClient provides a mock function with given fields: ctx, client
+func (_m *Client) CreateClient(ctx context.Context, client *model.ClientRequest) (*model.Client, error) {
+	ret := _m.Called(ctx, client)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetConfig")
+		panic("no return value specified for CreateClient")
 	}
 
-	var r0 *model.ConfigResponse
+	var r0 *model.Client
 	var r1 error
-	if rf, ok := ret.Get(0).(func() (*model.ConfigResponse, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ClientRequest) (*model.Client, error)); ok {
+		return rf(ctx, client)
 	}
-	if rf, ok := ret.Get(0).(func() *model.ConfigResponse); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ClientRequest) *model.Client); ok {
+		r0 = rf(ctx, client)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.ConfigResponse)
+			r0 = ret.Get(0).(*model.Client)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ClientRequest) error); ok {
+		r1 = rf(ctx, client)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -186,56 +190,58 @@ func (_m *Client) GetConfig() (*model.ConfigResponse, error) {
 	return r0, r1
 }
 
-// Client_GetConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetConfig'
-type Client_GetConfig_Call struct {
+// Client_CreateClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateClient'
+type Client_CreateClient_Call struct {
 	*mock.Call
 }
 
-// GetConfig is a helper method to define mock.On call
-func (_e *Client_Expecter) GetConfig() *Client_GetConfig_Call {
-	return &Client_GetConfig_Call{Call: _e.mock.On("GetConfig")}
+// CreateClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - client *model.ClientRequest
+func (_e *Client_Expecter) CreateClient(ctx interface{}, client interface{}) *Client_CreateClient_Call {
+	return &Client_CreateClient_Call{Call: _e.mock.On("CreateClient", ctx, client)}
 }
 
-func (_c *Client_GetConfig_Call) Run(run func()) *Client_GetConfig_Call {
+func (_c *Client_CreateClient_Call) Run(run func(ctx context.Context, client *model.ClientRequest)) *Client_CreateClient_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context), args[1].(*model.ClientRequest))
 	})
 	return _c
 }
 
-func (_c *Client_GetConfig_Call) Return(configResponse *model.ConfigResponse, err error) *Client_GetConfig_Call {
-	_c.Call.Return(configResponse, err)
+func (_c *Client_CreateClient_Call) Return(_a0 *model.Client, _a1 error) *Client_CreateClient_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Client_GetConfig_Call) RunAndReturn(run func() (*model.ConfigResponse, error)) *Client_GetConfig_Call {
+func (_c *Client_CreateClient_Call) RunAndReturn(run func(context.Context, *model.ClientRequest) (*model.Client, error)) *Client_CreateClient_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetTeleporter provides a mock function with given fields:
-func (_m *Client) GetTeleporter() ([]byte, error) {
-	ret := _m.Called()
+// CreateDomain provides a mock function with given fields: ctx, domain
+func (_m *Client) CreateDomain(ctx context.Context, domain *model.DomainRequest) (*model.Domain, error) {
+	ret := _m.Called(ctx, domain)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetTeleporter")
+		panic("no return value specified for CreateDomain")
 	}
 
-	var r0 []byte
+	var r0 *model.Domain
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]byte, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context, *model.DomainRequest) (*model.Domain, error)); ok {
+		return rf(ctx, domain)
 	}
-	if rf, ok := ret.Get(0).(func() []byte); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, *model.DomainRequest) *model.Domain); ok {
+		r0 = rf(ctx, domain)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).(*model.Domain)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, *model.DomainRequest) error); ok {
+		r1 = rf(ctx, domain)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -243,56 +249,58 @@ func (_m *Client) GetTeleporter() ([]byte, error) {
 	return r0, r1
 }
 
-// Client_GetTeleporter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTeleporter'
-type Client_GetTeleporter_Call struct {
+// Client_CreateDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateDomain'
+type Client_CreateDomain_Call struct {
 	*mock.Call
 }
 
-// GetTeleporter is a helper method to define mock.On call
-func (_e *Client_Expecter) GetTeleporter() *Client_GetTeleporter_Call {
-	return &Client_GetTeleporter_Call{Call: _e.mock.On("GetTeleporter")}
+// CreateDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - domain *model.DomainRequest
+func (_e *Client_Expecter) CreateDomain(ctx interface{}, domain interface{}) *Client_CreateDomain_Call {
+	return &Client_CreateDomain_Call{Call: _e.mock.On("CreateDomain", ctx, domain)}
 }
 
-func (_c *Client_GetTeleporter_Call) Run(run func()) *Client_GetTeleporter_Call {
+func (_c *Client_CreateDomain_Call) Run(run func(ctx context.Context, domain *model.DomainRequest)) *Client_CreateDomain_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context), args[1].(*model.DomainRequest))
 	})
 	return _c
 }
 
-func (_c *Client_GetTeleporter_Call) Return(_a0 []byte, _a1 error) *Client_GetTeleporter_Call {
+func (_c *Client_CreateDomain_Call) Return(_a0 *model.Domain, _a1 error) *Client_CreateDomain_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Client_GetTeleporter_Call) RunAndReturn(run func() ([]byte, error)) *Client_GetTeleporter_Call {
+func (_c *Client_CreateDomain_Call) RunAndReturn(run func(context.Context, *model.DomainRequest) (*model.Domain, error)) *Client_CreateDomain_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetVersion provides a mock function with given fields:
-func (_m *Client) GetVersion() (*model.VersionResponse, error) {
-	ret := _m.Called()
+// CreateGroup provides a mock function with given fields: ctx, group
+func (_m *Client) CreateGroup(ctx context.Context, group *model.GroupRequest) (*model.Group, error) {
+	ret := _m.Called(ctx, group)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetVersion")
+		panic("no return value specified for CreateGroup")
 	}
 
-	var r0 *model.VersionResponse
+	var r0 *model.Group
 	var r1 error
-	if rf, ok := ret.Get(0).(func() (*model.VersionResponse, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GroupRequest) (*model.Group, error)); ok {
+		return rf(ctx, group)
 	}
-	if rf, ok := ret.Get(0).(func() *model.VersionResponse); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, *model.GroupRequest) *model.Group); ok {
+		r0 = rf(ctx, group)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.VersionResponse)
+			r0 = ret.Get(0).(*model.Group)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, *model.GroupRequest) error); ok {
+		r1 = rf(ctx, group)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -300,44 +308,105 @@ func (_m *Client) GetVersion() (*model.VersionResponse, error) {
 	return r0, r1
 }
 
-// Client_GetVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVersion'
-type Client_GetVersion_Call struct {
+// Client_CreateGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateGroup'
+type Client_CreateGroup_Call struct {
 	*mock.Call
 }
 
-// GetVersion is a helper method to define mock.On call
-func (_e *Client_Expecter) GetVersion() *Client_GetVersion_Call {
-	return &Client_GetVersion_Call{Call: _e.mock.On("GetVersion")}
+// CreateGroup is a helper method to define mock.On call
+//   - ctx context.Context
+//   - group *model.GroupRequest
+func (_e *Client_Expecter) CreateGroup(ctx interface{}, group interface{}) *Client_CreateGroup_Call {
+	return &Client_CreateGroup_Call{Call: _e.mock.On("CreateGroup", ctx, group)}
 }
 
-func (_c *Client_GetVersion_Call) Run(run func()) *Client_GetVersion_Call {
+func (_c *Client_CreateGroup_Call) Run(run func(ctx context.Context, group *model.GroupRequest)) *Client_CreateGroup_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context), args[1].(*model.GroupRequest))
 	})
 	return _c
 }
 
-func (_c *Client_GetVersion_Call) Return(_a0 *model.VersionResponse, _a1 error) *Client_GetVersion_Call {
+func (_c *Client_CreateGroup_Call) Return(_a0 *model.Group, _a1 error) *Client_CreateGroup_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Client_GetVersion_Call) RunAndReturn(run func() (*model.VersionResponse, error)) *Client_GetVersion_Call {
+func (_c *Client_CreateGroup_Call) RunAndReturn(run func(context.Context, *model.GroupRequest) (*model.Group, error)) *Client_CreateGroup_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// PatchConfig provides a mock function with given fields: patchRequest
-func (_m *Client) PatchConfig(patchRequest *model.PatchConfigRequest) error {
-	ret := _m.Called(patchRequest)
+// CreateList provides a mock function with given fields: ctx, list
+func (_m *Client) CreateList(ctx context.Context, list *model.ListRequest) (*model.List, error) {
+	ret := _m.Called(ctx, list)
 
 	if len(ret) == 0 {
-		panic("no return value specified for PatchConfig")
+		panic("no return value specified for CreateList")
+	}
+
+	var r0 *model.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ListRequest) (*model.List, error)); ok {
+		return rf(ctx, list)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.ListRequest) *model.List); ok {
+		r0 = rf(ctx, list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.ListRequest) error); ok {
+		r1 = rf(ctx, list)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_CreateList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateList'
+type Client_CreateList_Call struct {
+	*mock.Call
+}
+
+// CreateList is a helper method to define mock.On call
+//   - ctx context.Context
+//   - list *model.ListRequest
+func (_e *Client_Expecter) CreateList(ctx interface{}, list interface{}) *Client_CreateList_Call {
+	return &Client_CreateList_Call{Call: _e.mock.On("CreateList", ctx, list)}
+}
+
+func (_c *Client_CreateList_Call) Run(run func(ctx context.Context, list *model.ListRequest)) *Client_CreateList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.ListRequest))
+	})
+	return _c
+}
+
+func (_c *Client_CreateList_Call) Return(_a0 *model.List, _a1 error) *Client_CreateList_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_CreateList_Call) RunAndReturn(run func(context.Context, *model.ListRequest) (*model.List, error)) *Client_CreateList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteClient provides a mock function with given fields: ctx, id
+func (_m *Client) DeleteClient(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteClient")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*model.PatchConfigRequest) error); ok {
-		r0 = rf(patchRequest)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -345,45 +414,46 @@ func (_m *Client) PatchConfig(patchRequest *model.PatchConfigRequest) error {
 	return r0
 }
 
-// Client_PatchConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PatchConfig'
-type Client_PatchConfig_Call struct {
+// Client_DeleteClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteClient'
+type Client_DeleteClient_Call struct {
 	*mock.Call
 }
 
-// PatchConfig is a helper method to define mock.On call
-//   - patchRequest *model.PatchConfigRequest
-func (_e *Client_Expecter) PatchConfig(patchRequest interface{}) *Client_PatchConfig_Call {
-	return &Client_PatchConfig_Call{Call: _e.mock.On("PatchConfig", patchRequest)}
+// DeleteClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *Client_Expecter) DeleteClient(ctx interface{}, id interface{}) *Client_DeleteClient_Call {
+	return &Client_DeleteClient_Call{Call: _e.mock.On("DeleteClient", ctx, id)}
 }
 
-func (_c *Client_PatchConfig_Call) Run(run func(patchRequest *model.PatchConfigRequest)) *Client_PatchConfig_Call {
+func (_c *Client_DeleteClient_Call) Run(run func(ctx context.Context, id string)) *Client_DeleteClient_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*model.PatchConfigRequest))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *Client_PatchConfig_Call) Return(_a0 error) *Client_PatchConfig_Call {
+func (_c *Client_DeleteClient_Call) Return(_a0 error) *Client_DeleteClient_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Client_PatchConfig_Call) RunAndReturn(run func(*model.PatchConfigRequest) error) *Client_PatchConfig_Call {
+func (_c *Client_DeleteClient_Call) RunAndReturn(run func(context.Context, string) error) *Client_DeleteClient_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// PostTeleporter provides a mock function with given fields: payload, teleporterRequest
-func (_m *Client) PostTeleporter(payload []byte, teleporterRequest *model.PostTeleporterRequest) error {
-	ret := _m.Called(payload, teleporterRequest)
+// DeleteClients provides a mock function with given fields: ctx, ids
+func (_m *Client) DeleteClients(ctx context.Context, ids []string) error {
+	ret := _m.Called(ctx, ids)
 
 	if len(ret) == 0 {
-		panic("no return value specified for PostTeleporter")
+		panic("no return value specified for DeleteClients")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func([]byte, *model.PostTeleporterRequest) error); ok {
-		r0 = rf(payload, teleporterRequest)
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, ids)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -391,76 +461,1788 @@ func (_m *Client) PostTeleporter(payload []byte, teleporterRequest *model.PostTe
 	return r0
 }
 
-// Client_PostTeleporter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PostTeleporter'
-type Client_PostTeleporter_Call struct {
+// Client_DeleteClients_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteClients'
+type Client_DeleteClients_Call struct {
 	*mock.Call
 }
 
-// PostTeleporter is a helper method to define mock.On call
-//   - payload []byte
-//   - teleporterRequest *model.PostTeleporterRequest
-func (_e *Client_Expecter) PostTeleporter(payload interface{}, teleporterRequest interface{}) *Client_PostTeleporter_Call {
-	return &Client_PostTeleporter_Call{Call: _e.mock.On("PostTeleporter", payload, teleporterRequest)}
+// DeleteClients is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ids []string
+func (_e *Client_Expecter) DeleteClients(ctx interface{}, ids interface{}) *Client_DeleteClients_Call {
+	return &Client_DeleteClients_Call{Call: _e.mock.On("DeleteClients", ctx, ids)}
 }
 
-func (_c *Client_PostTeleporter_Call) Run(run func(payload []byte, teleporterRequest *model.PostTeleporterRequest)) *Client_PostTeleporter_Call {
+func (_c *Client_DeleteClients_Call) Run(run func(ctx context.Context, ids []string)) *Client_DeleteClients_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].([]byte), args[1].(*model.PostTeleporterRequest))
+		run(args[0].(context.Context), args[1].([]string))
 	})
 	return _c
 }
 
-func (_c *Client_PostTeleporter_Call) Return(_a0 error) *Client_PostTeleporter_Call {
+func (_c *Client_DeleteClients_Call) Return(_a0 error) *Client_DeleteClients_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Client_PostTeleporter_Call) RunAndReturn(run func([]byte, *model.PostTeleporterRequest) error) *Client_PostTeleporter_Call {
+func (_c *Client_DeleteClients_Call) RunAndReturn(run func(context.Context, []string) error) *Client_DeleteClients_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// String provides a mock function with given fields:
-func (_m *Client) String() string {
-	ret := _m.Called()
+// DeleteDomain provides a mock function with given fields: ctx, key
+func (_m *Client) DeleteDomain(ctx context.Context, key model.DomainKey) error {
+	ret := _m.Called(ctx, key)
 
 	if len(ret) == 0 {
-		panic("no return value specified for String")
+		panic("no return value specified for DeleteDomain")
 	}
 
-	var r0 string
-	if rf, ok := ret.Get(0).(func() string); ok {
-		r0 = rf()
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DomainKey) error); ok {
+		r0 = rf(ctx, key)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Error(0)
 	}
 
 	return r0
 }
 
-// Client_String_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'String'
-type Client_String_Call struct {
+// Client_DeleteDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteDomain'
+type Client_DeleteDomain_Call struct {
 	*mock.Call
 }
 
-// String is a helper method to define mock.On call
-func (_e *Client_Expecter) String() *Client_String_Call {
-	return &Client_String_Call{Call: _e.mock.On("String")}
+// DeleteDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key model.DomainKey
+func (_e *Client_Expecter) DeleteDomain(ctx interface{}, key interface{}) *Client_DeleteDomain_Call {
+	return &Client_DeleteDomain_Call{Call: _e.mock.On("DeleteDomain", ctx, key)}
 }
 
-func (_c *Client_String_Call) Run(run func()) *Client_String_Call {
+func (_c *Client_DeleteDomain_Call) Run(run func(ctx context.Context, key model.DomainKey)) *Client_DeleteDomain_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context), args[1].(model.DomainKey))
 	})
 	return _c
 }
 
-func (_c *Client_String_Call) Return(_a0 string) *Client_String_Call {
+func (_c *Client_DeleteDomain_Call) Return(_a0 error) *Client_DeleteDomain_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Client_String_Call) RunAndReturn(run func() string) *Client_String_Call {
+func (_c *Client_DeleteDomain_Call) RunAndReturn(run func(context.Context, model.DomainKey) error) *Client_DeleteDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteDomains provides a mock function with given fields: ctx, keys
+func (_m *Client) DeleteDomains(ctx context.Context, keys []model.DomainKey) error {
+	ret := _m.Called(ctx, keys)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDomains")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.DomainKey) error); ok {
+		r0 = rf(ctx, keys)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_DeleteDomains_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteDomains'
+type Client_DeleteDomains_Call struct {
+	*mock.Call
+}
+
+// DeleteDomains is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keys []model.DomainKey
+func (_e *Client_Expecter) DeleteDomains(ctx interface{}, keys interface{}) *Client_DeleteDomains_Call {
+	return &Client_DeleteDomains_Call{Call: _e.mock.On("DeleteDomains", ctx, keys)}
+}
+
+func (_c *Client_DeleteDomains_Call) Run(run func(ctx context.Context, keys []model.DomainKey)) *Client_DeleteDomains_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]model.DomainKey))
+	})
+	return _c
+}
+
+func (_c *Client_DeleteDomains_Call) Return(_a0 error) *Client_DeleteDomains_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_DeleteDomains_Call) RunAndReturn(run func(context.Context, []model.DomainKey) error) *Client_DeleteDomains_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteGroup provides a mock function with given fields: ctx, name
+func (_m *Client) DeleteGroup(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteGroup")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_DeleteGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteGroup'
+type Client_DeleteGroup_Call struct {
+	*mock.Call
+}
+
+// DeleteGroup is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+func (_e *Client_Expecter) DeleteGroup(ctx interface{}, name interface{}) *Client_DeleteGroup_Call {
+	return &Client_DeleteGroup_Call{Call: _e.mock.On("DeleteGroup", ctx, name)}
+}
+
+func (_c *Client_DeleteGroup_Call) Run(run func(ctx context.Context, name string)) *Client_DeleteGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Client_DeleteGroup_Call) Return(_a0 error) *Client_DeleteGroup_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_DeleteGroup_Call) RunAndReturn(run func(context.Context, string) error) *Client_DeleteGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteGroups provides a mock function with given fields: ctx, names
+func (_m *Client) DeleteGroups(ctx context.Context, names []string) error {
+	ret := _m.Called(ctx, names)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteGroups")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) error); ok {
+		r0 = rf(ctx, names)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_DeleteGroups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteGroups'
+type Client_DeleteGroups_Call struct {
+	*mock.Call
+}
+
+// DeleteGroups is a helper method to define mock.On call
+//   - ctx context.Context
+//   - names []string
+func (_e *Client_Expecter) DeleteGroups(ctx interface{}, names interface{}) *Client_DeleteGroups_Call {
+	return &Client_DeleteGroups_Call{Call: _e.mock.On("DeleteGroups", ctx, names)}
+}
+
+func (_c *Client_DeleteGroups_Call) Run(run func(ctx context.Context, names []string)) *Client_DeleteGroups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *Client_DeleteGroups_Call) Return(_a0 error) *Client_DeleteGroups_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_DeleteGroups_Call) RunAndReturn(run func(context.Context, []string) error) *Client_DeleteGroups_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteList provides a mock function with given fields: ctx, key
+func (_m *Client) DeleteList(ctx context.Context, key model.ListKey) error {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteList")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ListKey) error); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_DeleteList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteList'
+type Client_DeleteList_Call struct {
+	*mock.Call
+}
+
+// DeleteList is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key model.ListKey
+func (_e *Client_Expecter) DeleteList(ctx interface{}, key interface{}) *Client_DeleteList_Call {
+	return &Client_DeleteList_Call{Call: _e.mock.On("DeleteList", ctx, key)}
+}
+
+func (_c *Client_DeleteList_Call) Run(run func(ctx context.Context, key model.ListKey)) *Client_DeleteList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.ListKey))
+	})
+	return _c
+}
+
+func (_c *Client_DeleteList_Call) Return(_a0 error) *Client_DeleteList_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_DeleteList_Call) RunAndReturn(run func(context.Context, model.ListKey) error) *Client_DeleteList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteLists provides a mock function with given fields: ctx, keys
+func (_m *Client) DeleteLists(ctx context.Context, keys []model.ListKey) error {
+	ret := _m.Called(ctx, keys)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteLists")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.ListKey) error); ok {
+		r0 = rf(ctx, keys)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_DeleteLists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteLists'
+type Client_DeleteLists_Call struct {
+	*mock.Call
+}
+
+// DeleteLists is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keys []model.ListKey
+func (_e *Client_Expecter) DeleteLists(ctx interface{}, keys interface{}) *Client_DeleteLists_Call {
+	return &Client_DeleteLists_Call{Call: _e.mock.On("DeleteLists", ctx, keys)}
+}
+
+func (_c *Client_DeleteLists_Call) Run(run func(ctx context.Context, keys []model.ListKey)) *Client_DeleteLists_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]model.ListKey))
+	})
+	return _c
+}
+
+func (_c *Client_DeleteLists_Call) Return(_a0 error) *Client_DeleteLists_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_DeleteLists_Call) RunAndReturn(run func(context.Context, []model.ListKey) error) *Client_DeleteLists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteSession provides a mock function with given fields: ctx
+func (_m *Client) DeleteSession(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_DeleteSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteSession'
+type Client_DeleteSession_Call struct {
+	*mock.Call
+}
+
+// DeleteSession is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) DeleteSession(ctx interface{}) *Client_DeleteSession_Call {
+	return &Client_DeleteSession_Call{Call: _e.mock.On("DeleteSession", ctx)}
+}
+
+func (_c *Client_DeleteSession_Call) Run(run func(ctx context.Context)) *Client_DeleteSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_DeleteSession_Call) Return(_a0 error) *Client_DeleteSession_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_DeleteSession_Call) RunAndReturn(run func(context.Context) error) *Client_DeleteSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FlushLogs provides a mock function with given fields: ctx
+func (_m *Client) FlushLogs(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FlushLogs")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_FlushLogs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FlushLogs'
+type Client_FlushLogs_Call struct {
+	*mock.Call
+}
+
+// FlushLogs is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) FlushLogs(ctx interface{}) *Client_FlushLogs_Call {
+	return &Client_FlushLogs_Call{Call: _e.mock.On("FlushLogs", ctx)}
+}
+
+func (_c *Client_FlushLogs_Call) Run(run func(ctx context.Context)) *Client_FlushLogs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_FlushLogs_Call) Return(_a0 error) *Client_FlushLogs_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_FlushLogs_Call) RunAndReturn(run func(context.Context) error) *Client_FlushLogs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FlushNetwork provides a mock function with given fields: ctx
+func (_m *Client) FlushNetwork(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FlushNetwork")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_FlushNetwork_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FlushNetwork'
+type Client_FlushNetwork_Call struct {
+	*mock.Call
+}
+
+// FlushNetwork is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) FlushNetwork(ctx interface{}) *Client_FlushNetwork_Call {
+	return &Client_FlushNetwork_Call{Call: _e.mock.On("FlushNetwork", ctx)}
+}
+
+func (_c *Client_FlushNetwork_Call) Run(run func(ctx context.Context)) *Client_FlushNetwork_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_FlushNetwork_Call) Return(_a0 error) *Client_FlushNetwork_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_FlushNetwork_Call) RunAndReturn(run func(context.Context) error) *Client_FlushNetwork_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBlocking provides a mock function with given fields: ctx
+func (_m *Client) GetBlocking(ctx context.Context) (*model.Blocking, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlocking")
+	}
+
+	var r0 *model.Blocking
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.Blocking, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.Blocking); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Blocking)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetBlocking_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBlocking'
+type Client_GetBlocking_Call struct {
+	*mock.Call
+}
+
+// GetBlocking is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) GetBlocking(ctx interface{}) *Client_GetBlocking_Call {
+	return &Client_GetBlocking_Call{Call: _e.mock.On("GetBlocking", ctx)}
+}
+
+func (_c *Client_GetBlocking_Call) Run(run func(ctx context.Context)) *Client_GetBlocking_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_GetBlocking_Call) Return(_a0 *model.Blocking, _a1 error) *Client_GetBlocking_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetBlocking_Call) RunAndReturn(run func(context.Context) (*model.Blocking, error)) *Client_GetBlocking_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetClient provides a mock function with given fields: ctx, id
+func (_m *Client) GetClient(ctx context.Context, id string) (*model.Client, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClient")
+	}
+
+	var r0 *model.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Client, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Client); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Client)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetClient'
+type Client_GetClient_Call struct {
+	*mock.Call
+}
+
+// GetClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *Client_Expecter) GetClient(ctx interface{}, id interface{}) *Client_GetClient_Call {
+	return &Client_GetClient_Call{Call: _e.mock.On("GetClient", ctx, id)}
+}
+
+func (_c *Client_GetClient_Call) Run(run func(ctx context.Context, id string)) *Client_GetClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Client_GetClient_Call) Return(_a0 *model.Client, _a1 error) *Client_GetClient_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetClient_Call) RunAndReturn(run func(context.Context, string) (*model.Client, error)) *Client_GetClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetClients provides a mock function with given fields: ctx
+func (_m *Client) GetClients(ctx context.Context) (*model.ClientsResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetClients")
+	}
+
+	var r0 *model.ClientsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.ClientsResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.ClientsResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ClientsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetClients_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetClients'
+type Client_GetClients_Call struct {
+	*mock.Call
+}
+
+// GetClients is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) GetClients(ctx interface{}) *Client_GetClients_Call {
+	return &Client_GetClients_Call{Call: _e.mock.On("GetClients", ctx)}
+}
+
+func (_c *Client_GetClients_Call) Run(run func(ctx context.Context)) *Client_GetClients_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_GetClients_Call) Return(_a0 *model.ClientsResponse, _a1 error) *Client_GetClients_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetClients_Call) RunAndReturn(run func(context.Context) (*model.ClientsResponse, error)) *Client_GetClients_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetConfig provides a mock function with given fields: ctx
+func (_m *Client) GetConfig(ctx context.Context) (*model.ConfigResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetConfig")
+	}
+
+	var r0 *model.ConfigResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.ConfigResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.ConfigResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ConfigResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetConfig'
+type Client_GetConfig_Call struct {
+	*mock.Call
+}
+
+// GetConfig is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) GetConfig(ctx interface{}) *Client_GetConfig_Call {
+	return &Client_GetConfig_Call{Call: _e.mock.On("GetConfig", ctx)}
+}
+
+func (_c *Client_GetConfig_Call) Run(run func(ctx context.Context)) *Client_GetConfig_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_GetConfig_Call) Return(configResponse *model.ConfigResponse, err error) *Client_GetConfig_Call {
+	_c.Call.Return(configResponse, err)
+	return _c
+}
+
+func (_c *Client_GetConfig_Call) RunAndReturn(run func(context.Context) (*model.ConfigResponse, error)) *Client_GetConfig_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDomain provides a mock function with given fields: ctx, key
+func (_m *Client) GetDomain(ctx context.Context, key model.DomainKey) (*model.Domain, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDomain")
+	}
+
+	var r0 *model.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DomainKey) (*model.Domain, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.DomainKey) *model.Domain); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Domain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.DomainKey) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDomain'
+type Client_GetDomain_Call struct {
+	*mock.Call
+}
+
+// GetDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key model.DomainKey
+func (_e *Client_Expecter) GetDomain(ctx interface{}, key interface{}) *Client_GetDomain_Call {
+	return &Client_GetDomain_Call{Call: _e.mock.On("GetDomain", ctx, key)}
+}
+
+func (_c *Client_GetDomain_Call) Run(run func(ctx context.Context, key model.DomainKey)) *Client_GetDomain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.DomainKey))
+	})
+	return _c
+}
+
+func (_c *Client_GetDomain_Call) Return(_a0 *model.Domain, _a1 error) *Client_GetDomain_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetDomain_Call) RunAndReturn(run func(context.Context, model.DomainKey) (*model.Domain, error)) *Client_GetDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDomains provides a mock function with given fields: ctx
+func (_m *Client) GetDomains(ctx context.Context) (*model.DomainsResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDomains")
+	}
+
+	var r0 *model.DomainsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.DomainsResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.DomainsResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DomainsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetDomains_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDomains'
+type Client_GetDomains_Call struct {
+	*mock.Call
+}
+
+// GetDomains is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) GetDomains(ctx interface{}) *Client_GetDomains_Call {
+	return &Client_GetDomains_Call{Call: _e.mock.On("GetDomains", ctx)}
+}
+
+func (_c *Client_GetDomains_Call) Run(run func(ctx context.Context)) *Client_GetDomains_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_GetDomains_Call) Return(_a0 *model.DomainsResponse, _a1 error) *Client_GetDomains_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetDomains_Call) RunAndReturn(run func(context.Context) (*model.DomainsResponse, error)) *Client_GetDomains_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetDomainsOf provides a mock function with given fields: ctx, domainType, kind
+func (_m *Client) GetDomainsOf(ctx context.Context, domainType string, kind string) (*model.DomainsResponse, error) {
+	ret := _m.Called(ctx, domainType, kind)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDomainsOf")
+	}
+
+	var r0 *model.DomainsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*model.DomainsResponse, error)); ok {
+		return rf(ctx, domainType, kind)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *model.DomainsResponse); ok {
+		r0 = rf(ctx, domainType, kind)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.DomainsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, domainType, kind)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetDomainsOf_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDomainsOf'
+type Client_GetDomainsOf_Call struct {
+	*mock.Call
+}
+
+// GetDomainsOf is a helper method to define mock.On call
+//   - ctx context.Context
+//   - domainType string
+//   - kind string
+func (_e *Client_Expecter) GetDomainsOf(ctx interface{}, domainType interface{}, kind interface{}) *Client_GetDomainsOf_Call {
+	return &Client_GetDomainsOf_Call{Call: _e.mock.On("GetDomainsOf", ctx, domainType, kind)}
+}
+
+func (_c *Client_GetDomainsOf_Call) Run(run func(ctx context.Context, domainType string, kind string)) *Client_GetDomainsOf_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *Client_GetDomainsOf_Call) Return(_a0 *model.DomainsResponse, _a1 error) *Client_GetDomainsOf_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetDomainsOf_Call) RunAndReturn(run func(context.Context, string, string) (*model.DomainsResponse, error)) *Client_GetDomainsOf_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetGroup provides a mock function with given fields: ctx, name
+func (_m *Client) GetGroup(ctx context.Context, name string) (*model.Group, error) {
+	ret := _m.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroup")
+	}
+
+	var r0 *model.Group
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*model.Group, error)); ok {
+		return rf(ctx, name)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *model.Group); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetGroup'
+type Client_GetGroup_Call struct {
+	*mock.Call
+}
+
+// GetGroup is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+func (_e *Client_Expecter) GetGroup(ctx interface{}, name interface{}) *Client_GetGroup_Call {
+	return &Client_GetGroup_Call{Call: _e.mock.On("GetGroup", ctx, name)}
+}
+
+func (_c *Client_GetGroup_Call) Run(run func(ctx context.Context, name string)) *Client_GetGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *Client_GetGroup_Call) Return(_a0 *model.Group, _a1 error) *Client_GetGroup_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetGroup_Call) RunAndReturn(run func(context.Context, string) (*model.Group, error)) *Client_GetGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetGroups provides a mock function with given fields: ctx
+func (_m *Client) GetGroups(ctx context.Context) (*model.GroupsResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroups")
+	}
+
+	var r0 *model.GroupsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.GroupsResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.GroupsResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.GroupsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetGroups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetGroups'
+type Client_GetGroups_Call struct {
+	*mock.Call
+}
+
+// GetGroups is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) GetGroups(ctx interface{}) *Client_GetGroups_Call {
+	return &Client_GetGroups_Call{Call: _e.mock.On("GetGroups", ctx)}
+}
+
+func (_c *Client_GetGroups_Call) Run(run func(ctx context.Context)) *Client_GetGroups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_GetGroups_Call) Return(_a0 *model.GroupsResponse, _a1 error) *Client_GetGroups_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetGroups_Call) RunAndReturn(run func(context.Context) (*model.GroupsResponse, error)) *Client_GetGroups_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetList provides a mock function with given fields: ctx, key
+func (_m *Client) GetList(ctx context.Context, key model.ListKey) (*model.List, error) {
+	ret := _m.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetList")
+	}
+
+	var r0 *model.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ListKey) (*model.List, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.ListKey) *model.List); ok {
+		r0 = rf(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.ListKey) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetList'
+type Client_GetList_Call struct {
+	*mock.Call
+}
+
+// GetList is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key model.ListKey
+func (_e *Client_Expecter) GetList(ctx interface{}, key interface{}) *Client_GetList_Call {
+	return &Client_GetList_Call{Call: _e.mock.On("GetList", ctx, key)}
+}
+
+func (_c *Client_GetList_Call) Run(run func(ctx context.Context, key model.ListKey)) *Client_GetList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.ListKey))
+	})
+	return _c
+}
+
+func (_c *Client_GetList_Call) Return(_a0 *model.List, _a1 error) *Client_GetList_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetList_Call) RunAndReturn(run func(context.Context, model.ListKey) (*model.List, error)) *Client_GetList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetLists provides a mock function with given fields: ctx
+func (_m *Client) GetLists(ctx context.Context) (*model.ListsResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLists")
+	}
+
+	var r0 *model.ListsResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.ListsResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.ListsResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.ListsResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetLists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLists'
+type Client_GetLists_Call struct {
+	*mock.Call
+}
+
+// GetLists is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) GetLists(ctx interface{}) *Client_GetLists_Call {
+	return &Client_GetLists_Call{Call: _e.mock.On("GetLists", ctx)}
+}
+
+func (_c *Client_GetLists_Call) Run(run func(ctx context.Context)) *Client_GetLists_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_GetLists_Call) Return(_a0 *model.ListsResponse, _a1 error) *Client_GetLists_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetLists_Call) RunAndReturn(run func(context.Context) (*model.ListsResponse, error)) *Client_GetLists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTeleporter provides a mock function with given fields: ctx
+func (_m *Client) GetTeleporter(ctx context.Context) ([]byte, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTeleporter")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]byte, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []byte); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetTeleporter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTeleporter'
+type Client_GetTeleporter_Call struct {
+	*mock.Call
+}
+
+// GetTeleporter is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) GetTeleporter(ctx interface{}) *Client_GetTeleporter_Call {
+	return &Client_GetTeleporter_Call{Call: _e.mock.On("GetTeleporter", ctx)}
+}
+
+func (_c *Client_GetTeleporter_Call) Run(run func(ctx context.Context)) *Client_GetTeleporter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_GetTeleporter_Call) Return(_a0 []byte, _a1 error) *Client_GetTeleporter_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetTeleporter_Call) RunAndReturn(run func(context.Context) ([]byte, error)) *Client_GetTeleporter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetVersion provides a mock function with given fields: ctx
+func (_m *Client) GetVersion(ctx context.Context) (*model.VersionResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetVersion")
+	}
+
+	var r0 *model.VersionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*model.VersionResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *model.VersionResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.VersionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_GetVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetVersion'
+type Client_GetVersion_Call struct {
+	*mock.Call
+}
+
+// GetVersion is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) GetVersion(ctx interface{}) *Client_GetVersion_Call {
+	return &Client_GetVersion_Call{Call: _e.mock.On("GetVersion", ctx)}
+}
+
+func (_c *Client_GetVersion_Call) Run(run func(ctx context.Context)) *Client_GetVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_GetVersion_Call) Return(_a0 *model.VersionResponse, _a1 error) *Client_GetVersion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_GetVersion_Call) RunAndReturn(run func(context.Context) (*model.VersionResponse, error)) *Client_GetVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PatchConfig provides a mock function with given fields: ctx, patchRequest
+func (_m *Client) PatchConfig(ctx context.Context, patchRequest *model.PatchConfigRequest) error {
+	ret := _m.Called(ctx, patchRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PatchConfig")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.PatchConfigRequest) error); ok {
+		r0 = rf(ctx, patchRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_PatchConfig_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PatchConfig'
+type Client_PatchConfig_Call struct {
+	*mock.Call
+}
+
+// PatchConfig is a helper method to define mock.On call
+//   - ctx context.Context
+//   - patchRequest *model.PatchConfigRequest
+func (_e *Client_Expecter) PatchConfig(ctx interface{}, patchRequest interface{}) *Client_PatchConfig_Call {
+	return &Client_PatchConfig_Call{Call: _e.mock.On("PatchConfig", ctx, patchRequest)}
+}
+
+func (_c *Client_PatchConfig_Call) Run(run func(ctx context.Context, patchRequest *model.PatchConfigRequest)) *Client_PatchConfig_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.PatchConfigRequest))
+	})
+	return _c
+}
+
+func (_c *Client_PatchConfig_Call) Return(_a0 error) *Client_PatchConfig_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_PatchConfig_Call) RunAndReturn(run func(context.Context, *model.PatchConfigRequest) error) *Client_PatchConfig_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PiHole provides a mock function with given fields:
+func (_m *Client) PiHole() model.PiHole {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PiHole")
+	}
+
+	var r0 model.PiHole
+	if rf, ok := ret.Get(0).(func() model.PiHole); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(model.PiHole)
+	}
+
+	return r0
+}
+
+// Client_PiHole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PiHole'
+type Client_PiHole_Call struct {
+	*mock.Call
+}
+
+// PiHole is a helper method to define mock.On call
+func (_e *Client_Expecter) PiHole() *Client_PiHole_Call {
+	return &Client_PiHole_Call{Call: _e.mock.On("PiHole")}
+}
+
+func (_c *Client_PiHole_Call) Run(run func()) *Client_PiHole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Client_PiHole_Call) Return(_a0 model.PiHole) *Client_PiHole_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_PiHole_Call) RunAndReturn(run func() model.PiHole) *Client_PiHole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PostTeleporter provides a mock function with given fields: ctx, payload, teleporterRequest
+func (_m *Client) PostTeleporter(ctx context.Context, payload []byte, teleporterRequest *model.PostTeleporterRequest) error {
+	ret := _m.Called(ctx, payload, teleporterRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PostTeleporter")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte, *model.PostTeleporterRequest) error); ok {
+		r0 = rf(ctx, payload, teleporterRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_PostTeleporter_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PostTeleporter'
+type Client_PostTeleporter_Call struct {
+	*mock.Call
+}
+
+// PostTeleporter is a helper method to define mock.On call
+//   - ctx context.Context
+//   - payload []byte
+//   - teleporterRequest *model.PostTeleporterRequest
+func (_e *Client_Expecter) PostTeleporter(ctx interface{}, payload interface{}, teleporterRequest interface{}) *Client_PostTeleporter_Call {
+	return &Client_PostTeleporter_Call{Call: _e.mock.On("PostTeleporter", ctx, payload, teleporterRequest)}
+}
+
+func (_c *Client_PostTeleporter_Call) Run(run func(ctx context.Context, payload []byte, teleporterRequest *model.PostTeleporterRequest)) *Client_PostTeleporter_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte), args[2].(*model.PostTeleporterRequest))
+	})
+	return _c
+}
+
+func (_c *Client_PostTeleporter_Call) Return(_a0 error) *Client_PostTeleporter_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_PostTeleporter_Call) RunAndReturn(run func(context.Context, []byte, *model.PostTeleporterRequest) error) *Client_PostTeleporter_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestartDNS provides a mock function with given fields: ctx
+func (_m *Client) RestartDNS(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestartDNS")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_RestartDNS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestartDNS'
+type Client_RestartDNS_Call struct {
+	*mock.Call
+}
+
+// RestartDNS is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Client_Expecter) RestartDNS(ctx interface{}) *Client_RestartDNS_Call {
+	return &Client_RestartDNS_Call{Call: _e.mock.On("RestartDNS", ctx)}
+}
+
+func (_c *Client_RestartDNS_Call) Run(run func(ctx context.Context)) *Client_RestartDNS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *Client_RestartDNS_Call) Return(_a0 error) *Client_RestartDNS_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_RestartDNS_Call) RunAndReturn(run func(context.Context) error) *Client_RestartDNS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RunGravity provides a mock function with given fields: ctx, output
+func (_m *Client) RunGravity(ctx context.Context, output func(string)) error {
+	ret := _m.Called(ctx, output)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RunGravity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(string)) error); ok {
+		r0 = rf(ctx, output)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Client_RunGravity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RunGravity'
+type Client_RunGravity_Call struct {
+	*mock.Call
+}
+
+// RunGravity is a helper method to define mock.On call
+//   - ctx context.Context
+//   - output func(string)
+func (_e *Client_Expecter) RunGravity(ctx interface{}, output interface{}) *Client_RunGravity_Call {
+	return &Client_RunGravity_Call{Call: _e.mock.On("RunGravity", ctx, output)}
+}
+
+func (_c *Client_RunGravity_Call) Run(run func(ctx context.Context, output func(string))) *Client_RunGravity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(func(string)))
+	})
+	return _c
+}
+
+func (_c *Client_RunGravity_Call) Return(_a0 error) *Client_RunGravity_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_RunGravity_Call) RunAndReturn(run func(context.Context, func(string)) error) *Client_RunGravity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetBlocking provides a mock function with given fields: ctx, blocking
+func (_m *Client) SetBlocking(ctx context.Context, blocking *model.BlockingRequest) (*model.Blocking, error) {
+	ret := _m.Called(ctx, blocking)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetBlocking")
+	}
+
+	var r0 *model.Blocking
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.BlockingRequest) (*model.Blocking, error)); ok {
+		return rf(ctx, blocking)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.BlockingRequest) *model.Blocking); ok {
+		r0 = rf(ctx, blocking)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Blocking)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.BlockingRequest) error); ok {
+		r1 = rf(ctx, blocking)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_SetBlocking_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetBlocking'
+type Client_SetBlocking_Call struct {
+	*mock.Call
+}
+
+// SetBlocking is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blocking *model.BlockingRequest
+func (_e *Client_Expecter) SetBlocking(ctx interface{}, blocking interface{}) *Client_SetBlocking_Call {
+	return &Client_SetBlocking_Call{Call: _e.mock.On("SetBlocking", ctx, blocking)}
+}
+
+func (_c *Client_SetBlocking_Call) Run(run func(ctx context.Context, blocking *model.BlockingRequest)) *Client_SetBlocking_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.BlockingRequest))
+	})
+	return _c
+}
+
+func (_c *Client_SetBlocking_Call) Return(_a0 *model.Blocking, _a1 error) *Client_SetBlocking_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_SetBlocking_Call) RunAndReturn(run func(context.Context, *model.BlockingRequest) (*model.Blocking, error)) *Client_SetBlocking_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// String provides a mock function with given fields:
+func (_m *Client) String() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for String")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Client_String_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'String'
+type Client_String_Call struct {
+	*mock.Call
+}
+
+// String is a helper method to define mock.On call
+func (_e *Client_Expecter) String() *Client_String_Call {
+	return &Client_String_Call{Call: _e.mock.On("String")}
+}
+
+func (_c *Client_String_Call) Run(run func()) *Client_String_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Client_String_Call) Return(_a0 string) *Client_String_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Client_String_Call) RunAndReturn(run func() string) *Client_String_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateClient provides a mock function with given fields: ctx, id, client
+func (_m *Client) UpdateClient(ctx context.Context, id string, client *model.ClientRequest) (*model.Client, error) {
+	ret := _m.Called(ctx, id, client)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateClient")
+	}
+
+	var r0 *model.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.ClientRequest) (*model.Client, error)); ok {
+		return rf(ctx, id, client)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.ClientRequest) *model.Client); ok {
+		r0 = rf(ctx, id, client)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Client)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.ClientRequest) error); ok {
+		r1 = rf(ctx, id, client)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_UpdateClient_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateClient'
+type Client_UpdateClient_Call struct {
+	*mock.Call
+}
+
+// UpdateClient is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - client *model.ClientRequest
+func (_e *Client_Expecter) UpdateClient(ctx interface{}, id interface{}, client interface{}) *Client_UpdateClient_Call {
+	return &Client_UpdateClient_Call{Call: _e.mock.On("UpdateClient", ctx, id, client)}
+}
+
+func (_c *Client_UpdateClient_Call) Run(run func(ctx context.Context, id string, client *model.ClientRequest)) *Client_UpdateClient_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*model.ClientRequest))
+	})
+	return _c
+}
+
+func (_c *Client_UpdateClient_Call) Return(_a0 *model.Client, _a1 error) *Client_UpdateClient_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_UpdateClient_Call) RunAndReturn(run func(context.Context, string, *model.ClientRequest) (*model.Client, error)) *Client_UpdateClient_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateDomain provides a mock function with given fields: ctx, key, domain
+func (_m *Client) UpdateDomain(ctx context.Context, key model.DomainKey, domain *model.DomainRequest) (*model.Domain, error) {
+	ret := _m.Called(ctx, key, domain)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDomain")
+	}
+
+	var r0 *model.Domain
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DomainKey, *model.DomainRequest) (*model.Domain, error)); ok {
+		return rf(ctx, key, domain)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.DomainKey, *model.DomainRequest) *model.Domain); ok {
+		r0 = rf(ctx, key, domain)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Domain)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.DomainKey, *model.DomainRequest) error); ok {
+		r1 = rf(ctx, key, domain)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_UpdateDomain_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateDomain'
+type Client_UpdateDomain_Call struct {
+	*mock.Call
+}
+
+// UpdateDomain is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key model.DomainKey
+//   - domain *model.DomainRequest
+func (_e *Client_Expecter) UpdateDomain(ctx interface{}, key interface{}, domain interface{}) *Client_UpdateDomain_Call {
+	return &Client_UpdateDomain_Call{Call: _e.mock.On("UpdateDomain", ctx, key, domain)}
+}
+
+func (_c *Client_UpdateDomain_Call) Run(run func(ctx context.Context, key model.DomainKey, domain *model.DomainRequest)) *Client_UpdateDomain_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.DomainKey), args[2].(*model.DomainRequest))
+	})
+	return _c
+}
+
+func (_c *Client_UpdateDomain_Call) Return(_a0 *model.Domain, _a1 error) *Client_UpdateDomain_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_UpdateDomain_Call) RunAndReturn(run func(context.Context, model.DomainKey, *model.DomainRequest) (*model.Domain, error)) *Client_UpdateDomain_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateGroup provides a mock function with given fields: ctx, name, group
+func (_m *Client) UpdateGroup(ctx context.Context, name string, group *model.GroupRequest) (*model.Group, error) {
+	ret := _m.Called(ctx, name, group)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateGroup")
+	}
+
+	var r0 *model.Group
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GroupRequest) (*model.Group, error)); ok {
+		return rf(ctx, name, group)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *model.GroupRequest) *model.Group); ok {
+		r0 = rf(ctx, name, group)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Group)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *model.GroupRequest) error); ok {
+		r1 = rf(ctx, name, group)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_UpdateGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateGroup'
+type Client_UpdateGroup_Call struct {
+	*mock.Call
+}
+
+// UpdateGroup is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+//   - group *model.GroupRequest
+func (_e *Client_Expecter) UpdateGroup(ctx interface{}, name interface{}, group interface{}) *Client_UpdateGroup_Call {
+	return &Client_UpdateGroup_Call{Call: _e.mock.On("UpdateGroup", ctx, name, group)}
+}
+
+func (_c *Client_UpdateGroup_Call) Run(run func(ctx context.Context, name string, group *model.GroupRequest)) *Client_UpdateGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*model.GroupRequest))
+	})
+	return _c
+}
+
+func (_c *Client_UpdateGroup_Call) Return(_a0 *model.Group, _a1 error) *Client_UpdateGroup_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_UpdateGroup_Call) RunAndReturn(run func(context.Context, string, *model.GroupRequest) (*model.Group, error)) *Client_UpdateGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateList provides a mock function with given fields: ctx, key, list
+func (_m *Client) UpdateList(ctx context.Context, key model.ListKey, list *model.ListRequest) (*model.List, error) {
+	ret := _m.Called(ctx, key, list)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateList")
+	}
+
+	var r0 *model.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ListKey, *model.ListRequest) (*model.List, error)); ok {
+		return rf(ctx, key, list)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.ListKey, *model.ListRequest) *model.List); ok {
+		r0 = rf(ctx, key, list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.ListKey, *model.ListRequest) error); ok {
+		r1 = rf(ctx, key, list)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_UpdateList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateList'
+type Client_UpdateList_Call struct {
+	*mock.Call
+}
+
+// UpdateList is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key model.ListKey
+//   - list *model.ListRequest
+func (_e *Client_Expecter) UpdateList(ctx interface{}, key interface{}, list interface{}) *Client_UpdateList_Call {
+	return &Client_UpdateList_Call{Call: _e.mock.On("UpdateList", ctx, key, list)}
+}
+
+func (_c *Client_UpdateList_Call) Run(run func(ctx context.Context, key model.ListKey, list *model.ListRequest)) *Client_UpdateList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.ListKey), args[2].(*model.ListRequest))
+	})
+	return _c
+}
+
+func (_c *Client_UpdateList_Call) Return(_a0 *model.List, _a1 error) *Client_UpdateList_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_UpdateList_Call) RunAndReturn(run func(context.Context, model.ListKey, *model.ListRequest) (*model.List, error)) *Client_UpdateList_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -3,7 +3,10 @@
 package sync
 
 import (
+	context "context"
+
 	config "github.com/lovelaze/nebula-sync/internal/config"
+	sync "github.com/lovelaze/nebula-sync/internal/sync"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -20,22 +23,93 @@ func (_m *Target) EXPECT() *Target_Expecter {
 	return &Target_Expecter{mock: &_m.Mock}
 }
 
-// FullSync provides a mock function with given fields:
-func (_m *Target) FullSync() error {
-	ret := _m.Called()
+// Diff provides a mock function with given fields: ctx, syncSettings
+func (_m *Target) Diff(ctx context.Context, syncSettings *config.SyncSettings) (*sync.DiffResult, error) {
+	ret := _m.Called(ctx, syncSettings)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Diff")
+	}
+
+	var r0 *sync.DiffResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *config.SyncSettings) (*sync.DiffResult, error)); ok {
+		return rf(ctx, syncSettings)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *config.SyncSettings) *sync.DiffResult); ok {
+		r0 = rf(ctx, syncSettings)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sync.DiffResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *config.SyncSettings) error); ok {
+		r1 = rf(ctx, syncSettings)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Target_Diff_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Diff'
+type Target_Diff_Call struct {
+	*mock.Call
+}
+
+// Diff is a helper method to define mock.On call
+//   - ctx context.Context
+//   - syncSettings *config.SyncSettings
+func (_e *Target_Expecter) Diff(ctx interface{}, syncSettings interface{}) *Target_Diff_Call {
+	return &Target_Diff_Call{Call: _e.mock.On("Diff", ctx, syncSettings)}
+}
+
+func (_c *Target_Diff_Call) Run(run func(ctx context.Context, syncSettings *config.SyncSettings)) *Target_Diff_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*config.SyncSettings))
+	})
+	return _c
+}
+
+func (_c *Target_Diff_Call) Return(_a0 *sync.DiffResult, _a1 error) *Target_Diff_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Target_Diff_Call) RunAndReturn(run func(context.Context, *config.SyncSettings) (*sync.DiffResult, error)) *Target_Diff_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FullSync provides a mock function with given fields: ctx
+func (_m *Target) FullSync(ctx context.Context) (*sync.SyncResult, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FullSync")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func() error); ok {
-		r0 = rf()
+	var r0 *sync.SyncResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*sync.SyncResult, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *sync.SyncResult); ok {
+		r0 = rf(ctx)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sync.SyncResult)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Target_FullSync_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FullSync'
@@ -44,43 +118,56 @@ type Target_FullSync_Call struct {
 }
 
 // FullSync is a helper method to define mock.On call
-func (_e *Target_Expecter) FullSync() *Target_FullSync_Call {
-	return &Target_FullSync_Call{Call: _e.mock.On("FullSync")}
+//   - ctx context.Context
+func (_e *Target_Expecter) FullSync(ctx interface{}) *Target_FullSync_Call {
+	return &Target_FullSync_Call{Call: _e.mock.On("FullSync", ctx)}
 }
 
-func (_c *Target_FullSync_Call) Run(run func()) *Target_FullSync_Call {
+func (_c *Target_FullSync_Call) Run(run func(ctx context.Context)) *Target_FullSync_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *Target_FullSync_Call) Return(_a0 error) *Target_FullSync_Call {
-	_c.Call.Return(_a0)
+func (_c *Target_FullSync_Call) Return(_a0 *sync.SyncResult, _a1 error) *Target_FullSync_Call {
+	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Target_FullSync_Call) RunAndReturn(run func() error) *Target_FullSync_Call {
+func (_c *Target_FullSync_Call) RunAndReturn(run func(context.Context) (*sync.SyncResult, error)) *Target_FullSync_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ManualSync provides a mock function with given fields: syncSettings
-func (_m *Target) ManualSync(syncSettings *config.SyncSettings) error {
-	ret := _m.Called(syncSettings)
+// ManualSync provides a mock function with given fields: ctx, syncSettings
+func (_m *Target) ManualSync(ctx context.Context, syncSettings *config.SyncSettings) (*sync.SyncResult, error) {
+	ret := _m.Called(ctx, syncSettings)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ManualSync")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(*config.SyncSettings) error); ok {
-		r0 = rf(syncSettings)
+	var r0 *sync.SyncResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *config.SyncSettings) (*sync.SyncResult, error)); ok {
+		return rf(ctx, syncSettings)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *config.SyncSettings) *sync.SyncResult); ok {
+		r0 = rf(ctx, syncSettings)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*sync.SyncResult)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, *config.SyncSettings) error); ok {
+		r1 = rf(ctx, syncSettings)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Target_ManualSync_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ManualSync'
@@ -89,24 +176,129 @@ type Target_ManualSync_Call struct {
 }
 
 // ManualSync is a helper method to define mock.On call
+//   - ctx context.Context
 //   - syncSettings *config.SyncSettings
-func (_e *Target_Expecter) ManualSync(syncSettings interface{}) *Target_ManualSync_Call {
-	return &Target_ManualSync_Call{Call: _e.mock.On("ManualSync", syncSettings)}
+func (_e *Target_Expecter) ManualSync(ctx interface{}, syncSettings interface{}) *Target_ManualSync_Call {
+	return &Target_ManualSync_Call{Call: _e.mock.On("ManualSync", ctx, syncSettings)}
+}
+
+func (_c *Target_ManualSync_Call) Run(run func(ctx context.Context, syncSettings *config.SyncSettings)) *Target_ManualSync_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*config.SyncSettings))
+	})
+	return _c
+}
+
+func (_c *Target_ManualSync_Call) Return(_a0 *sync.SyncResult, _a1 error) *Target_ManualSync_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
 }
 
-func (_c *Target_ManualSync_Call) Run(run func(syncSettings *config.SyncSettings)) *Target_ManualSync_Call {
+func (_c *Target_ManualSync_Call) RunAndReturn(run func(context.Context, *config.SyncSettings) (*sync.SyncResult, error)) *Target_ManualSync_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SyncBlocking provides a mock function with given fields: ctx
+func (_m *Target) SyncBlocking(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SyncBlocking")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Target_SyncBlocking_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SyncBlocking'
+type Target_SyncBlocking_Call struct {
+	*mock.Call
+}
+
+// SyncBlocking is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *Target_Expecter) SyncBlocking(ctx interface{}) *Target_SyncBlocking_Call {
+	return &Target_SyncBlocking_Call{Call: _e.mock.On("SyncBlocking", ctx)}
+}
+
+func (_c *Target_SyncBlocking_Call) Run(run func(ctx context.Context)) *Target_SyncBlocking_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*config.SyncSettings))
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *Target_ManualSync_Call) Return(_a0 error) *Target_ManualSync_Call {
+func (_c *Target_SyncBlocking_Call) Return(_a0 error) *Target_SyncBlocking_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *Target_ManualSync_Call) RunAndReturn(run func(*config.SyncSettings) error) *Target_ManualSync_Call {
+func (_c *Target_SyncBlocking_Call) RunAndReturn(run func(context.Context) error) *Target_SyncBlocking_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithReplicas provides a mock function with given fields: replicas
+func (_m *Target) WithReplicas(replicas []string) (sync.Target, error) {
+	ret := _m.Called(replicas)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithReplicas")
+	}
+
+	var r0 sync.Target
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) (sync.Target, error)); ok {
+		return rf(replicas)
+	}
+	if rf, ok := ret.Get(0).(func([]string) sync.Target); ok {
+		r0 = rf(replicas)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(sync.Target)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(replicas)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Target_WithReplicas_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithReplicas'
+type Target_WithReplicas_Call struct {
+	*mock.Call
+}
+
+// WithReplicas is a helper method to define mock.On call
+//   - replicas []string
+func (_e *Target_Expecter) WithReplicas(replicas interface{}) *Target_WithReplicas_Call {
+	return &Target_WithReplicas_Call{Call: _e.mock.On("WithReplicas", replicas)}
+}
+
+func (_c *Target_WithReplicas_Call) Run(run func(replicas []string)) *Target_WithReplicas_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]string))
+	})
+	return _c
+}
+
+func (_c *Target_WithReplicas_Call) Return(_a0 sync.Target, _a1 error) *Target_WithReplicas_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Target_WithReplicas_Call) RunAndReturn(run func([]string) (sync.Target, error)) *Target_WithReplicas_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -0,0 +1,220 @@
+package notify
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testEvent = Event{
+	Event:   EventFailure,
+	Title:   "nebula-sync: sync failed",
+	Message: "full sync failed at auth: authenticate: invalid password",
+	Time:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	Mode:    "full",
+	Error:   "authenticate: invalid password",
+	Phase:   "auth",
+}
+
+type request struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+func newServer(t *testing.T, status int) (*httptest.Server, *request) {
+	received := &request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		*received = request{path: r.URL.Path, header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestWebhook_Notify(t *testing.T) {
+	server, received := newServer(t, http.StatusNoContent)
+
+	err := (&webhook{url: server.URL + "/hook"}).Notify(testEvent)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/hook", received.path)
+	assert.Equal(t, "application/json", received.header.Get("Content-Type"))
+	event := Event{}
+	require.NoError(t, json.Unmarshal(received.body, &event))
+	assert.Equal(t, testEvent, event)
+}
+
+func TestWebhook_Notify_status(t *testing.T) {
+	server, _ := newServer(t, http.StatusInternalServerError)
+
+	err := (&webhook{url: server.URL + "/secret-token"}).Notify(testEvent)
+	assert.ErrorContains(t, err, "unexpected status code: 500")
+	assert.NotContains(t, err.Error(), "secret-token")
+}
+
+func TestSlack_Notify(t *testing.T) {
+	server, received := newServer(t, http.StatusOK)
+
+	err := (&slack{url: server.URL}).Notify(testEvent)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"text": "*nebula-sync: sync failed*\nfull sync failed at auth: authenticate: invalid password"}`, string(received.body))
+}
+
+func TestNtfy_Notify(t *testing.T) {
+	server, received := newServer(t, http.StatusOK)
+	t.Setenv("NTFY_TOKEN", "tk_secret")
+
+	err := (&ntfy{url: server.URL + "/nebula-sync", token: "env:NTFY_TOKEN"}).Notify(testEvent)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/nebula-sync", received.path)
+	assert.Equal(t, testEvent.Title, received.header.Get("Title"))
+	assert.Equal(t, "high", received.header.Get("Priority"))
+	assert.Equal(t, "Bearer tk_secret", received.header.Get("Authorization"))
+	assert.Equal(t, testEvent.Message, string(received.body))
+}
+
+func TestGotify_Notify(t *testing.T) {
+	server, received := newServer(t, http.StatusOK)
+
+	err := (&gotify{url: server.URL, token: "app-token"}).Notify(testEvent)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/message", received.path)
+	assert.Equal(t, "app-token", received.header.Get("X-Gotify-Key"))
+	assert.JSONEq(t, `{"title": "nebula-sync: sync failed", "message": "full sync failed at auth: authenticate: invalid password", "priority": 8}`, string(received.body))
+}
+
+func TestEmail_Notify(t *testing.T) {
+	email := newSMTP(config.SMTPNotifier{
+		Host:     "smtp.example.com",
+		Port:     587,
+		Username: "nebula",
+		Password: "password",
+		From:     "nebula-sync@example.com",
+		To:       []string{"admin@example.com", "ops@example.com"},
+	})
+
+	var addr, from string
+	var to []string
+	var msg []byte
+	email.sendMail = func(a string, auth smtp.Auth, f string, t []string, m []byte) error {
+		addr, from, to, msg = a, f, t, m
+		return nil
+	}
+
+	err := email.Notify(testEvent)
+	require.NoError(t, err)
+
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.Equal(t, "nebula-sync@example.com", from)
+	assert.Equal(t, []string{"admin@example.com", "ops@example.com"}, to)
+	assert.Equal(t, "From: nebula-sync@example.com\r\n"+
+		"To: admin@example.com, ops@example.com\r\n"+
+		"Subject: nebula-sync: sync failed\r\n"+
+		"Date: Wed, 01 Jan 2025 12:00:00 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\n"+
+		"\r\n"+
+		"full sync failed at auth: authenticate: invalid password\r\n", string(msg))
+}
+
+func TestEmail_Notify_implicitTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: server.TLS.Certificates})
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	// A minimal SMTP server, the connection is TLS from the start.
+	var auth, data string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		text := textproto.NewConn(conn)
+		text.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			switch command, _, _ := strings.Cut(line, " "); command {
+			case "EHLO":
+				text.PrintfLine("250-localhost")
+				text.PrintfLine("250 AUTH PLAIN")
+			case "AUTH":
+				auth = line
+				text.PrintfLine("235 Authenticated")
+			case "DATA":
+				text.PrintfLine("354 Go ahead")
+				lines, _ := text.ReadDotLines()
+				data = strings.Join(lines, "\n")
+				text.PrintfLine("250 OK")
+			case "QUIT":
+				text.PrintfLine("221 Bye")
+				return
+			default:
+				text.PrintfLine("250 OK")
+			}
+		}
+	}()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	email := newSMTP(config.SMTPNotifier{
+		Host:     "127.0.0.1",
+		Port:     smtpsPort,
+		Username: "nebula",
+		Password: "password",
+		From:     "nebula-sync@example.com",
+		To:       []string{"admin@example.com"},
+	})
+	require.True(t, email.implicitTLS)
+	email.addr = net.JoinHostPort("127.0.0.1", port)
+	email.tlsConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+	err = email.Notify(testEvent)
+	require.NoError(t, err)
+	<-done
+
+	assert.Equal(t, "AUTH PLAIN "+base64.StdEncoding.EncodeToString([]byte("\x00nebula\x00password")), auth)
+	assert.Contains(t, data, "Subject: nebula-sync: sync failed")
+	assert.Contains(t, data, "full sync failed at auth: authenticate: invalid password")
+}
+
+func TestNewDispatcher(t *testing.T) {
+	dispatcher := NewDispatcher(config.Notify{
+		Webhook: config.WebhookNotifier{URL: "https://example.com/hook", On: []string{config.NotifyAlways}},
+		Gotify:  config.TokenNotifier{URL: "https://gotify.example.com", Token: "token", On: []string{config.NotifyFailure}},
+		SMTP:    config.SMTPNotifier{Host: "smtp.example.com", Port: 25, From: "a@example.com", To: []string{"b@example.com"}},
+	})
+
+	require.NotNil(t, dispatcher)
+	names := make([]string, len(dispatcher.subscriptions))
+	for i, s := range dispatcher.subscriptions {
+		names[i] = s.notifier.String()
+	}
+	assert.Equal(t, []string{"webhook https://example.com", "gotify https://gotify.example.com", "smtp smtp.example.com:25"}, names)
+}
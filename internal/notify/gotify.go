@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"net/http"
+	"net/url"
+)
+
+const (
+	gotifyPriorityFailure = 8
+	gotifyPriorityDefault = 4
+)
+
+// gotify posts the message to a Gotify server with an application token.
+type gotify struct {
+	url   string
+	token string
+}
+
+func (g *gotify) Notify(event Event) error {
+	token, err := model.ResolveSecret(g.token)
+	if err != nil {
+		return fmt.Errorf("resolve token: %w", err)
+	}
+
+	target, err := url.JoinPath(g.url, "message")
+	if err != nil {
+		return err
+	}
+
+	priority := gotifyPriorityDefault
+	if event.Event == EventFailure {
+		priority = gotifyPriorityFailure
+	}
+
+	header := http.Header{}
+	header.Set("X-Gotify-Key", token)
+	return postJSON(target, header, map[string]interface{}{
+		"title":    event.Title,
+		"message":  event.Message,
+		"priority": priority,
+	})
+}
+
+func (g *gotify) String() string {
+	return "gotify " + redactURL(g.url)
+}
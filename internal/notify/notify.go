@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/lovelaze/nebula-sync/internal/sync"
+	"github.com/lovelaze/nebula-sync/version"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"slices"
+	"strings"
+	gosync "sync"
+	"time"
+)
+
+const notifyTimeout = 10 * time.Second
+
+var (
+	userAgent  = fmt.Sprintf("nebula-sync/%s", version.Version)
+	httpClient = &http.Client{Timeout: notifyTimeout}
+)
+
+const (
+	EventFailure  = "failure"
+	EventRecovery = "recovery"
+	EventSuccess  = "success"
+)
+
+// Event describes the outcome of a sync.
+type Event struct {
+	Event    string         `json:"event"`
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Time     time.Time      `json:"time"`
+	Mode     string         `json:"mode"`
+	Error    string         `json:"error,omitempty"`
+	Phase    sync.Phase     `json:"phase,omitempty"`
+	Replicas []ReplicaEvent `json:"replicas,omitempty"`
+}
+
+type ReplicaEvent struct {
+	Replica string        `json:"replica"`
+	Success bool          `json:"success"`
+	Phase   sync.Phase    `json:"phase,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Actions []ActionEvent `json:"actions,omitempty"`
+}
+
+// ActionEvent is the outcome of a post-sync action on a replica.
+type ActionEvent struct {
+	Action  string `json:"action"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Notifier sends an event to a notification backend.
+type Notifier interface {
+	Notify(event Event) error
+	String() string
+}
+
+type subscription struct {
+	notifier Notifier
+	on       []string
+}
+
+func (s subscription) wants(event string) bool {
+	return slices.Contains(s.on, config.NotifyAlways) ||
+		(event == EventFailure && slices.Contains(s.on, config.NotifyFailure)) ||
+		(event == EventRecovery && slices.Contains(s.on, config.NotifyRecovery))
+}
+
+// Dispatcher sends the outcome of syncs to the notifiers that want it, a nil Dispatcher does nothing. Notifications are
+// sent in the background, in the order the syncs were done, so that a slow notifier does not delay the next sync.
+type Dispatcher struct {
+	mu            gosync.Mutex
+	subscriptions []subscription
+	failed        bool
+	// sent is closed once the notifications of the last sync were sent.
+	sent chan struct{}
+}
+
+// NewDispatcher returns a dispatcher with the configured notifiers, or nil if none are configured.
+func NewDispatcher(conf config.Notify) *Dispatcher {
+	dispatcher := &Dispatcher{}
+	if conf.Webhook.URL != "" {
+		dispatcher.Add(&webhook{url: conf.Webhook.URL}, conf.Webhook.On)
+	}
+	if conf.Slack.URL != "" {
+		dispatcher.Add(&slack{url: conf.Slack.URL}, conf.Slack.On)
+	}
+	if conf.Ntfy.URL != "" {
+		dispatcher.Add(&ntfy{url: conf.Ntfy.URL, token: conf.Ntfy.Token}, conf.Ntfy.On)
+	}
+	if conf.Gotify.URL != "" {
+		dispatcher.Add(&gotify{url: conf.Gotify.URL, token: conf.Gotify.Token}, conf.Gotify.On)
+	}
+	if conf.SMTP.Host != "" {
+		dispatcher.Add(newSMTP(conf.SMTP), conf.SMTP.On)
+	}
+
+	if len(dispatcher.subscriptions) == 0 {
+		return nil
+	}
+	return dispatcher
+}
+
+// Add sends the events in on, see config.NotifyFailure, config.NotifyRecovery and config.NotifyAlways, to the notifier.
+func (d *Dispatcher) Add(notifier Notifier, on []string) {
+	d.subscriptions = append(d.subscriptions, subscription{notifier: notifier, on: on})
+}
+
+// SyncDone notifies about a sync in mode full or manual that ended with the result and error.
+func (d *Dispatcher) SyncDone(mode string, result *sync.SyncResult, err error) {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	event := newEvent(mode, result, err, d.failed)
+	d.failed = err != nil
+	previous, sent := d.sent, make(chan struct{})
+	d.sent = sent
+	d.mu.Unlock()
+
+	go func() {
+		defer close(sent)
+		if previous != nil {
+			<-previous
+		}
+		d.send(event)
+	}()
+}
+
+// Wait waits until the notifications of all syncs that are done were sent.
+func (d *Dispatcher) Wait() {
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	sent := d.sent
+	d.mu.Unlock()
+	if sent != nil {
+		<-sent
+	}
+}
+
+func (d *Dispatcher) send(event Event) {
+	for _, s := range d.subscriptions {
+		if !s.wants(event.Event) {
+			continue
+		}
+		if err := s.notifier.Notify(event); err != nil {
+			log.Warn().Err(err).Str("notifier", s.notifier.String()).Msg("Failed to send notification")
+		} else {
+			log.Debug().Str("notifier", s.notifier.String()).Str("event", event.Event).Msg("Notification sent")
+		}
+	}
+}
+
+func newEvent(mode string, result *sync.SyncResult, err error, failed bool) Event {
+	event := Event{
+		Event: EventSuccess,
+		Time:  time.Now(),
+		Mode:  mode,
+	}
+
+	switch {
+	case err != nil:
+		event.Event = EventFailure
+		event.Error = err.Error()
+	case failed:
+		event.Event = EventRecovery
+	}
+
+	if result != nil {
+		for _, replica := range result.Replicas {
+			replicaEvent := ReplicaEvent{Replica: replica.Replica, Success: replica.Success(), Phase: replica.Phase()}
+			if replica.Err != nil {
+				replicaEvent.Error = replica.Err.Error()
+			}
+			for _, action := range replica.Actions {
+				actionEvent := ActionEvent{Action: action.Action, Success: action.Success()}
+				if action.Err != nil {
+					actionEvent.Error = action.Err.Error()
+				}
+				replicaEvent.Actions = append(replicaEvent.Actions, actionEvent)
+			}
+			event.Replicas = append(event.Replicas, replicaEvent)
+		}
+	}
+	if result == nil || len(result.Failed()) == 0 {
+		event.Phase = sync.ErrorPhase(err)
+	}
+
+	event.Title = title(event)
+	event.Message = message(event)
+	return event
+}
+
+func title(event Event) string {
+	switch event.Event {
+	case EventFailure:
+		return "nebula-sync: sync failed"
+	case EventRecovery:
+		return "nebula-sync: sync recovered"
+	default:
+		return "nebula-sync: sync succeeded"
+	}
+}
+
+func message(event Event) string {
+	var sb strings.Builder
+	switch event.Event {
+	case EventFailure:
+		if event.Phase != "" {
+			fmt.Fprintf(&sb, "%s sync failed at %s: %s\n", event.Mode, event.Phase, event.Error)
+		} else {
+			fmt.Fprintf(&sb, "%s sync failed: %s\n", event.Mode, event.Error)
+		}
+	case EventRecovery:
+		fmt.Fprintf(&sb, "%s sync succeeded after a failed sync\n", event.Mode)
+	default:
+		fmt.Fprintf(&sb, "%s sync succeeded\n", event.Mode)
+	}
+
+	for _, replica := range event.Replicas {
+		switch {
+		case replica.Success:
+			fmt.Fprintf(&sb, "- %s: ok%s\n", replica.Replica, actionsMessage(replica.Actions))
+		case replica.Phase != "":
+			fmt.Fprintf(&sb, "- %s: failed at %s: %s\n", replica.Replica, replica.Phase, replica.Error)
+		default:
+			fmt.Fprintf(&sb, "- %s: failed: %s\n", replica.Replica, replica.Error)
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// actionsMessage summarizes the post-sync actions of a replica, e.g. ", gravity ok, restart_dns failed: timeout".
+func actionsMessage(actions []ActionEvent) string {
+	var sb strings.Builder
+	for _, action := range actions {
+		if action.Success {
+			fmt.Fprintf(&sb, ", %s ok", action.Action)
+		} else {
+			fmt.Fprintf(&sb, ", %s failed: %s", action.Action, action.Error)
+		}
+	}
+	return sb.String()
+}
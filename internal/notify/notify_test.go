@@ -0,0 +1,127 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/lovelaze/nebula-sync/internal/sync"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) Notify(event Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recorder) String() string {
+	return "recorder"
+}
+
+func (r *recorder) kinds() []string {
+	kinds := make([]string, len(r.events))
+	for i, event := range r.events {
+		kinds[i] = event.Event
+	}
+	return kinds
+}
+
+func TestDispatcher_SyncDone(t *testing.T) {
+	failures, recoveries, always, defaults := &recorder{}, &recorder{}, &recorder{}, &recorder{}
+	dispatcher := &Dispatcher{}
+	dispatcher.Add(failures, []string{config.NotifyFailure})
+	dispatcher.Add(recoveries, []string{config.NotifyRecovery})
+	dispatcher.Add(always, []string{config.NotifyAlways})
+	dispatcher.Add(defaults, []string{config.NotifyFailure, config.NotifyRecovery})
+
+	dispatcher.SyncDone("full", &sync.SyncResult{}, nil)
+	dispatcher.SyncDone("full", nil, errors.New("primary unreachable"))
+	dispatcher.SyncDone("full", nil, errors.New("primary unreachable"))
+	dispatcher.SyncDone("full", &sync.SyncResult{}, nil)
+	dispatcher.SyncDone("full", &sync.SyncResult{}, nil)
+	dispatcher.Wait()
+
+	assert.Equal(t, []string{EventFailure, EventFailure}, failures.kinds())
+	assert.Equal(t, []string{EventRecovery}, recoveries.kinds())
+	assert.Equal(t, []string{EventSuccess, EventFailure, EventFailure, EventRecovery, EventSuccess}, always.kinds())
+	assert.Equal(t, []string{EventFailure, EventFailure, EventRecovery}, defaults.kinds())
+}
+
+// blocking records events once release is closed.
+type blocking struct {
+	recorder
+	release chan struct{}
+}
+
+func (b *blocking) Notify(event Event) error {
+	<-b.release
+	return b.recorder.Notify(event)
+}
+
+func TestDispatcher_SyncDone_background(t *testing.T) {
+	notifier := &blocking{release: make(chan struct{})}
+	dispatcher := &Dispatcher{}
+	dispatcher.Add(notifier, []string{config.NotifyAlways})
+
+	// SyncDone returns while the notifier blocks, and the events are sent in order once it is released.
+	dispatcher.SyncDone("full", nil, errors.New("primary unreachable"))
+	dispatcher.SyncDone("full", &sync.SyncResult{}, nil)
+	dispatcher.SyncDone("full", &sync.SyncResult{}, nil)
+	close(notifier.release)
+	dispatcher.Wait()
+
+	assert.Equal(t, []string{EventFailure, EventRecovery, EventSuccess}, notifier.kinds())
+}
+
+func TestDispatcher_nil(t *testing.T) {
+	dispatcher := NewDispatcher(config.Notify{})
+	assert.Nil(t, dispatcher)
+
+	dispatcher.SyncDone("full", nil, errors.New("primary unreachable"))
+	dispatcher.Wait()
+}
+
+func Test_newEvent_replicaFailure(t *testing.T) {
+	result := &sync.SyncResult{Replicas: []sync.ReplicaResult{
+		{Replica: "http://ph2.example.com", Err: &sync.PhaseError{Phase: sync.PhaseTeleporter, Err: errors.New("sync teleporter: unexpected status code: 500")}},
+		{Replica: "http://ph3.example.com", Actions: []sync.ActionResult{{Action: sync.ActionGravity}, {Action: sync.ActionRestartDNS, Err: errors.New("timeout")}}},
+		{Replica: "http://ph4.example.com", Err: errors.New("connection refused")},
+	}}
+
+	event := newEvent("manual", result, fmt.Errorf("2 of 3 replicas failed: %w", result.Err()), false)
+
+	assert.Equal(t, EventFailure, event.Event)
+	assert.Equal(t, "nebula-sync: sync failed", event.Title)
+	assert.Equal(t, sync.Phase(""), event.Phase)
+	assert.Equal(t, []ReplicaEvent{
+		{Replica: "http://ph2.example.com", Success: false, Phase: sync.PhaseTeleporter, Error: "sync teleporter: unexpected status code: 500"},
+		{Replica: "http://ph3.example.com", Success: true, Actions: []ActionEvent{
+			{Action: "gravity", Success: true},
+			{Action: "restart_dns", Success: false, Error: "timeout"},
+		}},
+		{Replica: "http://ph4.example.com", Success: false, Error: "connection refused"},
+	}, event.Replicas)
+	assert.Contains(t, event.Message, "manual sync failed: 2 of 3 replicas failed")
+	assert.Contains(t, event.Message, "- http://ph2.example.com: failed at teleporter: sync teleporter: unexpected status code: 500\n- http://ph3.example.com: ok, gravity ok, restart_dns failed: timeout\n- http://ph4.example.com: failed: connection refused")
+}
+
+func Test_newEvent_primaryFailure(t *testing.T) {
+	err := &sync.PhaseError{Phase: sync.PhaseAuth, Err: errors.New("authenticate: invalid password")}
+
+	event := newEvent("full", nil, err, false)
+
+	assert.Equal(t, sync.PhaseAuth, event.Phase)
+	assert.Equal(t, "full sync failed at auth: authenticate: invalid password", event.Message)
+}
+
+func Test_newEvent_recovery(t *testing.T) {
+	event := newEvent("full", &sync.SyncResult{Replicas: []sync.ReplicaResult{{Replica: "http://ph2.example.com"}}}, nil, true)
+
+	assert.Equal(t, EventRecovery, event.Event)
+	assert.Equal(t, "nebula-sync: sync recovered", event.Title)
+	assert.Equal(t, "full sync succeeded after a failed sync\n- http://ph2.example.com: ok", event.Message)
+}
@@ -0,0 +1,43 @@
+package notify
+
+import (
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"net/http"
+	"strings"
+)
+
+// ntfy publishes the message to a ntfy topic url.
+type ntfy struct {
+	url   string
+	token string
+}
+
+func (n *ntfy) Notify(event Event) error {
+	req, err := http.NewRequest(http.MethodPost, n.url, strings.NewReader(event.Message))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Title", event.Title)
+	if event.Event == EventFailure {
+		req.Header.Set("Priority", "high")
+		req.Header.Set("Tags", "warning")
+	} else {
+		req.Header.Set("Tags", "white_check_mark")
+	}
+
+	if n.token != "" {
+		token, err := model.ResolveSecret(n.token)
+		if err != nil {
+			return fmt.Errorf("resolve token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return send(req)
+}
+
+func (n *ntfy) String() string {
+	return "ntfy " + redactURL(n.url)
+}
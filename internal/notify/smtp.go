@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// smtpsPort is the port of SMTP over implicit TLS, STARTTLS is not used on it.
+const smtpsPort = 465
+
+// email sends the message by SMTP, over implicit TLS on smtpsPort and with STARTTLS otherwise, if the server supports it.
+type email struct {
+	addr        string
+	host        string
+	username    string
+	password    string
+	from        string
+	to          []string
+	implicitTLS bool
+	tlsConfig   *tls.Config
+	sendMail    func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func newSMTP(conf config.SMTPNotifier) *email {
+	e := &email{
+		addr:        net.JoinHostPort(conf.Host, strconv.Itoa(conf.Port)),
+		host:        conf.Host,
+		username:    conf.Username,
+		password:    conf.Password,
+		from:        conf.From,
+		to:          conf.To,
+		implicitTLS: conf.Port == smtpsPort,
+		tlsConfig:   &tls.Config{ServerName: conf.Host},
+	}
+	e.sendMail = e.send
+	return e
+}
+
+func (e *email) Notify(event Event) error {
+	var auth smtp.Auth
+	if e.username != "" {
+		password, err := model.ResolveSecret(e.password)
+		if err != nil {
+			return fmt.Errorf("resolve password: %w", err)
+		}
+		auth = smtp.PlainAuth("", e.username, password, e.host)
+	}
+
+	return e.sendMail(e.addr, auth, e.from, e.to, e.message(event))
+}
+
+// send is smtp.SendMail with implicit TLS and notifyTimeout, so that an unresponsive server does not block notifications.
+func (e *email) send(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	dialer := &net.Dialer{Timeout: notifyTimeout}
+	var conn net.Conn
+	var err error
+	if e.implicitTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, e.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(notifyTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !e.implicitTLS {
+		if err := client.StartTLS(e.tlsConfig); err != nil {
+			return err
+		}
+	}
+	if a != nil {
+		if err := client.Auth(a); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (e *email) message(event Event) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\r\n", e.from)
+	fmt.Fprintf(&sb, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&sb, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", event.Title))
+	fmt.Fprintf(&sb, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(strings.ReplaceAll(event.Message, "\n", "\r\n"))
+	sb.WriteString("\r\n")
+	return []byte(sb.String())
+}
+
+func (e *email) String() string {
+	return "smtp " + e.addr
+}
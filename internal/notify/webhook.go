@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// webhook posts the event as JSON.
+type webhook struct {
+	url string
+}
+
+func (w *webhook) Notify(event Event) error {
+	return postJSON(w.url, nil, event)
+}
+
+func (w *webhook) String() string {
+	return "webhook " + redactURL(w.url)
+}
+
+// slack posts the message to a Slack compatible incoming webhook, such as a Discord webhook url ending in /slack.
+type slack struct {
+	url string
+}
+
+func (s *slack) Notify(event Event) error {
+	return postJSON(s.url, nil, map[string]string{
+		"text": fmt.Sprintf("*%s*\n%s", event.Title, event.Message),
+	})
+}
+
+func (s *slack) String() string {
+	return "slack " + redactURL(s.url)
+}
+
+func postJSON(target string, header http.Header, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return send(req)
+}
+
+func send(req *http.Request) error {
+	req.Header.Set("User-Agent", userAgent)
+
+	response, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("%s: unexpected status code: %d", redactURL(req.URL.String()), response.StatusCode)
+	}
+	return nil
+}
+
+// redactURL returns the scheme and host of a url, webhook urls often contain secrets in the path.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid url"
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,102 @@
+package pihole
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// terminalEscape matches the color and cursor escape sequences in the output of pihole -g.
+var terminalEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// RunGravity rebuilds gravity like pihole -g, output is called with every line of its output as Pi-hole streams it.
+// The run is limited by ctx only, as it downloads every adlist.
+func (client *client) RunGravity(ctx context.Context, output func(line string)) error {
+	client.logger.Debug().Msg("Run gravity")
+	req, err := client.newRequest(ctx, "POST", "action/gravity", nil)
+	if err != nil {
+		return client.wrapError(err, req)
+	}
+
+	response, err := client.stream(req)
+	if err != nil {
+		return client.wrapError(err, req)
+	}
+	defer response.Body.Close()
+
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		if line := outputLine(scanner.Text()); line != "" {
+			output(line)
+		}
+	}
+	return client.wrapError(scanner.Err(), req)
+}
+
+func (client *client) RestartDNS(ctx context.Context) error {
+	client.logger.Debug().Msg("Restart DNS")
+	// FTL restarts after it responded, a restart is not repeated unless it did not reach Pi-hole.
+	return client.requestJSON(ctx, "POST", "action/restartdns", nil, nil, nil, retryUnsent)
+}
+
+// FlushNetwork empties the network table, the devices Pi-hole has seen.
+func (client *client) FlushNetwork(ctx context.Context) error {
+	client.logger.Debug().Msg("Flush network table")
+	return client.requestJSON(ctx, "POST", "action/flush/network", nil, nil, nil, retryIdempotent)
+}
+
+// FlushLogs empties the DNS query log of the last 24 hours.
+func (client *client) FlushLogs(ctx context.Context) error {
+	client.logger.Debug().Msg("Flush logs")
+	return client.requestJSON(ctx, "POST", "action/flush/logs", nil, nil, nil, retryIdempotent)
+}
+
+// stream sends req with the session of the client like send, and returns the response to read the body as it arrives.
+// The request is not retried, other than once with a new session if Pi-hole rejects the session.
+func (client *client) stream(req *http.Request) (*http.Response, error) {
+	sid, err := client.session(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := client.streamAttempt(req, sid)
+	if isUnauthorized(err) {
+		if sid, err = client.reauthenticate(req.Context(), sid); err != nil {
+			return nil, err
+		}
+		response, err = client.streamAttempt(req, sid)
+	}
+
+	if err == nil {
+		client.used(sid)
+	}
+	return response, err
+}
+
+func (client *client) streamAttempt(req *http.Request, sid string) (*http.Response, error) {
+	req.Header.Set("sid", sid)
+	response, err := client.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return nil, client.responseError(req, response, body)
+	}
+	return response, nil
+}
+
+// outputLine removes escape sequences from a line of terminal output, and keeps only the text after the last
+// carriage return, which the terminal would have overwritten.
+func outputLine(line string) string {
+	line = terminalEscape.ReplaceAllString(line, "")
+	if i := strings.LastIndex(strings.TrimRight(line, "\r"), "\r"); i >= 0 {
+		line = line[i+1:]
+	}
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,59 @@
+package pihole
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_RunGravity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/action/gravity", r.URL.Path)
+		assert.Equal(t, "sid", r.Header.Get("sid"))
+		_, _ = w.Write([]byte("  [i] Neutrino emissions detected...\n"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("  [i] Pulling blocklist source list into range...\x1b[K\r  [\x1b[32m✓\x1b[0m] Pulling blocklist source list into range\n\n  [✓] Done.\n"))
+	}))
+	t.Cleanup(server.Close)
+	c, _ := newRetryClient(t, server.URL, 1)
+
+	var lines []string
+	err := c.RunGravity(context.Background(), func(line string) { lines = append(lines, line) })
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"[i] Neutrino emissions detected...",
+		"[✓] Pulling blocklist source list into range",
+		"[✓] Done.",
+	}, lines)
+}
+
+func TestClient_RunGravity_error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": {"key": "forbidden", "message": "Unable to change configuration (read-only)", "hint": null}}`))
+	}))
+	t.Cleanup(server.Close)
+	c, _ := newRetryClient(t, server.URL, 1)
+
+	err := c.RunGravity(context.Background(), func(line string) { t.Errorf("unexpected output %q", line) })
+	assert.ErrorContains(t, err, "unexpected status code: 403 forbidden")
+}
+
+func TestClient_actions(t *testing.T) {
+	c, requests := newGravityClient(t, `{"status": "success"}`)
+	ctx := context.Background()
+
+	require.NoError(t, c.RestartDNS(ctx))
+	require.NoError(t, c.FlushNetwork(ctx))
+	require.NoError(t, c.FlushLogs(ctx))
+
+	assert.Equal(t, []gravityRequest{
+		{"POST", "/api/action/restartdns", ""},
+		{"POST", "/api/action/flush/network", ""},
+		{"POST", "/api/action/flush/logs", ""},
+	}, *requests)
+}
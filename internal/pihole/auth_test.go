@@ -0,0 +1,86 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newAuthClient(t *testing.T, piHole model.PiHole, handler http.HandlerFunc) *client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	piHole.Url = model.NewPiHole(server.URL, "").Url
+	newClient, err := NewClient(piHole, Options{})
+	require.NoError(t, err)
+	return newClient.(*client)
+}
+
+func TestClient_Authenticate_totp(t *testing.T) {
+	var request model.AuthRequest
+	piHole := model.NewPiHole("http://ph1.example.com", apiPassword)
+	piHole.TotpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+	c := newAuthClient(t, piHole, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		_, _ = w.Write([]byte(`{"session": {"valid": true, "totp": true, "sid": "sid", "validity": 1800, "message": "password correct"}}`))
+	})
+	c.now = func() time.Time { return time.Unix(59, 0) }
+
+	require.NoError(t, c.Authenticate(context.Background()))
+	assert.Equal(t, model.AuthRequest{Password: apiPassword, Totp: 287082}, request)
+	assert.Equal(t, "sid", c.auth.sid)
+}
+
+func TestClient_Authenticate_message(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		want     string
+	}{
+		{
+			name:     "wrong password",
+			status:   http.StatusUnauthorized,
+			response: `{"session": {"valid": false, "totp": false, "sid": null, "validity": -1, "message": "password incorrect"}}`,
+			want:     "authentication failed: unexpected status code: 401: password incorrect",
+		},
+		{
+			name:     "totp required",
+			status:   http.StatusUnauthorized,
+			response: `{"session": {"valid": false, "totp": true, "sid": null, "validity": -1, "message": "no 2FA token found"}}`,
+			want:     "no 2FA token found (2FA is enabled: set totp_secret or use an app password)",
+		},
+		{
+			name:     "invalid session",
+			status:   http.StatusOK,
+			response: `{"session": {"valid": false, "sid": null}}`,
+			want:     "authentication failed: no valid session",
+		},
+		{
+			name:   "no body",
+			status: http.StatusTooManyRequests,
+			want:   "unexpected status code: 429",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newAuthClient(t, model.NewPiHole("http://ph1.example.com", apiPassword), func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			})
+
+			err := c.Authenticate(context.Background())
+			assert.ErrorContains(t, err, tt.want)
+			assert.ErrorContains(t, c.auth.verify(), "invalid sid found")
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package pihole
+
+import (
+	"context"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+)
+
+func (client *client) GetBlocking(ctx context.Context) (*model.Blocking, error) {
+	client.logger.Debug().Msg("Get blocking")
+	response := model.Blocking{}
+	if err := client.requestJSON(ctx, "GET", "dns/blocking", nil, nil, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// SetBlocking changes the blocking state. It is retried only if the request did not reach Pi-hole, because a resent
+// request would restart the timer.
+func (client *client) SetBlocking(ctx context.Context, blocking *model.BlockingRequest) (*model.Blocking, error) {
+	client.logger.Debug().Any("payload", blocking).Msg("Set blocking")
+	response := model.Blocking{}
+	if err := client.requestJSON(ctx, "POST", "dns/blocking", nil, blocking, &response, retryUnsent); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,48 @@
+package pihole
+
+import (
+	"context"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_blocking(t *testing.T) {
+	c, requests := newGravityClient(t, `{"blocking": "disabled", "timer": 287.5, "took": 0.001}`)
+	ctx := context.Background()
+
+	blocking, err := c.GetBlocking(ctx)
+	require.NoError(t, err)
+	timer := 287.5
+	assert.Equal(t, model.Blocking{Blocking: model.BlockingDisabled, Timer: &timer}, *blocking)
+
+	_, err = c.SetBlocking(ctx, &model.BlockingRequest{Blocking: false, Timer: &timer})
+	require.NoError(t, err)
+	_, err = c.SetBlocking(ctx, &model.BlockingRequest{Blocking: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, []gravityRequest{
+		{"GET", "/api/dns/blocking", ""},
+		{"POST", "/api/dns/blocking", `{"blocking":false,"timer":287.5}`},
+		{"POST", "/api/dns/blocking", `{"blocking":true,"timer":null}`},
+	}, *requests)
+}
+
+func TestClient_SetBlocking_noRetry(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c, _ := newRetryClient(t, server.URL, 3)
+	timer := 60.0
+	_, err := c.SetBlocking(context.Background(), &model.BlockingRequest{Timer: &timer})
+
+	assert.ErrorContains(t, err, "unexpected status code: 503")
+	assert.Equal(t, 1, requests)
+}
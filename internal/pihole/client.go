@@ -2,6 +2,9 @@ package pihole
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,90 +14,214 @@ import (
 	"github.com/rs/zerolog/log"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"sync"
 	"time"
 )
 
-var (
-	userAgent  = fmt.Sprintf("nebula-sync/%s", version.Version)
-	httpClient = &http.Client{Timeout: 5 * time.Second}
-)
+var userAgent = fmt.Sprintf("nebula-sync/%s", version.Version)
+
+type Options struct {
+	Retry   RetryOptions
+	Session SessionOptions
+	// Transport wraps the transport of the http client if set, e.g. to instrument every request sent to Pi-hole.
+	Transport func(http.RoundTripper) http.RoundTripper
+}
 
-func NewClient(piHole model.PiHole) Client {
+// NewClient creates a client for piHole, with the timeouts of piHole.Timeouts and the TLS settings of piHole.TLS.
+func NewClient(piHole model.PiHole, options Options) (Client, error) {
 	logger := log.With().Str("client", piHole.Url.String()).Logger()
-	return &client{
-		piHole: piHole,
-		logger: &logger,
+
+	httpClient, err := newHttpClient(piHole, options.Transport)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", piHole.Url, err)
+	}
+	if piHole.TLS.InsecureSkipVerify {
+		logger.Warn().Msg("TLS certificate verification is DISABLED, the connection is open to man-in-the-middle attacks")
 	}
+
+	return &client{
+		piHole:   piHole,
+		logger:   &logger,
+		http:     httpClient,
+		timeouts: piHole.Timeouts,
+		retry:    options.Retry,
+		sessions: options.Session,
+		sleep:    sleep,
+		now:      time.Now,
+	}, nil
 }
 
 type Client interface {
-	Authenticate() error
-	DeleteSession() error
-	GetVersion() (*model.VersionResponse, error)
-	GetTeleporter() ([]byte, error)
-	PostTeleporter(payload []byte, teleporterRequest *model.PostTeleporterRequest) error
-	GetConfig() (configResponse *model.ConfigResponse, err error)
-	PatchConfig(patchRequest *model.PatchConfigRequest) error
+	Authenticate(ctx context.Context) error
+	DeleteSession(ctx context.Context) error
+	Close(ctx context.Context) error
+	GetVersion(ctx context.Context) (*model.VersionResponse, error)
+	GetTeleporter(ctx context.Context) ([]byte, error)
+	PostTeleporter(ctx context.Context, payload []byte, teleporterRequest *model.PostTeleporterRequest) error
+	GetConfig(ctx context.Context) (configResponse *model.ConfigResponse, err error)
+	PatchConfig(ctx context.Context, patchRequest *model.PatchConfigRequest) error
+	GetGroups(ctx context.Context) (*model.GroupsResponse, error)
+	GetLists(ctx context.Context) (*model.ListsResponse, error)
+	GetDomains(ctx context.Context) (*model.DomainsResponse, error)
+	GetClients(ctx context.Context) (*model.ClientsResponse, error)
+	GetGroup(ctx context.Context, name string) (*model.Group, error)
+	CreateGroup(ctx context.Context, group *model.GroupRequest) (*model.Group, error)
+	UpdateGroup(ctx context.Context, name string, group *model.GroupRequest) (*model.Group, error)
+	DeleteGroup(ctx context.Context, name string) error
+	DeleteGroups(ctx context.Context, names []string) error
+	GetList(ctx context.Context, key model.ListKey) (*model.List, error)
+	CreateList(ctx context.Context, list *model.ListRequest) (*model.List, error)
+	UpdateList(ctx context.Context, key model.ListKey, list *model.ListRequest) (*model.List, error)
+	DeleteList(ctx context.Context, key model.ListKey) error
+	DeleteLists(ctx context.Context, keys []model.ListKey) error
+	GetDomainsOf(ctx context.Context, domainType, kind string) (*model.DomainsResponse, error)
+	GetDomain(ctx context.Context, key model.DomainKey) (*model.Domain, error)
+	CreateDomain(ctx context.Context, domain *model.DomainRequest) (*model.Domain, error)
+	UpdateDomain(ctx context.Context, key model.DomainKey, domain *model.DomainRequest) (*model.Domain, error)
+	DeleteDomain(ctx context.Context, key model.DomainKey) error
+	DeleteDomains(ctx context.Context, keys []model.DomainKey) error
+	GetClient(ctx context.Context, id string) (*model.Client, error)
+	CreateClient(ctx context.Context, client *model.ClientRequest) (*model.Client, error)
+	UpdateClient(ctx context.Context, id string, client *model.ClientRequest) (*model.Client, error)
+	DeleteClient(ctx context.Context, id string) error
+	DeleteClients(ctx context.Context, ids []string) error
+	RunGravity(ctx context.Context, output func(line string)) error
+	RestartDNS(ctx context.Context) error
+	FlushNetwork(ctx context.Context) error
+	FlushLogs(ctx context.Context) error
+	GetBlocking(ctx context.Context) (*model.Blocking, error)
+	SetBlocking(ctx context.Context, blocking *model.BlockingRequest) (*model.Blocking, error)
+	PiHole() model.PiHole
 	String() string
 	ApiPath(target string) string
 }
 
 type client struct {
 	piHole model.PiHole
-	auth   auth
-	logger *zerolog.Logger
+	// mu guards auth, so that the client can be used by concurrent syncs.
+	mu       sync.Mutex
+	auth     auth
+	logger   *zerolog.Logger
+	http     *http.Client
+	timeouts model.Timeouts
+	retry    RetryOptions
+	sessions SessionOptions
+	sleep    func(ctx context.Context, d time.Duration) error
+	now      func() time.Time
 }
 
-type auth struct {
-	sid      string
-	csrf     string
-	validity int
-	valid    bool
+// newHttpClient creates an http client with the TLS settings of piHole, that limits connecting to the connect timeout.
+// Requests are limited by their context. The transport is wrapped by wrap, if not nil.
+func newHttpClient(piHole model.PiHole, wrap func(http.RoundTripper) http.RoundTripper) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(piHole.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: piHole.Timeouts.Connect, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = piHole.Timeouts.Connect
+	transport.TLSClientConfig = tlsConfig
+	if wrap != nil {
+		return &http.Client{Transport: wrap(transport)}, nil
+	}
+	return &http.Client{Transport: transport}, nil
 }
 
-func (a *auth) verify() error {
-	if !a.valid {
-		return errors.New("invalid sid found")
+func newTLSConfig(options model.TLS) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         options.ServerName,
+		InsecureSkipVerify: options.InsecureSkipVerify,
 	}
 
-	return nil
+	if options.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca_file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_file %s: no PEM certificates found", options.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
 }
 
-func (client *client) Authenticate() error {
+// Authenticate logs in, or reuses the current or persisted session if sessions are reused and it does not expire soon.
+func (client *client) Authenticate(ctx context.Context) error {
 	client.logger.Debug().Msg("Authenticate")
-	authResponse := model.AuthResponse{}
+	client.mu.Lock()
+	defer client.mu.Unlock()
 
-	reqBytes, err := json.Marshal(model.AuthRequest{Password: client.piHole.Password})
-	if err != nil {
-		return client.wrapError(err, nil)
+	if client.sessions.Reuse {
+		if client.auth.usable(client.now()) {
+			client.logger.Debug().Msg("Reusing session")
+			return nil
+		}
+		if !client.auth.valid && client.restoreSession(ctx) {
+			return nil
+		}
+		return client.renew(ctx)
 	}
+	return client.login(ctx)
+}
 
-	req, err := http.NewRequest("POST", client.ApiPath("/auth"), bytes.NewReader(reqBytes))
+// login creates a new session, the caller must hold mu.
+func (client *client) login(ctx context.Context) error {
+	authResponse := model.AuthResponse{}
 
+	password, err := client.piHole.ResolvePassword()
 	if err != nil {
-		return client.wrapError(err, req)
+		return client.wrapError(fmt.Errorf("resolve password: %w", err), nil)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", userAgent)
+	authRequest := model.AuthRequest{Password: password}
+	if err := client.totp(&authRequest); err != nil {
+		return client.wrapError(err, nil)
+	}
 
-	response, err := httpClient.Do(req)
+	reqBytes, err := json.Marshal(authRequest)
 	if err != nil {
-		return client.wrapError(err, req)
+		return client.wrapError(err, nil)
 	}
 
-	if err := successfulHttpStatus(response.StatusCode); err != nil {
+	req, err := client.newRequest(ctx, "POST", "auth", bytes.NewReader(reqBytes))
+	if err != nil {
 		return client.wrapError(err, req)
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
+	// Authentication is retried only if it did not reach Pi-hole, a retried login could create a second session, and
+	// retrying a rate-limited login only extends the lockout.
+	body, err := client.do(req, retryUnsent, client.timeouts.Request)
+	var apiErr *APIError
+	if err != nil && !errors.As(err, &apiErr) {
 		return client.wrapError(err, req)
 	}
 
-	if err = json.Unmarshal(body, &authResponse); err != nil {
-		return client.wrapError(err, req)
+	// Pi-hole explains a failed login in the message of the response, which is more useful than the status code.
+	if jsonErr := json.Unmarshal(body, &authResponse); jsonErr != nil {
+		if err != nil {
+			return client.wrapError(err, req)
+		}
+		return client.wrapError(jsonErr, req)
 	}
 
 	client.auth = auth{
@@ -104,105 +231,134 @@ func (client *client) Authenticate() error {
 		valid:    authResponse.Session.Valid,
 	}
 
-	return client.auth.verify()
-}
+	if err != nil || !client.auth.valid {
+		client.auth.valid = false
+		client.removeSession()
+		return client.wrapError(client.authError(authResponse, err), req)
+	}
+	client.auth.touch(client.now())
+	client.saveSession()
 
-func (client *client) DeleteSession() error {
-	client.logger.Debug().Msg("Delete session")
-	if err := client.auth.verify(); err != nil {
-		return client.wrapError(err, nil)
+	if client.piHole.AppPassword && authResponse.Session.Message != "app-password correct" {
+		client.logger.Warn().Str("message", authResponse.Session.Message).Msg("Authenticated, but not with an app password")
 	}
+	return nil
+}
 
-	if client.auth.sid == "" {
+// totp adds the current TOTP code to authRequest if the Pi-hole has a TOTP secret.
+func (client *client) totp(authRequest *model.AuthRequest) error {
+	secret, err := client.piHole.ResolveTotpSecret()
+	if err != nil {
+		return fmt.Errorf("resolve totp_secret: %w", err)
+	}
+	if secret == "" {
 		return nil
 	}
 
-	req, err := http.NewRequest("DELETE", client.ApiPath("auth"), nil)
+	key, err := model.DecodeTotpSecret(secret)
 	if err != nil {
-		return client.wrapError(err, req)
+		return fmt.Errorf("totp_secret: %w", err)
 	}
+	authRequest.Totp = totpCode(key, client.now())
+	return nil
+}
 
-	req.Header.Set("sid", client.auth.sid)
-	req.Header.Set("User-Agent", userAgent)
-
-	response, err := httpClient.Do(req)
-
-	if err != nil {
-		return client.wrapError(err, req)
+// authError describes a failed login with the message of authResponse. If the response was unsuccessful,
+// the message is added to the *APIError err, as Pi-hole sends it in the session instead of an error body.
+func (client *client) authError(authResponse model.AuthResponse, err error) error {
+	message := authResponse.Session.Message
+	var hint string
+	if authResponse.Session.Totp && client.piHole.TotpSecret == "" {
+		hint = "2FA is enabled: set totp_secret or use an app password"
 	}
 
-	if err := successfulHttpStatus(response.StatusCode); err != nil {
-		return client.wrapError(err, req)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.Message == "" {
+			apiErr.Message = message
+		}
+		if apiErr.Hint == "" {
+			apiErr.Hint = hint
+		}
+		return fmt.Errorf("authentication failed: %w", err)
 	}
 
-	return client.wrapError(err, req)
+	if message == "" {
+		message = "no valid session"
+	}
+	if hint != "" {
+		message += " (" + hint + ")"
+	}
+	return fmt.Errorf("authentication failed: %s", message)
 }
 
-func (client *client) GetVersion() (*model.VersionResponse, error) {
-	client.logger.Debug().Msg("Get version")
-	versionResponse := model.VersionResponse{}
+// DeleteSession logs out, unless sessions are reused, then the session is kept for the next sync.
+func (client *client) DeleteSession(ctx context.Context) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
 	if err := client.auth.verify(); err != nil {
-		return &versionResponse, client.wrapError(err, nil)
+		return client.wrapError(err, nil)
 	}
-
-	req, err := http.NewRequest("GET", client.ApiPath("info/version"), nil)
-	if err != nil {
-		return &versionResponse, client.wrapError(err, req)
+	if client.sessions.Reuse {
+		client.logger.Debug().Msg("Keeping session for reuse")
+		client.saveSession()
+		return nil
 	}
-	req.Header.Set("sid", client.auth.sid)
-	req.Header.Set("User-Agent", userAgent)
+	return client.deleteSession(ctx)
+}
 
-	response, err := httpClient.Do(req)
-	if err != nil {
-		return &versionResponse, client.wrapError(err, req)
+// Close deletes the session, including a persisted one.
+func (client *client) Close(ctx context.Context) error {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	defer client.removeSession()
+	if !client.auth.valid {
+		return nil
 	}
+	return client.deleteSession(ctx)
+}
 
-	if err := successfulHttpStatus(response.StatusCode); err != nil {
-		return &versionResponse, client.wrapError(err, req)
+// deleteSession logs out, the caller must hold mu.
+func (client *client) deleteSession(ctx context.Context) error {
+	client.logger.Debug().Msg("Delete session")
+	sid := client.auth.sid
+	client.auth = auth{}
+	if sid == "" {
+		return nil
 	}
 
-	body, err := io.ReadAll(response.Body)
+	req, err := client.newRequest(ctx, "DELETE", "auth", nil)
 	if err != nil {
-		return &versionResponse, client.wrapError(err, req)
+		return client.wrapError(err, req)
 	}
+	req.Header.Set("sid", sid)
 
-	err = json.Unmarshal(body, &versionResponse)
+	_, err = client.do(req, retryIdempotent, client.timeouts.Request)
+	return client.wrapError(err, req)
+}
 
-	return &versionResponse, client.wrapError(err, req)
+func (client *client) GetVersion(ctx context.Context) (*model.VersionResponse, error) {
+	client.logger.Debug().Msg("Get version")
+	versionResponse := model.VersionResponse{}
+	return &versionResponse, client.getJSON(ctx, "info/version", &versionResponse)
 }
 
-func (client *client) GetTeleporter() ([]byte, error) {
+func (client *client) GetTeleporter(ctx context.Context) ([]byte, error) {
 	client.logger.Debug().Msg("Get teleporter")
-	if err := client.auth.verify(); err != nil {
-		return nil, client.wrapError(err, nil)
-	}
-	req, err := http.NewRequest("GET", client.ApiPath("teleporter"), nil)
-	if err != nil {
-		return nil, client.wrapError(err, req)
-	}
-	req.Header.Set("sid", client.auth.sid)
-	req.Header.Set("User-Agent", userAgent)
-
-	response, err := httpClient.Do(req)
+	req, err := client.newRequest(ctx, "GET", "teleporter", nil)
 	if err != nil {
 		return nil, client.wrapError(err, req)
 	}
 
-	if err := successfulHttpStatus(response.StatusCode); err != nil {
-		return nil, client.wrapError(err, req)
-	}
-
-	body, err := io.ReadAll(response.Body)
+	body, err := client.send(req, retryIdempotent, client.timeouts.Teleporter)
 	return body, client.wrapError(err, req)
 }
 
-func (client *client) PostTeleporter(payload []byte, teleporterRequest *model.PostTeleporterRequest) error {
+func (client *client) PostTeleporter(ctx context.Context, payload []byte, teleporterRequest *model.PostTeleporterRequest) error {
 	client.logger.Debug().Any("payload", teleporterRequest).Msg("Post teleporter")
 
-	if err := client.auth.verify(); err != nil {
-		return client.wrapError(err, nil)
-	}
-
 	var requestBody bytes.Buffer
 	writer := multipart.NewWriter(&requestBody)
 
@@ -225,88 +381,116 @@ func (client *client) PostTeleporter(payload []byte, teleporterRequest *model.Po
 		return client.wrapError(err, nil)
 	}
 
-	req, err := http.NewRequest("POST", client.ApiPath("teleporter"), &requestBody)
+	req, err := client.newRequest(ctx, "POST", "teleporter", &requestBody)
 	if err != nil {
 		return client.wrapError(err, req)
 	}
-	req.Header.Set("sid", client.auth.sid)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set("User-Agent", userAgent)
-
-	response, err := httpClient.Do(req)
-	if err != nil {
-		return client.wrapError(err, req)
-	}
 
-	if err := successfulHttpStatus(response.StatusCode); err != nil {
-		return client.wrapError(err, req)
-	}
-
-	return nil
+	// The import is not idempotent, it is retried only if it did not reach Pi-hole.
+	_, err = client.send(req, retryUnsent, client.timeouts.Teleporter)
+	return client.wrapError(err, req)
 }
 
-func (client *client) GetConfig() (configResponse *model.ConfigResponse, err error) {
+func (client *client) GetConfig(ctx context.Context) (configResponse *model.ConfigResponse, err error) {
 	client.logger.Debug().Msg("Get config")
-	if err := client.auth.verify(); err != nil {
-		return configResponse, client.wrapError(err, nil)
-	}
+	return configResponse, client.getJSON(ctx, "config", &configResponse)
+}
 
-	req, err := http.NewRequest("GET", client.ApiPath("config"), nil)
-	if err != nil {
-		return configResponse, client.wrapError(err, req)
-	}
-	req.Header.Set("sid", client.auth.sid)
-	req.Header.Set("User-Agent", userAgent)
+func (client *client) PatchConfig(ctx context.Context, patchRequest *model.PatchConfigRequest) error {
+	client.logger.Debug().Any("payload", patchRequest.Redacted()).Msgf("Patch config")
+	return client.requestJSON(ctx, "PATCH", "config", nil, patchRequest, nil, retryIdempotent)
+}
 
-	response, err := httpClient.Do(req)
-	if err != nil {
-		return configResponse, client.wrapError(err, req)
-	}
+func (client *client) GetGroups(ctx context.Context) (*model.GroupsResponse, error) {
+	client.logger.Debug().Msg("Get groups")
+	groupsResponse := model.GroupsResponse{}
+	return &groupsResponse, client.getJSON(ctx, "groups", &groupsResponse)
+}
 
-	if err := successfulHttpStatus(response.StatusCode); err != nil {
-		return configResponse, client.wrapError(err, req)
-	}
+func (client *client) GetLists(ctx context.Context) (*model.ListsResponse, error) {
+	client.logger.Debug().Msg("Get lists")
+	listsResponse := model.ListsResponse{}
+	return &listsResponse, client.getJSON(ctx, "lists", &listsResponse)
+}
 
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		return configResponse, client.wrapError(err, req)
-	}
+func (client *client) GetDomains(ctx context.Context) (*model.DomainsResponse, error) {
+	client.logger.Debug().Msg("Get domains")
+	domainsResponse := model.DomainsResponse{}
+	return &domainsResponse, client.getJSON(ctx, "domains", &domainsResponse)
+}
 
-	if err := json.Unmarshal(body, &configResponse); err != nil {
-		return configResponse, client.wrapError(err, req)
-	}
+func (client *client) GetClients(ctx context.Context) (*model.ClientsResponse, error) {
+	client.logger.Debug().Msg("Get clients")
+	clientsResponse := model.ClientsResponse{}
+	return &clientsResponse, client.getJSON(ctx, "clients", &clientsResponse)
+}
 
-	return configResponse, client.wrapError(err, req)
+func (client *client) getJSON(ctx context.Context, target string, v interface{}) error {
+	return client.requestJSON(ctx, "GET", target, nil, nil, v, retryIdempotent)
 }
 
-func (client *client) PatchConfig(patchRequest *model.PatchConfigRequest) error {
-	client.logger.Debug().Any("payload", patchRequest).Msgf("Patch config")
-	if err := client.auth.verify(); err != nil {
-		return client.wrapError(err, nil)
+// requestJSON sends request as JSON body to target, and decodes the response body into response. Both can be nil.
+func (client *client) requestJSON(ctx context.Context, method, target string, query url.Values, request, response interface{}, policy retryPolicy) error {
+	var body io.Reader
+	if request != nil {
+		reqBytes, err := json.Marshal(request)
+		if err != nil {
+			return client.wrapError(err, nil)
+		}
+		body = bytes.NewReader(reqBytes)
 	}
 
-	reqBytes, err := json.Marshal(patchRequest)
+	req, err := client.newRequest(ctx, method, target, body)
 	if err != nil {
-		return client.wrapError(err, nil)
+		return client.wrapError(err, req)
+	}
+	req.URL.RawQuery = query.Encode()
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	req, err := http.NewRequest("PATCH", client.ApiPath("config"), bytes.NewReader(reqBytes))
-	if err != nil {
+	respBytes, err := client.send(req, policy, client.timeouts.Request)
+	if err != nil || response == nil {
 		return client.wrapError(err, req)
 	}
-	req.Header.Set("sid", client.auth.sid)
-	req.Header.Set("User-Agent", userAgent)
+	return client.wrapError(json.Unmarshal(respBytes, response), req)
+}
 
-	response, err := httpClient.Do(req)
+// send sends req with the session of the client, and authenticates again and resends req once if Pi-hole rejects the session.
+func (client *client) send(req *http.Request, policy retryPolicy, timeout time.Duration) ([]byte, error) {
+	sid, err := client.session(req.Context())
 	if err != nil {
-		return client.wrapError(err, req)
+		return nil, err
 	}
+	req.Header.Set("sid", sid)
 
-	if err := successfulHttpStatus(response.StatusCode); err != nil {
-		return client.wrapError(err, req)
+	body, err := client.do(req, policy, timeout)
+	if isUnauthorized(err) && rewind(req) {
+		if sid, err = client.reauthenticate(req.Context(), sid); err != nil {
+			return nil, err
+		}
+		req.Header.Set("sid", sid)
+		body, err = client.do(req, policy, timeout)
 	}
 
-	return client.wrapError(err, req)
+	if err == nil {
+		client.used(sid)
+	}
+	return body, err
+}
+
+func (client *client) newRequest(ctx context.Context, method, target string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, client.ApiPath(target), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return req, nil
+}
+
+func (client *client) PiHole() model.PiHole {
+	return client.piHole
 }
 
 func (client *client) String() string {
@@ -326,11 +510,3 @@ func (client *client) wrapError(err error, req *http.Request) error {
 	}
 	return nil
 }
-
-func successfulHttpStatus(statusCode int) error {
-	if statusCode >= 200 && statusCode <= 299 {
-		return nil
-	}
-
-	return fmt.Errorf("unexpected status code: %d", statusCode)
-}
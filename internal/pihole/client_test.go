@@ -27,7 +27,7 @@ type clientTestSuite struct {
 
 func (suite *clientTestSuite) SetupTest() {
 	client := createClient(piHole)
-	err := client.Authenticate()
+	err := client.Authenticate(context.Background())
 	require.NoError(suite.T(), err)
 	suite.client = client
 }
@@ -37,34 +37,34 @@ func TestClientIntegration(t *testing.T) {
 }
 
 func (suite *clientTestSuite) TestClient_Authenticate() {
-	err := suite.client.Authenticate()
+	err := suite.client.Authenticate(context.Background())
 
 	assert.NoError(suite.T(), err)
 }
 
 func (suite *clientTestSuite) TestClient_DeleteSession() {
-	err := suite.client.DeleteSession()
+	err := suite.client.DeleteSession(context.Background())
 
 	assert.NoError(suite.T(), err)
 }
 
 func (suite *clientTestSuite) TestClient_GetVersion() {
-	version, err := suite.client.GetVersion()
+	version, err := suite.client.GetVersion(context.Background())
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), version)
 }
 
 func (suite *clientTestSuite) TestClient_GetTeleporter() {
-	payload, err := suite.client.GetTeleporter()
+	payload, err := suite.client.GetTeleporter(context.Background())
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), payload)
 }
 
 func (suite *clientTestSuite) TestClient_PostTeleporter() {
-	payload, _ := suite.client.GetTeleporter()
-	err := suite.client.PostTeleporter(payload, &model.PostTeleporterRequest{
+	payload, _ := suite.client.GetTeleporter(context.Background())
+	err := suite.client.PostTeleporter(context.Background(), payload, &model.PostTeleporterRequest{
 		Config:     true,
 		DHCPLeases: true,
 		Gravity: model.PostGravityRequest{
@@ -82,7 +82,7 @@ func (suite *clientTestSuite) TestClient_PostTeleporter() {
 }
 
 func (suite *clientTestSuite) TestClient_GetConfig() {
-	conf, err := suite.client.GetConfig()
+	conf, err := suite.client.GetConfig(context.Background())
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), conf)
@@ -99,21 +99,52 @@ func (suite *clientTestSuite) TestClient_PatchConfig() {
 			Misc:     nil,
 			Debug:    nil,
 		}}
-	err := suite.client.PatchConfig(&request)
+	err := suite.client.PatchConfig(context.Background(), &request)
 
 	assert.NoError(suite.T(), err)
 }
 
+func (suite *clientTestSuite) TestClient_GetGroups() {
+	groups, err := suite.client.GetGroups(context.Background())
+
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), groups.Groups)
+}
+
+func (suite *clientTestSuite) TestClient_GetLists() {
+	lists, err := suite.client.GetLists(context.Background())
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), lists)
+}
+
+func (suite *clientTestSuite) TestClient_GetDomains() {
+	domains, err := suite.client.GetDomains(context.Background())
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), domains)
+}
+
+func (suite *clientTestSuite) TestClient_GetClients() {
+	clients, err := suite.client.GetClients(context.Background())
+
+	assert.NoError(suite.T(), err)
+	assert.NotNil(suite.T(), clients)
+}
+
 func TestClient_String(t *testing.T) {
 	piHole := model.NewPiHole("http://asdfasdf.com:1234", apiPassword)
-	s := NewClient(piHole).String()
+	c, err := NewClient(piHole, Options{})
+	require.NoError(t, err)
+	s := c.String()
 
 	assert.Equal(t, "http://asdfasdf.com:1234", s)
 }
 
 func TestClient_ApiPath(t *testing.T) {
 	piHole := model.NewPiHole("http://asdfasdf.com:1234", apiPassword)
-	c := NewClient(piHole)
+	c, err := NewClient(piHole, Options{})
+	require.NoError(t, err)
 
 	url := c.String()
 	path := c.ApiPath("testing")
@@ -143,5 +174,9 @@ func createClient(container tc.Container) Client {
 
 	host := fmt.Sprintf("http://localhost:%s", apiPort.Port())
 
-	return NewClient(model.NewPiHole(host, apiPassword))
+	client, err := NewClient(model.NewPiHole(host, apiPassword), Options{})
+	if err != nil {
+		panic(err)
+	}
+	return client
 }
@@ -0,0 +1,87 @@
+package pihole
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned when the Pi-hole API responds with an unsuccessful status code,
+// with the key, message and hint of the error in the response body if Pi-hole sent one.
+type APIError struct {
+	StatusCode int
+	Key        string
+	Message    string
+	Hint       string
+	// Endpoint is the method and path of the request, e.g. PATCH /api/config.
+	Endpoint string
+}
+
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "unexpected status code: %d", e.StatusCode)
+	if e.Key != "" {
+		fmt.Fprintf(&sb, " %s", e.Key)
+	}
+	if e.Message != "" {
+		fmt.Fprintf(&sb, ": %s", e.Message)
+	}
+	if e.Hint != "" {
+		fmt.Fprintf(&sb, " (%s)", e.Hint)
+	}
+	return sb.String()
+}
+
+// BadPassword reports whether Pi-hole rejected the password or 2FA code of a login.
+func (e *APIError) BadPassword() bool {
+	return e.StatusCode == http.StatusUnauthorized && e.Endpoint == "POST /api/auth"
+}
+
+// Unauthorized reports whether Pi-hole rejected the session of a request, e.g. because it expired.
+func (e *APIError) Unauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized && !e.BadPassword()
+}
+
+// RateLimited reports whether Pi-hole refused the request because of too many requests or no free API seats.
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// ReadOnly reports whether Pi-hole refused to change its config, because the session may not change it,
+// e.g. an app password without webserver.api.app_sudo, or because a key is set by an env var on the Pi-hole.
+func (e *APIError) ReadOnly() bool {
+	if e.StatusCode == http.StatusForbidden {
+		return true
+	}
+	message := strings.ToLower(e.Message + " " + e.Hint)
+	return e.StatusCode == http.StatusBadRequest && (strings.Contains(message, "read-only") || strings.Contains(message, "environment variable"))
+}
+
+// Unsupported reports whether the Pi-hole does not know the endpoint, e.g. because it runs an older version.
+func (e *APIError) Unsupported() bool {
+	return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusMethodNotAllowed || e.StatusCode == http.StatusNotImplemented
+}
+
+// responseError returns an *APIError for an unsuccessful response to req, or nil if the response was successful.
+func (client *client) responseError(req *http.Request, response *http.Response, body []byte) error {
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return nil
+	}
+
+	apiErr := &APIError{StatusCode: response.StatusCode, Endpoint: client.endpoint(req)}
+	errorResponse := model.ErrorResponse{}
+	if err := json.Unmarshal(body, &errorResponse); err == nil {
+		apiErr.Key = errorResponse.Error.Key
+		apiErr.Message = errorResponse.Error.Message
+		apiErr.Hint = errorResponse.Error.Hint
+	}
+	return apiErr
+}
+
+// endpoint returns the method and the path of req below the url of the Pi-hole, e.g. PATCH /api/config.
+func (client *client) endpoint(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, strings.TrimSuffix(client.piHole.Url.Path, "/"))
+	return req.Method + " " + path
+}
@@ -0,0 +1,65 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_apiError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"key": "bad_request", "message": "Config items set via environment variables cannot be changed via the API", "hint": "dns.upstreams"}, "took": 0.001}`))
+	}))
+	defer server.Close()
+
+	c, _ := newRetryClient(t, server.URL+"/pihole/", 1)
+	err := c.PatchConfig(context.Background(), &model.PatchConfigRequest{})
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, APIError{
+		StatusCode: http.StatusBadRequest,
+		Key:        "bad_request",
+		Message:    "Config items set via environment variables cannot be changed via the API",
+		Hint:       "dns.upstreams",
+		Endpoint:   "PATCH /api/config",
+	}, *apiErr)
+	assert.EqualError(t, apiErr, "unexpected status code: 400 bad_request: Config items set via environment variables cannot be changed via the API (dns.upstreams)")
+	assert.True(t, apiErr.ReadOnly())
+}
+
+func TestClient_apiError_noBody(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	c, _ := newRetryClient(t, server.URL, 1)
+	_, err := c.GetGroups(context.Background())
+
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "GET /api/groups", apiErr.Endpoint)
+	assert.EqualError(t, apiErr, "unexpected status code: 404")
+	assert.True(t, apiErr.Unsupported())
+}
+
+func TestAPIError_classify(t *testing.T) {
+	badPassword := &APIError{StatusCode: 401, Endpoint: "POST /api/auth"}
+	assert.True(t, badPassword.BadPassword())
+	assert.False(t, badPassword.Unauthorized())
+
+	expired := &APIError{StatusCode: 401, Endpoint: "GET /api/config"}
+	assert.False(t, expired.BadPassword())
+	assert.True(t, expired.Unauthorized())
+
+	assert.True(t, (&APIError{StatusCode: 429}).RateLimited())
+	assert.True(t, (&APIError{StatusCode: 403, Key: "forbidden"}).ReadOnly())
+	assert.False(t, (&APIError{StatusCode: 400, Key: "bad_request", Message: "Invalid value"}).ReadOnly())
+	assert.True(t, (&APIError{StatusCode: 501}).Unsupported())
+	assert.False(t, (&APIError{StatusCode: 500}).Unsupported())
+}
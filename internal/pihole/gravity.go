@@ -0,0 +1,258 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"net/url"
+	"path"
+)
+
+// ErrNotFound is returned when a gravity item that is requested by its key does not exist.
+var ErrNotFound = errors.New("not found")
+
+func (client *client) GetGroup(ctx context.Context, name string) (*model.Group, error) {
+	client.logger.Debug().Str("group", name).Msg("Get group")
+	response := model.GroupsResponse{}
+	if err := client.requestJSON(ctx, "GET", itemPath("groups", name), nil, nil, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	return first(response.Groups, "group "+name)
+}
+
+func (client *client) CreateGroup(ctx context.Context, group *model.GroupRequest) (*model.Group, error) {
+	client.logger.Debug().Any("payload", group).Msg("Create group")
+	response := model.GroupsResponse{}
+	if err := client.requestJSON(ctx, "POST", "groups", nil, group, &response, retryUnsent); err != nil {
+		return nil, err
+	}
+	if err := processedError(response.Processed); err != nil {
+		return nil, client.wrapError(err, nil)
+	}
+	return first(response.Groups, "group "+group.Name)
+}
+
+// UpdateGroup updates the group name, group.Name renames it if it differs.
+func (client *client) UpdateGroup(ctx context.Context, name string, group *model.GroupRequest) (*model.Group, error) {
+	client.logger.Debug().Str("group", name).Any("payload", group).Msg("Update group")
+	response := model.GroupsResponse{}
+	if err := client.requestJSON(ctx, "PUT", itemPath("groups", name), nil, group, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	if err := processedError(response.Processed); err != nil {
+		return nil, client.wrapError(err, nil)
+	}
+	return first(response.Groups, "group "+group.Name)
+}
+
+func (client *client) DeleteGroup(ctx context.Context, name string) error {
+	client.logger.Debug().Str("group", name).Msg("Delete group")
+	return client.requestJSON(ctx, "DELETE", itemPath("groups", name), nil, nil, nil, retryIdempotent)
+}
+
+func (client *client) DeleteGroups(ctx context.Context, names []string) error {
+	client.logger.Debug().Strs("groups", names).Msg("Delete groups")
+	items := make([]model.BatchDeleteItem, len(names))
+	for i, name := range names {
+		items[i] = model.BatchDeleteItem{Item: name}
+	}
+	return client.batchDelete(ctx, "groups", items)
+}
+
+func (client *client) GetList(ctx context.Context, key model.ListKey) (*model.List, error) {
+	client.logger.Debug().Str("list", key.Address).Str("type", key.Type).Msg("Get list")
+	response := model.ListsResponse{}
+	if err := client.requestJSON(ctx, "GET", itemPath("lists", key.Address), listQuery(key.Type), nil, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	return first(response.Lists, "list "+key.Address)
+}
+
+func (client *client) CreateList(ctx context.Context, list *model.ListRequest) (*model.List, error) {
+	client.logger.Debug().Any("payload", list).Msg("Create list")
+	response := model.ListsResponse{}
+	if err := client.requestJSON(ctx, "POST", "lists", listQuery(list.Type), list, &response, retryUnsent); err != nil {
+		return nil, err
+	}
+	if err := processedError(response.Processed); err != nil {
+		return nil, client.wrapError(err, nil)
+	}
+	return first(response.Lists, "list "+list.Address)
+}
+
+func (client *client) UpdateList(ctx context.Context, key model.ListKey, list *model.ListRequest) (*model.List, error) {
+	client.logger.Debug().Str("list", key.Address).Any("payload", list).Msg("Update list")
+	response := model.ListsResponse{}
+	if err := client.requestJSON(ctx, "PUT", itemPath("lists", key.Address), listQuery(key.Type), list, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	if err := processedError(response.Processed); err != nil {
+		return nil, client.wrapError(err, nil)
+	}
+	return first(response.Lists, "list "+key.Address)
+}
+
+func (client *client) DeleteList(ctx context.Context, key model.ListKey) error {
+	client.logger.Debug().Str("list", key.Address).Str("type", key.Type).Msg("Delete list")
+	return client.requestJSON(ctx, "DELETE", itemPath("lists", key.Address), listQuery(key.Type), nil, nil, retryIdempotent)
+}
+
+func (client *client) DeleteLists(ctx context.Context, keys []model.ListKey) error {
+	client.logger.Debug().Int("lists", len(keys)).Msg("Delete lists")
+	items := make([]model.BatchDeleteItem, len(keys))
+	for i, key := range keys {
+		items[i] = model.BatchDeleteItem{Item: key.Address, Type: key.Type}
+	}
+	return client.batchDelete(ctx, "lists", items)
+}
+
+// GetDomainsOf lists the domains of a type and kind, e.g. allow and exact.
+func (client *client) GetDomainsOf(ctx context.Context, domainType, kind string) (*model.DomainsResponse, error) {
+	client.logger.Debug().Str("type", domainType).Str("kind", kind).Msg("Get domains")
+	response := model.DomainsResponse{}
+	if err := client.requestJSON(ctx, "GET", domainsPath(domainType, kind), nil, nil, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (client *client) GetDomain(ctx context.Context, key model.DomainKey) (*model.Domain, error) {
+	client.logger.Debug().Str("domain", key.Domain).Str("type", key.Type).Str("kind", key.Kind).Msg("Get domain")
+	response := model.DomainsResponse{}
+	if err := client.requestJSON(ctx, "GET", itemPath(domainsPath(key.Type, key.Kind), key.Domain), nil, nil, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	return first(response.Domains, "domain "+key.Domain)
+}
+
+// CreateDomain adds domain.Domain to the domains of domain.Type and domain.Kind.
+func (client *client) CreateDomain(ctx context.Context, domain *model.DomainRequest) (*model.Domain, error) {
+	client.logger.Debug().Any("payload", domain).Msg("Create domain")
+	response := model.DomainsResponse{}
+	if err := client.requestJSON(ctx, "POST", domainsPath(domain.Type, domain.Kind), nil, domain, &response, retryUnsent); err != nil {
+		return nil, err
+	}
+	if err := processedError(response.Processed); err != nil {
+		return nil, client.wrapError(err, nil)
+	}
+	return first(response.Domains, "domain "+domain.Domain)
+}
+
+// UpdateDomain updates the domain of key, a different domain.Type or domain.Kind moves it.
+func (client *client) UpdateDomain(ctx context.Context, key model.DomainKey, domain *model.DomainRequest) (*model.Domain, error) {
+	client.logger.Debug().Str("domain", key.Domain).Any("payload", domain).Msg("Update domain")
+	response := model.DomainsResponse{}
+	if err := client.requestJSON(ctx, "PUT", itemPath(domainsPath(key.Type, key.Kind), key.Domain), nil, domain, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	if err := processedError(response.Processed); err != nil {
+		return nil, client.wrapError(err, nil)
+	}
+	return first(response.Domains, "domain "+key.Domain)
+}
+
+func (client *client) DeleteDomain(ctx context.Context, key model.DomainKey) error {
+	client.logger.Debug().Str("domain", key.Domain).Str("type", key.Type).Str("kind", key.Kind).Msg("Delete domain")
+	return client.requestJSON(ctx, "DELETE", itemPath(domainsPath(key.Type, key.Kind), key.Domain), nil, nil, nil, retryIdempotent)
+}
+
+func (client *client) DeleteDomains(ctx context.Context, keys []model.DomainKey) error {
+	client.logger.Debug().Int("domains", len(keys)).Msg("Delete domains")
+	items := make([]model.BatchDeleteItem, len(keys))
+	for i, key := range keys {
+		items[i] = model.BatchDeleteItem{Item: key.Domain, Type: key.Type, Kind: key.Kind}
+	}
+	return client.batchDelete(ctx, "domains", items)
+}
+
+func (client *client) GetClient(ctx context.Context, id string) (*model.Client, error) {
+	client.logger.Debug().Str("id", id).Msg("Get client")
+	response := model.ClientsResponse{}
+	if err := client.requestJSON(ctx, "GET", itemPath("clients", id), nil, nil, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	return first(response.Clients, "client "+id)
+}
+
+func (client *client) CreateClient(ctx context.Context, c *model.ClientRequest) (*model.Client, error) {
+	client.logger.Debug().Any("payload", c).Msg("Create client")
+	response := model.ClientsResponse{}
+	if err := client.requestJSON(ctx, "POST", "clients", nil, c, &response, retryUnsent); err != nil {
+		return nil, err
+	}
+	if err := processedError(response.Processed); err != nil {
+		return nil, client.wrapError(err, nil)
+	}
+	return first(response.Clients, "client "+c.Client)
+}
+
+func (client *client) UpdateClient(ctx context.Context, id string, c *model.ClientRequest) (*model.Client, error) {
+	client.logger.Debug().Str("id", id).Any("payload", c).Msg("Update client")
+	response := model.ClientsResponse{}
+	if err := client.requestJSON(ctx, "PUT", itemPath("clients", id), nil, c, &response, retryIdempotent); err != nil {
+		return nil, err
+	}
+	if err := processedError(response.Processed); err != nil {
+		return nil, client.wrapError(err, nil)
+	}
+	return first(response.Clients, "client "+id)
+}
+
+func (client *client) DeleteClient(ctx context.Context, id string) error {
+	client.logger.Debug().Str("id", id).Msg("Delete client")
+	return client.requestJSON(ctx, "DELETE", itemPath("clients", id), nil, nil, nil, retryIdempotent)
+}
+
+func (client *client) DeleteClients(ctx context.Context, ids []string) error {
+	client.logger.Debug().Strs("ids", ids).Msg("Delete clients")
+	items := make([]model.BatchDeleteItem, len(ids))
+	for i, id := range ids {
+		items[i] = model.BatchDeleteItem{Item: id}
+	}
+	return client.batchDelete(ctx, "clients", items)
+}
+
+// batchDelete deletes items of a gravity table with a single request.
+func (client *client) batchDelete(ctx context.Context, table string, items []model.BatchDeleteItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return client.requestJSON(ctx, "POST", table+":batchDelete", nil, items, nil, retryIdempotent)
+}
+
+// itemPath returns the path of an item of a gravity table, the item is escaped because addresses and regexes contain slashes.
+func itemPath(table, item string) string {
+	return path.Join(table, url.PathEscape(item))
+}
+
+func domainsPath(domainType, kind string) string {
+	return path.Join("domains", url.PathEscape(domainType), url.PathEscape(kind))
+}
+
+func listQuery(listType string) url.Values {
+	if listType == "" {
+		return nil
+	}
+	return url.Values{"type": []string{listType}}
+}
+
+// first returns the first of the items Pi-hole responded with, or ErrNotFound if there are none.
+func first[T any](items []T, name string) (*T, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+	}
+	return &items[0], nil
+}
+
+// processedError returns the errors of the items that Pi-hole failed to process, e.g. because they already exist.
+func processedError(processed *model.Processed) error {
+	if processed == nil {
+		return nil
+	}
+	errs := make([]error, len(processed.Errors))
+	for i, processedErr := range processed.Errors {
+		errs[i] = fmt.Errorf("%s: %s", processedErr.Item, processedErr.Error)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,145 @@
+package pihole
+
+import (
+	"context"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type gravityRequest struct {
+	method string
+	uri    string
+	body   string
+}
+
+func newGravityClient(t *testing.T, response string) (*client, *[]gravityRequest) {
+	t.Helper()
+	var requests []gravityRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, gravityRequest{method: r.Method, uri: r.URL.RequestURI(), body: string(body)})
+		if r.Method == "DELETE" || r.URL.Path == "/api/groups:batchDelete" || r.URL.Path == "/api/lists:batchDelete" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	c, _ := newRetryClient(t, server.URL, 1)
+	return c, &requests
+}
+
+func TestClient_groups(t *testing.T) {
+	c, requests := newGravityClient(t, `{"groups": [{"id": 2, "name": "iot", "enabled": true}], "processed": {"success": [{"item": "iot"}], "errors": []}}`)
+	ctx := context.Background()
+
+	group, err := c.GetGroup(ctx, "iot")
+	require.NoError(t, err)
+	assert.Equal(t, model.Group{Id: 2, Name: "iot", Enabled: true}, *group)
+
+	_, err = c.CreateGroup(ctx, &model.GroupRequest{Name: "iot", Enabled: true})
+	require.NoError(t, err)
+	_, err = c.UpdateGroup(ctx, "iot", &model.GroupRequest{Name: "iot", Comment: "things", Enabled: true})
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteGroup(ctx, "iot"))
+	require.NoError(t, c.DeleteGroups(ctx, []string{"iot", "kids"}))
+	require.NoError(t, c.DeleteGroups(ctx, nil))
+
+	assert.Equal(t, []gravityRequest{
+		{"GET", "/api/groups/iot", ""},
+		{"POST", "/api/groups", `{"name":"iot","comment":"","enabled":true}`},
+		{"PUT", "/api/groups/iot", `{"name":"iot","comment":"things","enabled":true}`},
+		{"DELETE", "/api/groups/iot", ""},
+		{"POST", "/api/groups:batchDelete", `[{"item":"iot"},{"item":"kids"}]`},
+	}, *requests)
+}
+
+func TestClient_lists(t *testing.T) {
+	c, requests := newGravityClient(t, `{"lists": [{"id": 1, "address": "https://example.com/hosts.txt", "type": "block", "groups": [0]}]}`)
+	ctx := context.Background()
+	key := model.ListKey{Address: "https://example.com/hosts.txt", Type: "block"}
+
+	list, err := c.GetList(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0}, list.Groups)
+
+	_, err = c.CreateList(ctx, &model.ListRequest{Address: key.Address, Type: "block", Groups: []int{0}, Enabled: true})
+	require.NoError(t, err)
+	_, err = c.UpdateList(ctx, key, &model.ListRequest{Type: "block", Groups: []int{0, 2}, Enabled: false})
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteList(ctx, key))
+	require.NoError(t, c.DeleteLists(ctx, []model.ListKey{key}))
+
+	assert.Equal(t, []gravityRequest{
+		{"GET", "/api/lists/https:%2F%2Fexample.com%2Fhosts.txt?type=block", ""},
+		{"POST", "/api/lists?type=block", `{"address":"https://example.com/hosts.txt","comment":"","groups":[0],"enabled":true}`},
+		{"PUT", "/api/lists/https:%2F%2Fexample.com%2Fhosts.txt?type=block", `{"comment":"","groups":[0,2],"enabled":false}`},
+		{"DELETE", "/api/lists/https:%2F%2Fexample.com%2Fhosts.txt?type=block", ""},
+		{"POST", "/api/lists:batchDelete", `[{"item":"https://example.com/hosts.txt","type":"block"}]`},
+	}, *requests)
+}
+
+func TestClient_domains(t *testing.T) {
+	c, requests := newGravityClient(t, `{"domains": [{"id": 3, "domain": "(\\.|^)example\\.com$", "type": "deny", "kind": "regex"}]}`)
+	ctx := context.Background()
+	key := model.DomainKey{Domain: `(\.|^)example\.com$`, Type: "deny", Kind: "regex"}
+
+	domains, err := c.GetDomainsOf(ctx, "deny", "regex")
+	require.NoError(t, err)
+	assert.Len(t, domains.Domains, 1)
+
+	domain, err := c.GetDomain(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, key.Domain, domain.Domain)
+
+	_, err = c.CreateDomain(ctx, &model.DomainRequest{Domain: key.Domain, Type: "deny", Kind: "regex", Enabled: true})
+	require.NoError(t, err)
+	_, err = c.UpdateDomain(ctx, key, &model.DomainRequest{Type: "allow", Kind: "regex", Enabled: true})
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteDomain(ctx, key))
+
+	assert.Equal(t, []gravityRequest{
+		{"GET", "/api/domains/deny/regex", ""},
+		{"GET", "/api/domains/deny/regex/%28%5C.%7C%5E%29example%5C.com$", ""},
+		{"POST", "/api/domains/deny/regex", `{"domain":"(\\.|^)example\\.com$","type":"deny","kind":"regex","comment":"","groups":null,"enabled":true}`},
+		{"PUT", "/api/domains/deny/regex/%28%5C.%7C%5E%29example%5C.com$", `{"type":"allow","kind":"regex","comment":"","groups":null,"enabled":true}`},
+		{"DELETE", "/api/domains/deny/regex/%28%5C.%7C%5E%29example%5C.com$", ""},
+	}, *requests)
+}
+
+func TestClient_clients(t *testing.T) {
+	c, requests := newGravityClient(t, `{"clients": [{"id": 4, "client": "192.168.1.0/24", "groups": [0, 2]}]}`)
+	ctx := context.Background()
+
+	got, err := c.GetClient(ctx, "192.168.1.0/24")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, got.Groups)
+
+	_, err = c.CreateClient(ctx, &model.ClientRequest{Client: "192.168.1.0/24", Groups: []int{0, 2}})
+	require.NoError(t, err)
+	_, err = c.UpdateClient(ctx, "192.168.1.0/24", &model.ClientRequest{Comment: "lan", Groups: []int{0}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []gravityRequest{
+		{"GET", "/api/clients/192.168.1.0%2F24", ""},
+		{"POST", "/api/clients", `{"client":"192.168.1.0/24","comment":"","groups":[0,2]}`},
+		{"PUT", "/api/clients/192.168.1.0%2F24", `{"comment":"lan","groups":[0]}`},
+	}, *requests)
+}
+
+func TestClient_gravity_errors(t *testing.T) {
+	c, _ := newGravityClient(t, `{"groups": [], "processed": {"success": [], "errors": [{"item": "iot", "error": "UNIQUE constraint failed: group.name"}]}}`)
+	ctx := context.Background()
+
+	_, err := c.CreateGroup(ctx, &model.GroupRequest{Name: "iot"})
+	assert.ErrorContains(t, err, "iot: UNIQUE constraint failed: group.name")
+
+	_, err = c.GetGroup(ctx, "iot")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
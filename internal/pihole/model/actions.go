@@ -0,0 +1,62 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// When a post-sync action runs.
+const (
+	ActionNever   = "never"
+	ActionAlways  = "always"
+	ActionChanged = "changed"
+)
+
+// PostSync are the actions run on a replica after it was synced, each ActionNever, ActionAlways or ActionChanged.
+type PostSync struct {
+	// Gravity rebuilds gravity, changed means the adlists changed.
+	Gravity string
+	// RestartDNS restarts FTL, changed means the config changed.
+	RestartDNS string
+	// FlushNetwork flushes the network table, changed means anything on the replica changed.
+	FlushNetwork string
+	// FlushLogs flushes the query logs, changed means anything on the replica changed.
+	FlushLogs string
+	// GravityTimeout limits the gravity run, which downloads every adlist.
+	GravityTimeout time.Duration
+	// Timeout limits each of the other actions.
+	Timeout time.Duration
+}
+
+// OnChange reports whether any action runs only if something changed, which needs a sync to find out what it changed.
+func (p PostSync) OnChange() bool {
+	return p.Gravity == ActionChanged || p.RestartDNS == ActionChanged || p.FlushNetwork == ActionChanged || p.FlushLogs == ActionChanged
+}
+
+// Validate checks the set action modes, empty modes are left to the defaults.
+func (p *PostSync) Validate() error {
+	modes := []struct {
+		name string
+		mode string
+	}{
+		{"gravity", p.Gravity},
+		{"restart_dns", p.RestartDNS},
+		{"flush_network", p.FlushNetwork},
+		{"flush_logs", p.FlushLogs},
+	}
+	for _, mode := range modes {
+		switch mode.mode {
+		case "", ActionNever, ActionAlways, ActionChanged:
+		default:
+			return fmt.Errorf("%s: invalid mode %q, expected %q, %q or %q", mode.name, mode.mode, ActionNever, ActionAlways, ActionChanged)
+		}
+	}
+	if p.GravityTimeout < 0 {
+		return errors.New("gravity_timeout: must not be negative")
+	}
+	if p.Timeout < 0 {
+		return errors.New("timeout: must not be negative")
+	}
+	return nil
+}
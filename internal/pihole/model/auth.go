@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// appPasswordLength is the length of the application passwords generated by Pi-hole, 32 random bytes in base64.
+const appPasswordLength = 44
+
+// ResolveTotpSecret returns the TOTP secret of the Pi-hole, or an empty string if 2FA is not configured.
+// References of the form file:<path> and env:<name> are read on every call.
+func (piHole *PiHole) ResolveTotpSecret() (string, error) {
+	return ResolveSecret(piHole.TotpSecret)
+}
+
+// ValidateAuth resolves the password and TOTP secret of the Pi-hole and checks that they can be used to authenticate.
+func (piHole *PiHole) ValidateAuth() error {
+	if err := piHole.validateAuthOptions(); err != nil {
+		return err
+	}
+
+	password, err := piHole.ResolvePassword()
+	if err != nil {
+		return fmt.Errorf("password: %w", err)
+	}
+	if piHole.AppPassword {
+		if err := validateAppPassword(password); err != nil {
+			return fmt.Errorf("password: %w", err)
+		}
+	}
+
+	secret, err := piHole.ResolveTotpSecret()
+	if err != nil {
+		return fmt.Errorf("totp_secret: %w", err)
+	}
+	if _, err := DecodeTotpSecret(secret); err != nil {
+		return fmt.Errorf("totp_secret: %w", err)
+	}
+	return nil
+}
+
+// validateAuthOptions checks the auth options without resolving secrets.
+func (piHole *PiHole) validateAuthOptions() error {
+	if piHole.AppPassword && piHole.TotpSecret != "" {
+		return errors.New("totp_secret cannot be used with app_password, Pi-hole does not ask app passwords for 2FA")
+	}
+	if err := validateSecret(piHole.TotpSecret); err != nil {
+		return fmt.Errorf("totp_secret: %w", err)
+	}
+	return nil
+}
+
+// validateAppPassword checks that password looks like an application password generated by Pi-hole.
+func validateAppPassword(password string) error {
+	decoded, err := base64.StdEncoding.DecodeString(password)
+	if err != nil || len(password) != appPasswordLength || len(decoded) != 32 {
+		return fmt.Errorf("not a Pi-hole app password, expected the %d character password generated under Settings > Web interface / API", appPasswordLength)
+	}
+	return nil
+}
+
+// DecodeTotpSecret decodes a base32 TOTP secret, as shown by Pi-hole when enabling 2FA. Spaces, padding and case are ignored.
+func DecodeTotpSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return nil, errors.New("invalid base32 secret")
+	}
+	return key, nil
+}
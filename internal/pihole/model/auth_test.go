@@ -0,0 +1,64 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testAppPassword = "Z3G0xQ2v0Ykq9c4mW3kXl5yH8bT1nR7eJ6aP2sU4dF0="
+
+func TestPiHole_Decode_authOptions(t *testing.T) {
+	ph := PiHole{}
+
+	assert.NoError(t, ph.Decode("https://ph1.example.com?app_password=true|env:PH1_APP_PASSWORD"))
+	assert.True(t, ph.AppPassword)
+	assert.Empty(t, ph.TotpSecret)
+	assert.Equal(t, "https://ph1.example.com", ph.Url.String())
+
+	assert.NoError(t, ph.Decode("https://ph1.example.com?totp_secret=env:PH1_TOTP_SECRET|password"))
+	assert.False(t, ph.AppPassword)
+	assert.Equal(t, "env:PH1_TOTP_SECRET", ph.TotpSecret)
+
+	assert.ErrorContains(t, ph.Decode("https://ph1.example.com?app_password=yes|password"), "option app_password")
+	assert.ErrorContains(t, ph.Decode("https://ph1.example.com?app_password=true&totp_secret=JBSWY3DP|password"), "totp_secret cannot be used with app_password")
+	assert.ErrorContains(t, ph.Decode("https://ph1.example.com?totp_secret=env:|password"), "totp_secret: empty env secret reference")
+}
+
+func TestPiHole_ValidateAuth(t *testing.T) {
+	t.Setenv("PH_TOTP_SECRET", "jbsw y3dp ehpk 3pxp")
+
+	valid := []PiHole{
+		{Password: "password"},
+		{Password: testAppPassword, AppPassword: true},
+		{Password: "password", TotpSecret: "JBSWY3DPEHPK3PXP"},
+		{Password: "password", TotpSecret: "env:PH_TOTP_SECRET"},
+	}
+	for _, piHole := range valid {
+		assert.NoError(t, piHole.ValidateAuth())
+	}
+
+	tests := []struct {
+		piHole PiHole
+		want   string
+	}{
+		{PiHole{Password: "password", AppPassword: true}, "password: not a Pi-hole app password"},
+		{PiHole{Password: testAppPassword[:43], AppPassword: true}, "password: not a Pi-hole app password"},
+		{PiHole{Password: "env:PH_MISSING_PASSWORD"}, "password: secret env var PH_MISSING_PASSWORD not set"},
+		{PiHole{Password: "password", TotpSecret: "not base32!"}, "totp_secret: invalid base32 secret"},
+		{PiHole{Password: "password", TotpSecret: "env:PH_MISSING_TOTP"}, "totp_secret: secret env var PH_MISSING_TOTP not set"},
+		{PiHole{Password: testAppPassword, AppPassword: true, TotpSecret: "JBSWY3DPEHPK3PXP"}, "totp_secret cannot be used with app_password"},
+	}
+	for _, tt := range tests {
+		assert.ErrorContains(t, tt.piHole.ValidateAuth(), tt.want)
+	}
+}
+
+func TestDecodeTotpSecret(t *testing.T) {
+	key, err := DecodeTotpSecret("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("12345678901234567890"), key)
+
+	key, err = DecodeTotpSecret("mzxw 6===")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("foo"), key)
+}
@@ -0,0 +1,18 @@
+package model
+
+const (
+	BlockingEnabled  = "enabled"
+	BlockingDisabled = "disabled"
+)
+
+// Blocking is the blocking state of Pi-hole. Timer is the number of seconds until the state is toggled, nil if it is not.
+type Blocking struct {
+	Blocking string   `json:"blocking"`
+	Timer    *float64 `json:"timer"`
+}
+
+// BlockingRequest enables or disables blocking, and toggles it back after Timer seconds if it is set.
+type BlockingRequest struct {
+	Blocking bool     `json:"blocking"`
+	Timer    *float64 `json:"timer"`
+}
@@ -0,0 +1,138 @@
+package model
+
+type Group struct {
+	Id           int    `json:"id"`
+	Name         string `json:"name"`
+	Comment      string `json:"comment"`
+	Enabled      bool   `json:"enabled"`
+	DateAdded    int64  `json:"date_added"`
+	DateModified int64  `json:"date_modified"`
+}
+
+type List struct {
+	Id             int    `json:"id"`
+	Address        string `json:"address"`
+	Type           string `json:"type"`
+	Comment        string `json:"comment"`
+	Groups         []int  `json:"groups"`
+	Enabled        bool   `json:"enabled"`
+	DateAdded      int64  `json:"date_added"`
+	DateModified   int64  `json:"date_modified"`
+	DateUpdated    int64  `json:"date_updated"`
+	Number         int    `json:"number"`
+	InvalidDomains int    `json:"invalid_domains"`
+	AbpEntries     int    `json:"abp_entries"`
+	Status         int    `json:"status"`
+}
+
+type Domain struct {
+	Id           int    `json:"id"`
+	Domain       string `json:"domain"`
+	Unicode      string `json:"unicode"`
+	Type         string `json:"type"`
+	Kind         string `json:"kind"`
+	Comment      string `json:"comment"`
+	Groups       []int  `json:"groups"`
+	Enabled      bool   `json:"enabled"`
+	DateAdded    int64  `json:"date_added"`
+	DateModified int64  `json:"date_modified"`
+}
+
+type Client struct {
+	Id           int    `json:"id"`
+	Client       string `json:"client"`
+	Name         string `json:"name"`
+	Comment      string `json:"comment"`
+	Groups       []int  `json:"groups"`
+	DateAdded    int64  `json:"date_added"`
+	DateModified int64  `json:"date_modified"`
+}
+
+type GroupsResponse struct {
+	Groups    []Group    `json:"groups"`
+	Processed *Processed `json:"processed,omitempty"`
+}
+
+type ListsResponse struct {
+	Lists     []List     `json:"lists"`
+	Processed *Processed `json:"processed,omitempty"`
+}
+
+type DomainsResponse struct {
+	Domains   []Domain   `json:"domains"`
+	Processed *Processed `json:"processed,omitempty"`
+}
+
+type ClientsResponse struct {
+	Clients   []Client   `json:"clients"`
+	Processed *Processed `json:"processed,omitempty"`
+}
+
+// Processed reports the items of a create, update or delete request that Pi-hole processed, and the items that failed.
+type Processed struct {
+	Success []ProcessedItem  `json:"success"`
+	Errors  []ProcessedError `json:"errors"`
+}
+
+type ProcessedItem struct {
+	Item string `json:"item"`
+}
+
+type ProcessedError struct {
+	Item  string `json:"item"`
+	Error string `json:"error"`
+}
+
+type GroupRequest struct {
+	Name    string `json:"name"`
+	Comment string `json:"comment"`
+	Enabled bool   `json:"enabled"`
+}
+
+// ListKey identifies a list, the same address can be both an allow and a block list.
+type ListKey struct {
+	Address string
+	// Type is allow or block.
+	Type string
+}
+
+type ListRequest struct {
+	Address string `json:"address,omitempty"`
+	// Type is allow or block, it is sent as query parameter.
+	Type    string `json:"-"`
+	Comment string `json:"comment"`
+	Groups  []int  `json:"groups"`
+	Enabled bool   `json:"enabled"`
+}
+
+// DomainKey identifies a domain, the same domain can be on the allow and block lists, as exact domain and as regex.
+type DomainKey struct {
+	Domain string
+	// Type is allow or deny.
+	Type string
+	// Kind is exact or regex.
+	Kind string
+}
+
+type DomainRequest struct {
+	Domain string `json:"domain,omitempty"`
+	// Type and Kind of a domain can be changed by an update.
+	Type    string `json:"type"`
+	Kind    string `json:"kind"`
+	Comment string `json:"comment"`
+	Groups  []int  `json:"groups"`
+	Enabled bool   `json:"enabled"`
+}
+
+type ClientRequest struct {
+	Client  string `json:"client,omitempty"`
+	Comment string `json:"comment"`
+	Groups  []int  `json:"groups"`
+}
+
+// BatchDeleteItem is an item of a batch delete request, Type and Kind are set for lists and domains.
+type BatchDeleteItem struct {
+	Item string `json:"item"`
+	Type string `json:"type,omitempty"`
+	Kind string `json:"kind,omitempty"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// UrlOptions are the options in the query of a Pi-hole url, e.g. https://ph1.example.com?ca_file=/etc/ssl/ca.pem
+type UrlOptions struct {
+	TLS         TLS
+	AppPassword bool
+	TotpSecret  string
+}
+
+const (
+	optionCAFile             = "ca_file"
+	optionCertFile           = "cert_file"
+	optionKeyFile            = "key_file"
+	optionServerName         = "server_name"
+	optionInsecureSkipVerify = "insecure_skip_verify"
+	optionAppPassword        = "app_password"
+	optionTotpSecret         = "totp_secret"
+)
+
+// ParseUrl parses a Pi-hole url and removes the options from its query.
+func ParseUrl(uri string) (*url.URL, UrlOptions, error) {
+	options := UrlOptions{}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, options, fmt.Errorf("parse url: %w", err)
+	}
+
+	for key, values := range u.Query() {
+		value := values[len(values)-1]
+		switch key {
+		case optionCAFile:
+			options.TLS.CAFile = value
+		case optionCertFile:
+			options.TLS.CertFile = value
+		case optionKeyFile:
+			options.TLS.KeyFile = value
+		case optionServerName:
+			options.TLS.ServerName = value
+		case optionInsecureSkipVerify:
+			if options.TLS.InsecureSkipVerify, err = strconv.ParseBool(value); err != nil {
+				return nil, options, fmt.Errorf("option %s: %w", key, err)
+			}
+		case optionAppPassword:
+			if options.AppPassword, err = strconv.ParseBool(value); err != nil {
+				return nil, options, fmt.Errorf("option %s: %w", key, err)
+			}
+		case optionTotpSecret:
+			options.TotpSecret = value
+		default:
+			return nil, options, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	u.RawQuery = ""
+	u.ForceQuery = false
+
+	if err := options.TLS.Validate(); err != nil {
+		return nil, options, err
+	}
+	return u, options, nil
+}
@@ -5,11 +5,39 @@ import (
 	"github.com/rs/zerolog/log"
 	"net/url"
 	"strings"
+	"time"
 )
 
 type PiHole struct {
-	Url      *url.URL
+	Name string
+	Url  *url.URL
+	// Password is the password or app password, or a file:<path> or env:<name> reference to it.
 	Password string
+	// AppPassword marks Password as an application password generated by Pi-hole, which is not asked for 2FA.
+	AppPassword bool
+	// TotpSecret is the base32 secret of the 2FA of the Pi-hole, or a file:<path> or env:<name> reference to it.
+	TotpSecret string
+	// PasswordLine is the 1-based line of a file: password reference to read, 0 reads the whole file.
+	PasswordLine int
+	// Overrides are config values set on top of the primary config, keyed by dot-path. String values are Go templates.
+	Overrides map[string]interface{}
+	// Vars are custom variables available to override templates.
+	Vars map[string]string
+	// Timeouts limit requests to this Pi-hole, zero values mean no limit.
+	Timeouts Timeouts
+	// TLS configures the HTTPS connections to this Pi-hole.
+	TLS TLS
+	// PostSync are the actions run on this Pi-hole after it was synced as replica.
+	PostSync PostSync
+}
+
+type Timeouts struct {
+	// Connect limits establishing a connection, including the TLS handshake.
+	Connect time.Duration
+	// Request limits each API request other than teleporter transfers.
+	Request time.Duration
+	// Teleporter limits teleporter exports and imports.
+	Teleporter time.Duration
 }
 
 func NewPiHole(host, password string) PiHole {
@@ -19,27 +47,48 @@ func NewPiHole(host, password string) PiHole {
 	}
 
 	return PiHole{
+		Name:     hostName(u),
 		Url:      u,
 		Password: password,
 	}
 }
 
+// Decode parses <url>|<password>. The |password part may be left out for passwords read from a file, which the config
+// checks.
 func (piHole *PiHole) Decode(value string) error {
-	uri, password, found := strings.Cut(value, "|")
-
-	if !found {
+	uri, password, _ := strings.Cut(value, "|")
+	if uri == "" {
 		return fmt.Errorf("invalid pihole format")
 	}
 
-	parsedUrl, err := url.Parse(uri)
+	if err := validateSecret(password); err != nil {
+		return err
+	}
 
+	parsedUrl, options, err := ParseUrl(uri)
 	if err != nil {
-		return fmt.Errorf("parse url: %s", err)
+		return err
 	}
 
-	*piHole = PiHole{
-		Url:      parsedUrl,
-		Password: password,
+	decoded := PiHole{
+		Name:        hostName(parsedUrl),
+		Url:         parsedUrl,
+		Password:    password,
+		AppPassword: options.AppPassword,
+		TotpSecret:  options.TotpSecret,
+		TLS:         options.TLS,
 	}
+	if err := decoded.validateAuthOptions(); err != nil {
+		return err
+	}
+
+	*piHole = decoded
 	return nil
 }
+
+func hostName(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return u.Host
+}
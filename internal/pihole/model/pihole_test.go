@@ -20,4 +20,40 @@ func TestPiHole_Decode(t *testing.T) {
 
 	assert.Equal(t, expectedUrl, ph.Url)
 	assert.Equal(t, pw, ph.Password)
+	assert.Equal(t, "localhost:1337", ph.Name)
+}
+
+func TestPiHole_Decode_secretReference(t *testing.T) {
+	ph := PiHole{}
+
+	assert.NoError(t, ph.Decode("http://localhost:1337|file:/run/secrets/ph1"))
+	assert.Equal(t, "file:/run/secrets/ph1", ph.Password)
+
+	assert.NoError(t, ph.Decode("http://localhost:1337"))
+	assert.Empty(t, ph.Password)
+
+	assert.ErrorContains(t, ph.Decode("http://localhost:1337|env:"), "empty env secret reference")
+	assert.ErrorContains(t, ph.Decode("|asdf"), "invalid pihole format")
+}
+
+func TestPiHole_Decode_tlsOptions(t *testing.T) {
+	ph := PiHole{}
+
+	err := ph.Decode("https://ph1.example.com:8443/?ca_file=/etc/ssl/ca.pem&cert_file=/etc/ssl/client.pem&key_file=/etc/ssl/client.key&server_name=pi.hole&insecure_skip_verify=true|password")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://ph1.example.com:8443/", ph.Url.String())
+	assert.Equal(t, "ph1.example.com:8443", ph.Name)
+	assert.Equal(t, "password", ph.Password)
+	assert.Equal(t, TLS{
+		CAFile:             "/etc/ssl/ca.pem",
+		CertFile:           "/etc/ssl/client.pem",
+		KeyFile:            "/etc/ssl/client.key",
+		ServerName:         "pi.hole",
+		InsecureSkipVerify: true,
+	}, ph.TLS)
+
+	assert.ErrorContains(t, ph.Decode("https://ph1.example.com?ca=/etc/ssl/ca.pem"), `unknown option "ca"`)
+	assert.ErrorContains(t, ph.Decode("https://ph1.example.com?insecure_skip_verify=maybe"), "option insecure_skip_verify")
+	assert.ErrorContains(t, ph.Decode("https://ph1.example.com?cert_file=/etc/ssl/client.pem"), "cert_file and key_file must be set together")
 }
@@ -2,6 +2,7 @@ package model
 
 type AuthRequest struct {
 	Password string `json:"password"`
+	Totp     int    `json:"totp,omitempty"`
 }
 
 type PostGravityRequest struct {
@@ -21,13 +22,15 @@ type PostTeleporterRequest struct {
 }
 
 type PatchConfig struct {
-	DNS      map[string]interface{} `json:"dns"`
-	DHCP     map[string]interface{} `json:"dhcp"`
-	NTP      map[string]interface{} `json:"ntp"`
-	Resolver map[string]interface{} `json:"resolver"`
-	Database map[string]interface{} `json:"database"`
-	Misc     map[string]interface{} `json:"misc"`
-	Debug    map[string]interface{} `json:"debug"`
+	DNS       map[string]interface{} `json:"dns"`
+	DHCP      map[string]interface{} `json:"dhcp"`
+	NTP       map[string]interface{} `json:"ntp"`
+	Resolver  map[string]interface{} `json:"resolver"`
+	Database  map[string]interface{} `json:"database"`
+	Webserver map[string]interface{} `json:"webserver"`
+	Files     map[string]interface{} `json:"files"`
+	Misc      map[string]interface{} `json:"misc"`
+	Debug     map[string]interface{} `json:"debug"`
 }
 
 type PatchConfigRequest struct {
@@ -11,6 +11,15 @@ type AuthResponse struct {
 	} `json:"session"`
 }
 
+// ErrorResponse is the body of unsuccessful Pi-hole API responses.
+type ErrorResponse struct {
+	Error struct {
+		Key     string `json:"key"`
+		Message string `json:"message"`
+		Hint    string `json:"hint"`
+	} `json:"error"`
+}
+
 type VersionResponse struct {
 	Version struct {
 		Core struct {
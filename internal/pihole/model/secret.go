@@ -0,0 +1,85 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	secretFilePrefix = "file:"
+	secretEnvPrefix  = "env:"
+)
+
+// FileSecret returns a reference to a secret read from a file.
+func FileSecret(filename string) string {
+	return secretFilePrefix + filename
+}
+
+// ResolvePassword returns the password of the Pi-hole. References of the form file:<path> and env:<name> are read on
+// every call, so that rotated secrets are picked up without a restart.
+func (piHole *PiHole) ResolvePassword() (string, error) {
+	return resolveSecret(piHole.Password, piHole.PasswordLine)
+}
+
+// ResolveSecret returns the value, or the secret it references with file:<path> or env:<name>.
+func ResolveSecret(value string) (string, error) {
+	return resolveSecret(value, 0)
+}
+
+func resolveSecret(value string, line int) (string, error) {
+	if filename, found := strings.CutPrefix(value, secretFilePrefix); found {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return "", fmt.Errorf("read secret file: %w", err)
+		}
+		return secretLine(string(content), line, filename)
+	}
+
+	if name, found := strings.CutPrefix(value, secretEnvPrefix); found {
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret env var %s not set", name)
+		}
+		return secret, nil
+	}
+
+	return value, nil
+}
+
+// secretLine returns the given 1-based line of the content, or the whole content without trailing newlines if line is 0.
+func secretLine(content string, line int, filename string) (string, error) {
+	content = strings.TrimRight(content, "\r\n")
+	if line == 0 {
+		return content, nil
+	}
+
+	lines := SecretLines(content)
+	if line > len(lines) {
+		return "", fmt.Errorf("secret file %s has %d lines, expected at least %d", filename, len(lines), line)
+	}
+	return lines[line-1], nil
+}
+
+// SecretLines splits the content of a secrets file into lines, ignoring trailing newlines.
+func SecretLines(content string) []string {
+	content = strings.TrimRight(content, "\r\n")
+	if content == "" {
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+func validateSecret(value string) error {
+	for _, prefix := range []string{secretFilePrefix, secretEnvPrefix} {
+		if reference, found := strings.CutPrefix(value, prefix); found && reference == "" {
+			return fmt.Errorf("empty %s secret reference", strings.TrimSuffix(prefix, ":"))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPiHole_ResolvePassword(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(filename, []byte("first\r\nsecond\n"), 0o600))
+	t.Setenv("PH_PASSWORD", "fromenv")
+
+	tests := []struct {
+		piHole   PiHole
+		expected string
+	}{
+		{PiHole{Password: "plain"}, "plain"},
+		{PiHole{Password: "env:PH_PASSWORD"}, "fromenv"},
+		{PiHole{Password: "file:" + filename}, "first\r\nsecond"},
+		{PiHole{Password: "file:" + filename, PasswordLine: 1}, "first"},
+		{PiHole{Password: "file:" + filename, PasswordLine: 2}, "second"},
+	}
+
+	for _, tt := range tests {
+		password, err := tt.piHole.ResolvePassword()
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, password)
+	}
+}
+
+func TestPiHole_ResolvePassword_rotated(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "secret")
+	piHole := PiHole{Password: FileSecret(filename)}
+
+	require.NoError(t, os.WriteFile(filename, []byte("old\n"), 0o600))
+	password, err := piHole.ResolvePassword()
+	require.NoError(t, err)
+	assert.Equal(t, "old", password)
+
+	require.NoError(t, os.WriteFile(filename, []byte("new\n"), 0o600))
+	password, err = piHole.ResolvePassword()
+	require.NoError(t, err)
+	assert.Equal(t, "new", password)
+}
+
+func TestPiHole_ResolvePassword_errors(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(filename, []byte("first\n"), 0o600))
+
+	_, err := (&PiHole{Password: "file:" + filepath.Join(t.TempDir(), "missing")}).ResolvePassword()
+	assert.ErrorContains(t, err, "read secret file")
+
+	_, err = (&PiHole{Password: "env:PH_MISSING_PASSWORD"}).ResolvePassword()
+	assert.ErrorContains(t, err, "secret env var PH_MISSING_PASSWORD not set")
+
+	_, err = (&PiHole{Password: "file:" + filename, PasswordLine: 2}).ResolvePassword()
+	assert.ErrorContains(t, err, "has 1 lines, expected at least 2")
+}
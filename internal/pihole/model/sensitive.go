@@ -0,0 +1,87 @@
+package model
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+// Redacted replaces the values of SensitivePaths where config is logged or shown.
+const Redacted = "<redacted>"
+
+// SensitivePaths are config paths that are host-specific or hold credentials. They are not synced unless explicitly
+// opted in, and their values are redacted in diffs and logs.
+var SensitivePaths = []string{
+	"webserver.port",
+	"webserver.api.password",
+	"webserver.api.pwhash",
+	"webserver.api.app_pwhash",
+	"webserver.api.totp_secret",
+	"webserver.tls.*",
+	"files.*",
+}
+
+// IsSensitive reports whether the config path, split into its segments, is matched by one of SensitivePaths.
+func IsSensitive(segments []string) bool {
+	for _, pattern := range SensitivePaths {
+		if MatchPath(strings.Split(pattern, "."), segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath reports whether a dot-path pattern, split into its segments, matches the leading segments of a config path.
+// Segments are matched like path.Match, so * matches any single segment.
+func MatchPath(pattern, segments []string) bool {
+	if len(pattern) > len(segments) {
+		return false
+	}
+
+	for i, segment := range pattern {
+		matched, err := path.Match(segment, segments[i])
+		if err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// RedactConfig returns a copy of config sections, keyed by section name, with the values of SensitivePaths redacted.
+func RedactConfig(sections map[string]interface{}) map[string]interface{} {
+	return redactMap(nil, sections)
+}
+
+// Redacted returns the sections of the request with the values of SensitivePaths redacted, for logging.
+func (request *PatchConfigRequest) Redacted() map[string]interface{} {
+	bytes, err := json.Marshal(request.Config)
+	if err != nil {
+		return nil
+	}
+	sections := map[string]interface{}{}
+	if err := json.Unmarshal(bytes, &sections); err != nil {
+		return nil
+	}
+	return RedactConfig(sections)
+}
+
+func redactMap(prefix []string, values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		segments := append(prefix[:len(prefix):len(prefix)], key)
+		nested, isMap := value.(map[string]interface{})
+		switch {
+		case IsSensitive(segments):
+			redacted[key] = Redacted
+		case isMap:
+			redacted[key] = redactMap(segments, nested)
+		default:
+			redacted[key] = value
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIsSensitive(t *testing.T) {
+	assert.True(t, IsSensitive([]string{"webserver", "api", "pwhash"}))
+	assert.True(t, IsSensitive([]string{"webserver", "tls", "cert"}))
+	assert.True(t, IsSensitive([]string{"files", "log", "ftl"}))
+	assert.False(t, IsSensitive([]string{"files"}))
+	assert.False(t, IsSensitive([]string{"webserver", "api", "max_sessions"}))
+}
+
+func TestPatchConfigRequest_Redacted(t *testing.T) {
+	request := PatchConfigRequest{Config: PatchConfig{
+		DNS: map[string]interface{}{"upstreams": []interface{}{"1.1.1.1"}},
+		Webserver: map[string]interface{}{
+			"api":       map[string]interface{}{"pwhash": "$BALLOON-SHA256$hash", "max_sessions": float64(16)},
+			"interface": map[string]interface{}{"theme": "default-dark"},
+		},
+		Files: map[string]interface{}{"log": map[string]interface{}{"ftl": "/var/log/pihole/FTL.log"}},
+	}}
+
+	redacted := request.Redacted()
+	assert.Equal(t, map[string]interface{}{"upstreams": []interface{}{"1.1.1.1"}}, redacted["dns"])
+	assert.Equal(t, map[string]interface{}{
+		"api":       map[string]interface{}{"pwhash": Redacted, "max_sessions": float64(16)},
+		"interface": map[string]interface{}{"theme": "default-dark"},
+	}, redacted["webserver"])
+	assert.Equal(t, map[string]interface{}{"log": Redacted}, redacted["files"])
+	assert.Nil(t, redacted["dhcp"])
+	assert.Equal(t, "$BALLOON-SHA256$hash", request.Config.Webserver["api"].(map[string]interface{})["pwhash"])
+}
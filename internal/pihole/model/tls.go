@@ -0,0 +1,24 @@
+package model
+
+import (
+	"errors"
+)
+
+type TLS struct {
+	// CAFile is a PEM bundle of the CAs trusted in addition to the system CAs.
+	CAFile string
+	// CertFile and KeyFile are the PEM client certificate and key for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name the server certificate is verified against.
+	ServerName string
+	// InsecureSkipVerify disables verification of the server certificate.
+	InsecureSkipVerify bool
+}
+
+func (t *TLS) Validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return errors.New("cert_file and key_file must be set together")
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package pihole
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetryDelay caps both the backoff and Retry-After delays.
+const maxRetryDelay = time.Minute
+
+type RetryOptions struct {
+	// MaxAttempts is the number of times a request is sent, 1 disables retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled on every further retry.
+	BaseDelay time.Duration
+	// Jitter randomizes each delay by up to this fraction of the delay, between 0 and 1.
+	Jitter float64
+}
+
+// retryPolicy decides which failures of a request are retried.
+type retryPolicy int
+
+const (
+	// retryIdempotent retries network errors, 429 and 5xx responses.
+	retryIdempotent retryPolicy = iota
+	// retryUnsent retries only when the request did not reach Pi-hole, because the connection could not be established.
+	retryUnsent
+)
+
+// do sends req, and retries it according to policy and client.retry, each attempt is limited by timeout if set.
+// It returns the body of a successful response, or the error of the last attempt.
+func (client *client) do(req *http.Request, policy retryPolicy, timeout time.Duration) ([]byte, error) {
+	attempts := max(client.retry.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		body, response, err := client.attempt(req, timeout)
+		if attempt >= attempts || req.Context().Err() != nil || !policy.retryable(response, err) || !rewind(req) {
+			if err != nil {
+				return nil, err
+			}
+			return body, client.responseError(req, response, body)
+		}
+
+		delay := client.retry.delay(attempt, response)
+		event := client.logger.Warn().Int("attempt", attempt).Int("attempts", attempts).Dur("delay", delay).Str("url", req.URL.String())
+		if err != nil {
+			event.Err(err).Msg("Request failed, retrying")
+		} else {
+			event.Int("status", response.StatusCode).Msg("Request failed, retrying")
+		}
+
+		if err := client.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends req once and reads the response body, within timeout if set.
+func (client *client) attempt(req *http.Request, timeout time.Duration) ([]byte, *http.Response, error) {
+	ctx := req.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	response, err := client.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	return body, response, err
+}
+
+// sleep waits for d, or returns the error of ctx if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (policy retryPolicy) retryable(response *http.Response, err error) bool {
+	if err != nil {
+		if policy == retryUnsent {
+			var opErr *net.OpError
+			return errors.As(err, &opErr) && opErr.Op == "dial"
+		}
+		return true
+	}
+
+	if policy == retryUnsent {
+		return false
+	}
+	return response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500
+}
+
+// rewind resets the body of req so that it can be sent again, it reports false if the body cannot be reset.
+func rewind(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return true
+	}
+	if req.GetBody == nil {
+		return false
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return false
+	}
+	req.Body = body
+	return true
+}
+
+// delay returns the delay before the retry following attempt, from the Retry-After header of response if set.
+func (retry RetryOptions) delay(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if delay, ok := retryAfter(response.Header.Get("Retry-After")); ok {
+			return min(delay, maxRetryDelay)
+		}
+	}
+
+	delay := retry.BaseDelay
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxRetryDelay)
+
+	if retry.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * retry.Jitter * float64(delay))
+	}
+	return max(delay, 0)
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
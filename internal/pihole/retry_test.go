@@ -0,0 +1,201 @@
+package pihole
+
+import (
+	"context"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newRetryClient(t *testing.T, url string, attempts int) (*client, *[]time.Duration) {
+	t.Helper()
+	newClient, err := NewClient(model.NewPiHole(url, apiPassword), Options{
+		Retry: RetryOptions{MaxAttempts: attempts, BaseDelay: time.Second},
+	})
+	require.NoError(t, err)
+	c := newClient.(*client)
+	c.auth = auth{sid: "sid", valid: true}
+
+	var delays []time.Duration
+	c.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return c, &delays
+}
+
+func TestClient_retry(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"groups": []}`))
+	}))
+	defer server.Close()
+
+	c, delays := newRetryClient(t, server.URL, 3)
+	_, err := c.GetGroups(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *delays)
+}
+
+func TestClient_retry_exhausted(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	c, _ := newRetryClient(t, server.URL, 2)
+	_, err := c.GetConfig(context.Background())
+
+	assert.ErrorContains(t, err, "unexpected status code: 502")
+	assert.Equal(t, 2, requests)
+}
+
+func TestClient_retry_body(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	c, _ := newRetryClient(t, server.URL, 3)
+	err := c.PatchConfig(context.Background(), &model.PatchConfigRequest{})
+
+	require.NoError(t, err)
+	require.Len(t, bodies, 2)
+	assert.Equal(t, bodies[0], bodies[1])
+}
+
+func TestClient_retry_clientError(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	c, _ := newRetryClient(t, server.URL, 3)
+	_, err := c.GetVersion(context.Background())
+
+	assert.ErrorContains(t, err, "unexpected status code: 400")
+	assert.Equal(t, 1, requests)
+}
+
+func TestClient_retry_postTeleporter(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c, _ := newRetryClient(t, server.URL, 3)
+	err := c.PostTeleporter(context.Background(), []byte("zip"), nil)
+
+	assert.ErrorContains(t, err, "unexpected status code: 503")
+	assert.Equal(t, 1, requests)
+}
+
+func TestClient_retry_postTeleporter_unsent(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	c, delays := newRetryClient(t, url, 3)
+	err := c.PostTeleporter(context.Background(), []byte("zip"), nil)
+
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Len(t, *delays, 2)
+}
+
+func TestClient_retry_authRateLimited(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"session": {"valid": false, "message": "rate-limiting"}}`))
+	}))
+	defer server.Close()
+
+	c, delays := newRetryClient(t, server.URL, 3)
+	err := c.Authenticate(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, requests)
+	assert.Empty(t, *delays)
+}
+
+func TestClient_timeout(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(100 * time.Millisecond):
+		}
+	}))
+	defer server.Close()
+
+	c, _ := newRetryClient(t, server.URL, 2)
+	c.timeouts.Request = 10 * time.Millisecond
+	_, err := c.GetConfig(context.Background())
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestClient_cancel(t *testing.T) {
+	var requests atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	c, _ := newRetryClient(t, server.URL, 3)
+	_, err := c.GetTeleporter(ctx)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestRetryOptions_delay(t *testing.T) {
+	retry := RetryOptions{BaseDelay: time.Second}
+
+	assert.Equal(t, time.Second, retry.delay(1, nil))
+	assert.Equal(t, 4*time.Second, retry.delay(3, nil))
+	assert.Equal(t, maxRetryDelay, retry.delay(20, nil))
+
+	response := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+	assert.Equal(t, 7*time.Second, retry.delay(1, response))
+
+	response.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.Equal(t, maxRetryDelay, retry.delay(1, response))
+
+	retry.Jitter = 0.5
+	for range 10 {
+		delay := retry.delay(2, nil)
+		assert.GreaterOrEqual(t, delay, time.Second)
+		assert.LessOrEqual(t, delay, 3*time.Second)
+	}
+}
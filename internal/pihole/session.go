@@ -0,0 +1,207 @@
+package pihole
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/lovelaze/nebula-sync/internal/fsutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxRenewMargin is how long before it expires a session is renewed, at most half of its validity.
+const maxRenewMargin = time.Minute
+
+// SessionOptions configures how sessions are reused across syncs.
+type SessionOptions struct {
+	// Reuse keeps the session when DeleteSession is called, so that the next sync does not log in again.
+	// The session is deleted by Close.
+	Reuse bool
+	// Dir persists reused sessions across runs, in memory only if empty.
+	Dir string
+}
+
+type auth struct {
+	sid      string
+	csrf     string
+	validity int
+	valid    bool
+	// expires is when the session expires unless it is used, zero if unknown.
+	expires time.Time
+}
+
+func (a *auth) verify() error {
+	if !a.valid {
+		return errors.New("invalid sid found")
+	}
+
+	return nil
+}
+
+// usable reports whether the session is valid and does not need to be renewed at now.
+func (a *auth) usable(now time.Time) bool {
+	if !a.valid || a.sid == "" {
+		return false
+	}
+	if a.expires.IsZero() {
+		return true
+	}
+	margin := min(maxRenewMargin, time.Duration(a.validity)*time.Second/2)
+	return now.Add(margin).Before(a.expires)
+}
+
+// touch extends the session, Pi-hole restarts the validity of a session whenever it is used.
+func (a *auth) touch(now time.Time) {
+	if a.validity > 0 {
+		a.expires = now.Add(time.Duration(a.validity) * time.Second)
+	}
+}
+
+// persistedSession is the session of a Pi-hole as stored in SessionOptions.Dir.
+type persistedSession struct {
+	Sid      string    `json:"sid"`
+	Csrf     string    `json:"csrf"`
+	Validity int       `json:"validity"`
+	Expires  time.Time `json:"expires"`
+}
+
+// session returns the id of a usable session, renewing the session if it is about to expire.
+func (client *client) session(ctx context.Context) (string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if err := client.auth.verify(); err != nil {
+		return "", err
+	}
+	if !client.auth.usable(client.now()) {
+		client.logger.Debug().Msg("Session about to expire, renewing")
+		if err := client.renew(ctx); err != nil {
+			return "", err
+		}
+	}
+	return client.auth.sid, nil
+}
+
+// reauthenticate logs in again after Pi-hole rejected sid, unless another request already did.
+func (client *client) reauthenticate(ctx context.Context, sid string) (string, error) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.auth.valid && client.auth.sid != sid {
+		return client.auth.sid, nil
+	}
+	client.logger.Info().Msg("Session rejected, authenticating again")
+	client.auth = auth{}
+	if err := client.login(ctx); err != nil {
+		return "", err
+	}
+	return client.auth.sid, nil
+}
+
+// used extends the session after a successful request with sid.
+func (client *client) used(sid string) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	if client.auth.sid == sid {
+		client.auth.touch(client.now())
+	}
+}
+
+// renew replaces the current session with a new one, the caller must hold mu.
+func (client *client) renew(ctx context.Context) error {
+	if client.auth.valid && client.auth.sid != "" {
+		if err := client.deleteSession(ctx); err != nil {
+			client.logger.Debug().Err(err).Msg("Failed to delete expiring session")
+		}
+	}
+	return client.login(ctx)
+}
+
+// restoreSession loads the persisted session and checks with Pi-hole that it is still valid, the caller must hold mu.
+func (client *client) restoreSession(ctx context.Context) bool {
+	filename := client.sessionFile()
+	if filename == "" {
+		return false
+	}
+
+	bytes, err := os.ReadFile(filename)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			client.logger.Warn().Err(err).Msg("Failed to read session")
+		}
+		return false
+	}
+	persisted := persistedSession{}
+	if err := json.Unmarshal(bytes, &persisted); err != nil {
+		client.logger.Warn().Err(err).Msg("Failed to parse session")
+		return false
+	}
+
+	restored := auth{sid: persisted.Sid, csrf: persisted.Csrf, validity: persisted.Validity, valid: true, expires: persisted.Expires}
+	if !restored.usable(client.now()) {
+		return false
+	}
+
+	req, err := client.newRequest(ctx, "GET", "auth", nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("sid", restored.sid)
+	if _, err := client.do(req, retryIdempotent, client.timeouts.Request); err != nil {
+		client.logger.Debug().Err(err).Msg("Persisted session rejected")
+		return false
+	}
+
+	restored.touch(client.now())
+	client.auth = restored
+	client.logger.Debug().Msg("Reusing persisted session")
+	return true
+}
+
+// saveSession persists the current session if SessionOptions.Dir is set, the caller must hold mu.
+func (client *client) saveSession() {
+	filename := client.sessionFile()
+	if filename == "" {
+		return
+	}
+
+	bytes, err := json.Marshal(persistedSession{
+		Sid:      client.auth.sid,
+		Csrf:     client.auth.csrf,
+		Validity: client.auth.validity,
+		Expires:  client.auth.expires,
+	})
+	if err == nil {
+		err = fsutil.WriteFileAtomic(filename, bytes)
+	}
+	if err != nil {
+		client.logger.Warn().Err(err).Msg("Failed to save session")
+	}
+}
+
+// removeSession removes the persisted session, the caller must hold mu.
+func (client *client) removeSession() {
+	filename := client.sessionFile()
+	if filename == "" {
+		return
+	}
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		client.logger.Warn().Err(err).Msg("Failed to remove session")
+	}
+}
+
+// sessionFile returns the file the session is persisted to, or an empty string if sessions are not persisted.
+func (client *client) sessionFile() string {
+	if !client.sessions.Reuse || client.sessions.Dir == "" {
+		return ""
+	}
+	return filepath.Join(client.sessions.Dir, fsutil.URLName(client.piHole.Url.String())+".json")
+}
+
+// isUnauthorized reports whether Pi-hole rejected the session of a request, as it does for expired or deleted sessions.
+func isUnauthorized(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Unauthorized()
+}
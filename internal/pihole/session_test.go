@@ -0,0 +1,183 @@
+package pihole
+
+import (
+	"context"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/fsutil"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAuth is a Pi-hole API that hands out sessions and rejects unknown ones.
+type fakeAuth struct {
+	mu       sync.Mutex
+	sessions map[string]bool
+	logins   int
+	logouts  int
+}
+
+func newFakeAuth(t *testing.T) (*fakeAuth, *httptest.Server) {
+	t.Helper()
+	fake := &fakeAuth{sessions: make(map[string]bool)}
+	server := httptest.NewServer(http.HandlerFunc(fake.serveHTTP))
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (fake *fakeAuth) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	sid := r.Header.Get("sid")
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/api/auth":
+		fake.logins++
+		sid = fmt.Sprintf("sid%d", fake.logins)
+		fake.sessions[sid] = true
+		_, _ = fmt.Fprintf(w, `{"session": {"valid": true, "sid": %q, "validity": 300}}`, sid)
+	case !fake.sessions[sid]:
+		w.WriteHeader(http.StatusUnauthorized)
+	case r.Method == "DELETE" && r.URL.Path == "/api/auth":
+		fake.logouts++
+		delete(fake.sessions, sid)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		_, _ = w.Write([]byte(`{"session": {"valid": true}, "version": {}}`))
+	}
+}
+
+func (fake *fakeAuth) expire(sid string) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	delete(fake.sessions, sid)
+}
+
+func newSessionClient(t *testing.T, url string, sessions SessionOptions) (*client, *time.Time) {
+	t.Helper()
+	newClient, err := NewClient(model.NewPiHole(url, apiPassword), Options{Session: sessions})
+	require.NoError(t, err)
+	c := newClient.(*client)
+
+	now := time.Unix(1_700_000_000, 0)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestClient_session_reuse(t *testing.T) {
+	fake, server := newFakeAuth(t)
+	c, _ := newSessionClient(t, server.URL, SessionOptions{Reuse: true})
+	ctx := context.Background()
+
+	for range 3 {
+		require.NoError(t, c.Authenticate(ctx))
+		_, err := c.GetVersion(ctx)
+		require.NoError(t, err)
+		require.NoError(t, c.DeleteSession(ctx))
+	}
+	assert.Equal(t, 1, fake.logins)
+	assert.Equal(t, 0, fake.logouts)
+
+	require.NoError(t, c.Close(ctx))
+	assert.Equal(t, 1, fake.logouts)
+	assert.Empty(t, fake.sessions)
+}
+
+func TestClient_session_noReuse(t *testing.T) {
+	fake, server := newFakeAuth(t)
+	c, _ := newSessionClient(t, server.URL, SessionOptions{})
+	ctx := context.Background()
+
+	for range 2 {
+		require.NoError(t, c.Authenticate(ctx))
+		require.NoError(t, c.DeleteSession(ctx))
+	}
+	assert.Equal(t, 2, fake.logins)
+	assert.Equal(t, 2, fake.logouts)
+
+	require.NoError(t, c.Close(ctx))
+	assert.Equal(t, 2, fake.logouts)
+}
+
+func TestClient_session_renew(t *testing.T) {
+	fake, server := newFakeAuth(t)
+	c, now := newSessionClient(t, server.URL, SessionOptions{Reuse: true})
+	ctx := context.Background()
+	require.NoError(t, c.Authenticate(ctx))
+
+	// Using the session extends it.
+	*now = now.Add(3 * time.Minute)
+	_, err := c.GetVersion(ctx)
+	require.NoError(t, err)
+	*now = now.Add(3 * time.Minute)
+	_, err = c.GetVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fake.logins)
+
+	// Within a minute of expiring, the session is replaced.
+	*now = now.Add(4*time.Minute + 30*time.Second)
+	_, err = c.GetVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.logins)
+	assert.Equal(t, 1, fake.logouts)
+	assert.Equal(t, "sid2", c.auth.sid)
+
+	// An expired session is replaced when authenticating.
+	*now = now.Add(time.Hour)
+	require.NoError(t, c.Authenticate(ctx))
+	assert.Equal(t, 3, fake.logins)
+}
+
+func TestClient_session_unauthorized(t *testing.T) {
+	fake, server := newFakeAuth(t)
+	c, _ := newSessionClient(t, server.URL, SessionOptions{Reuse: true})
+	ctx := context.Background()
+	require.NoError(t, c.Authenticate(ctx))
+
+	fake.expire("sid1")
+	require.NoError(t, c.PatchConfig(ctx, &model.PatchConfigRequest{}))
+	assert.Equal(t, 2, fake.logins)
+	assert.Equal(t, "sid2", c.auth.sid)
+}
+
+func TestClient_session_persisted(t *testing.T) {
+	fake, server := newFakeAuth(t)
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	first, _ := newSessionClient(t, server.URL, SessionOptions{Reuse: true, Dir: dir})
+	require.NoError(t, first.Authenticate(ctx))
+	require.NoError(t, first.DeleteSession(ctx))
+	assert.FileExists(t, filepath.Join(dir, fsutil.URLName(first.piHole.Url.String())+".json"))
+
+	second, _ := newSessionClient(t, server.URL, SessionOptions{Reuse: true, Dir: dir})
+	require.NoError(t, second.Authenticate(ctx))
+	assert.Equal(t, 1, fake.logins)
+	assert.Equal(t, "sid1", second.auth.sid)
+
+	// A persisted session that Pi-hole no longer knows is replaced.
+	fake.expire("sid1")
+	third, _ := newSessionClient(t, server.URL, SessionOptions{Reuse: true, Dir: dir})
+	require.NoError(t, third.Authenticate(ctx))
+	assert.Equal(t, 2, fake.logins)
+
+	require.NoError(t, third.Close(ctx))
+	_, err := os.Stat(filepath.Join(dir, fsutil.URLName(third.piHole.Url.String())+".json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestClient_session_unauthenticated(t *testing.T) {
+	_, server := newFakeAuth(t)
+	c, _ := newSessionClient(t, server.URL, SessionOptions{Reuse: true})
+
+	_, err := c.GetVersion(context.Background())
+	assert.ErrorContains(t, err, "invalid sid found")
+	assert.NoError(t, c.Close(context.Background()))
+}
@@ -0,0 +1,96 @@
+package pihole
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTLSServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version": {}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0o600))
+	return server, caFile
+}
+
+func getVersion(t *testing.T, piHole model.PiHole) error {
+	t.Helper()
+	c, err := NewClient(piHole, Options{})
+	require.NoError(t, err)
+	c.(*client).auth = auth{sid: "sid", valid: true}
+
+	_, err = c.GetVersion(context.Background())
+	return err
+}
+
+func TestClient_tls(t *testing.T) {
+	server, caFile := newTLSServer(t)
+
+	piHole := model.NewPiHole(server.URL, apiPassword)
+	assert.ErrorContains(t, getVersion(t, piHole), "certificate")
+
+	piHole.TLS = model.TLS{CAFile: caFile}
+	assert.NoError(t, getVersion(t, piHole))
+
+	piHole.TLS = model.TLS{CAFile: caFile, ServerName: "ph1.lan"}
+	assert.ErrorContains(t, getVersion(t, piHole), "ph1.lan")
+
+	// The certificate of the test server is valid for example.com.
+	piHole.TLS = model.TLS{CAFile: caFile, ServerName: "example.com"}
+	assert.NoError(t, getVersion(t, piHole))
+
+	piHole.TLS = model.TLS{InsecureSkipVerify: true}
+	assert.NoError(t, getVersion(t, piHole))
+}
+
+func TestClient_tls_clientCertificate(t *testing.T) {
+	server, caFile := newTLSServer(t)
+	server.TLS.ClientAuth = tls.RequireAnyClientCert
+
+	piHole := model.NewPiHole(server.URL, apiPassword)
+	piHole.TLS = model.TLS{CAFile: caFile}
+	assert.Error(t, getVersion(t, piHole))
+
+	dir := t.TempDir()
+	certificate := server.TLS.Certificates[0]
+	piHole.TLS.CertFile = filepath.Join(dir, "client.pem")
+	piHole.TLS.KeyFile = filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(piHole.TLS.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Certificate[0]}), 0o600))
+	key, err := x509.MarshalPKCS8PrivateKey(certificate.PrivateKey)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(piHole.TLS.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600))
+	assert.NoError(t, getVersion(t, piHole))
+}
+
+func TestNewClient_tlsFiles(t *testing.T) {
+	piHole := model.NewPiHole("https://ph1.example.com", apiPassword)
+
+	piHole.TLS = model.TLS{CAFile: filepath.Join(t.TempDir(), "missing.pem")}
+	_, err := NewClient(piHole, Options{})
+	assert.ErrorContains(t, err, "read ca_file")
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, nil, 0o600))
+	piHole.TLS = model.TLS{CAFile: empty}
+	_, err = NewClient(piHole, Options{})
+	assert.ErrorContains(t, err, "no PEM certificates found")
+
+	piHole.TLS = model.TLS{CertFile: empty, KeyFile: empty}
+	_, err = NewClient(piHole, Options{})
+	assert.ErrorContains(t, err, "load client certificate")
+}
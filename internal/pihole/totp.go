@@ -0,0 +1,27 @@
+package pihole
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/binary"
+	"time"
+)
+
+const (
+	totpStep   = 30 * time.Second
+	totpDigits = 1_000_000
+)
+
+// totpCode generates the RFC 6238 code of key at t, with the HMAC-SHA1, 30 second and 6 digit settings used by Pi-hole.
+func totpCode(key []byte, t time.Time) int {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/int64(totpStep.Seconds())))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return int(code % totpDigits)
+}
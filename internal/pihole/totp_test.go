@@ -0,0 +1,17 @@
+package pihole
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTotpCode(t *testing.T) {
+	// Test vectors of RFC 6238, truncated to 6 digits.
+	key := []byte("12345678901234567890")
+
+	assert.Equal(t, 287082, totpCode(key, time.Unix(59, 0)))
+	assert.Equal(t, 81804, totpCode(key, time.Unix(1111111109, 0)))
+	assert.Equal(t, 5924, totpCode(key, time.Unix(1234567890, 0)))
+	assert.Equal(t, 279037, totpCode(key, time.Unix(2000000000, 0)))
+}
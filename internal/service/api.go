@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/lovelaze/nebula-sync/internal/sync"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const maxRequestSize = 1 << 20
+
+const (
+	modeFull   = "full"
+	modeManual = "manual"
+)
+
+// SyncRequest is the body of POST /api/v1/sync, all fields are optional.
+type SyncRequest struct {
+	// Mode is full or manual, the configured mode if empty.
+	Mode string `json:"mode,omitempty"`
+	// Config are the config sections a manual sync syncs.
+	Config []string `json:"config,omitempty"`
+	// Gravity are the gravity tables a manual sync syncs.
+	Gravity []string `json:"gravity,omitempty"`
+	// Replicas are the names or urls of the replicas to sync, all replicas if empty.
+	Replicas []string `json:"replicas,omitempty"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// syncPlan is what a sync job runs.
+type syncPlan struct {
+	job          *SyncJob
+	target       sync.Target
+	fullSync     bool
+	syncSettings *config.SyncSettings
+}
+
+func (service *Service) registerApi(mux *http.ServeMux) {
+	if service.conf.ApiToken == "" {
+		return
+	}
+
+	mux.HandleFunc("POST /api/v1/sync", service.authenticated(service.handleSync))
+	mux.HandleFunc("GET /api/v1/sync/{id}", service.authenticated(service.handleGetSync))
+	mux.HandleFunc("GET /api/v1/syncs", service.authenticated(service.handleListSyncs))
+}
+
+func (service *Service) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(service.conf.ApiToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, apiError{Error: "unauthorized"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (service *Service) handleSync(w http.ResponseWriter, r *http.Request) {
+	request := SyncRequest{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: fmt.Sprintf("invalid request: %s", err)})
+		return
+	}
+
+	if service.conf.DryRun {
+		writeJSON(w, http.StatusConflict, apiError{Error: "syncs are disabled with DRY_RUN"})
+		return
+	}
+
+	plan, err := service.newSyncPlan(request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: err.Error()})
+		return
+	}
+
+	switch service.conf.ApiSyncPolicy {
+	case config.SyncPolicyQueue:
+		plan.job = service.jobs.add(request, JobQueued)
+		if !service.enqueue(plan) {
+			service.finishJob(plan.job, nil, errors.New("sync queue is full"))
+			writeJSON(w, http.StatusServiceUnavailable, apiError{Error: "sync queue is full"})
+			return
+		}
+	default:
+		if !service.syncMu.TryLock() {
+			writeJSON(w, http.StatusConflict, apiError{Error: "a sync is already running"})
+			return
+		}
+		plan.job = service.jobs.add(request, JobRunning)
+		go func() {
+			defer service.syncMu.Unlock()
+			service.runJob(plan)
+		}()
+	}
+
+	job, _ := service.jobs.get(plan.job.ID)
+	w.Header().Set("Location", "/api/v1/sync/"+job.ID)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (service *Service) handleGetSync(w http.ResponseWriter, r *http.Request) {
+	job, found := service.jobs.get(r.PathValue("id"))
+	if !found {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "sync not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (service *Service) handleListSyncs(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, struct {
+		Syncs []SyncJob `json:"syncs"`
+	}{Syncs: service.jobs.list()})
+}
+
+func (service *Service) newSyncPlan(request SyncRequest) (*syncPlan, error) {
+	plan := &syncPlan{target: service.target}
+
+	switch request.Mode {
+	case "":
+		plan.fullSync = service.conf.FullSync
+	case modeFull:
+		plan.fullSync = true
+	case modeManual:
+		plan.fullSync = false
+	default:
+		return nil, fmt.Errorf("invalid mode %q, expected %q or %q", request.Mode, modeFull, modeManual)
+	}
+
+	filtered := len(request.Config) > 0 || len(request.Gravity) > 0
+	switch {
+	case plan.fullSync && filtered:
+		return nil, errors.New("config and gravity require manual mode")
+	case !plan.fullSync && filtered:
+		syncSettings, err := config.NewSyncSettings(request.Config, request.Gravity)
+		if err != nil {
+			return nil, err
+		}
+		if configured := service.conf.SyncSettings; configured != nil && configured.Config != nil {
+			syncSettings.Config.Exclude = configured.Config.Exclude
+			syncSettings.Config.Sensitive = configured.Config.Sensitive
+		}
+		plan.syncSettings = syncSettings
+	case !plan.fullSync:
+		if service.conf.SyncSettings == nil {
+			return nil, errors.New("manual mode requires config or gravity")
+		}
+		plan.syncSettings = service.conf.SyncSettings
+	}
+
+	if len(request.Replicas) > 0 {
+		target, err := service.target.WithReplicas(request.Replicas)
+		if err != nil {
+			return nil, err
+		}
+		plan.target = target
+	}
+
+	return plan, nil
+}
+
+// startQueue runs the syncs queued by enqueue one after another until ctx is cancelled, queued syncs that did not start
+// by then fail. The returned channel is closed once it stopped.
+func (service *Service) startQueue(ctx context.Context) <-chan struct{} {
+	service.jobs.queue = make(chan *syncPlan, jobQueueSize)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ctx.Done():
+				service.cancelQueued(ctx.Err())
+				return
+			case plan := <-service.jobs.queue:
+				service.syncMu.Lock()
+				if err := ctx.Err(); err != nil {
+					service.finishJob(plan.job, nil, err)
+				} else {
+					service.runJob(plan)
+				}
+				service.syncMu.Unlock()
+			}
+		}
+	}()
+	return stopped
+}
+
+// cancelQueued fails the syncs left in the queue with err.
+func (service *Service) cancelQueued(err error) {
+	for {
+		select {
+		case plan := <-service.jobs.queue:
+			service.finishJob(plan.job, nil, err)
+		default:
+			return
+		}
+	}
+}
+
+// enqueue queues the plan to run after the running sync, it returns false if the queue is full or not started.
+func (service *Service) enqueue(plan *syncPlan) bool {
+	select {
+	case service.jobs.queue <- plan:
+		return true
+	default:
+		return false
+	}
+}
+
+// runJob runs the sync of the plan, the caller must hold syncMu.
+func (service *Service) runJob(plan *syncPlan) {
+	service.jobs.update(plan.job, func(job *SyncJob) {
+		now := time.Now()
+		job.State = JobRunning
+		job.StartedAt = &now
+	})
+
+	log.Info().Str("id", plan.job.ID).Msg("Running API sync")
+	result, err := service.runSync(service.context(), plan.target, plan.fullSync, plan.syncSettings)
+	if err != nil {
+		log.Error().Err(err).Str("id", plan.job.ID).Msg("API sync failed")
+	}
+	service.finishJob(plan.job, result, err)
+}
+
+func (service *Service) finishJob(job *SyncJob, result *sync.SyncResult, err error) {
+	service.jobs.update(job, func(job *SyncJob) {
+		now := time.Now()
+		job.FinishedAt = &now
+		job.Replicas = replicaStatuses(result)
+		if err != nil {
+			job.State = JobFailed
+			job.Error = err.Error()
+		} else {
+			job.State = JobSucceeded
+		}
+	})
+}
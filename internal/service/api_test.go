@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	syncmock "github.com/lovelaze/nebula-sync/internal/mocks/sync"
+	"github.com/lovelaze/nebula-sync/internal/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const apiToken = "secret"
+
+func apiRequest(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+apiToken)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func decodeJob(t *testing.T, response *httptest.ResponseRecorder) SyncJob {
+	job := SyncJob{}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &job))
+	return job
+}
+
+// waitJob polls the sync until it is finished.
+func waitJob(t *testing.T, handler http.Handler, id string) SyncJob {
+	var job SyncJob
+	require.Eventually(t, func() bool {
+		job = decodeJob(t, apiRequest(handler, http.MethodGet, "/api/v1/sync/"+id, ""))
+		return job.FinishedAt != nil
+	}, time.Second, 5*time.Millisecond)
+	return job
+}
+
+func newApiService(target sync.Target, conf config.Config) *Service {
+	conf.ApiToken = apiToken
+	if conf.ApiSyncPolicy == "" {
+		conf.ApiSyncPolicy = config.SyncPolicyReject
+	}
+	return &Service{target: target, conf: conf}
+}
+
+func TestApi_disabled(t *testing.T) {
+	service := Service{}
+
+	response := apiRequest(service.handler(), http.MethodPost, "/api/v1/sync", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestApi_unauthorized(t *testing.T) {
+	service := newApiService(syncmock.NewTarget(t), config.Config{})
+
+	for _, header := range []string{"", "Bearer wrong", apiToken} {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/syncs", nil)
+		request.Header.Set("Authorization", header)
+		response := httptest.NewRecorder()
+		service.handler().ServeHTTP(response, request)
+
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	}
+}
+
+func TestApi_sync(t *testing.T) {
+	target := syncmock.NewTarget(t)
+	target.EXPECT().FullSync(mock.Anything).Return(&sync.SyncResult{Replicas: []sync.ReplicaResult{{Replica: "http://ph2.example.com"}}}, nil)
+	service := newApiService(target, config.Config{FullSync: true})
+	handler := service.handler()
+
+	response := apiRequest(handler, http.MethodPost, "/api/v1/sync", "")
+	require.Equal(t, http.StatusAccepted, response.Code)
+	job := decodeJob(t, response)
+	assert.Equal(t, "/api/v1/sync/"+job.ID, response.Header().Get("Location"))
+
+	job = waitJob(t, handler, job.ID)
+	assert.Equal(t, JobSucceeded, job.State)
+	assert.Equal(t, []ReplicaStatus{{Replica: "http://ph2.example.com", Success: true, Duration: "0s"}}, job.Replicas)
+
+	response = apiRequest(handler, http.MethodGet, "/api/v1/syncs", "")
+	require.Equal(t, http.StatusOK, response.Code)
+	history := struct{ Syncs []SyncJob }{}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &history))
+	require.Len(t, history.Syncs, 1)
+	assert.Equal(t, job.ID, history.Syncs[0].ID)
+}
+
+func TestApi_sync_filters(t *testing.T) {
+	filtered := syncmock.NewTarget(t)
+	target := syncmock.NewTarget(t)
+	target.EXPECT().WithReplicas([]string{"ph2"}).Return(filtered, nil)
+
+	expected := &config.SyncSettings{
+		Config:  &config.ManualConfig{DNS: true, Webserver: true, Exclude: []string{"dns.interface"}, Sensitive: []string{"webserver.tls"}},
+		Gravity: &config.ManualGravity{Group: true},
+	}
+	filtered.EXPECT().ManualSync(mock.Anything, expected).Return(nil, errors.New("primary unreachable"))
+
+	service := newApiService(target, config.Config{
+		FullSync:     true,
+		SyncSettings: &config.SyncSettings{Config: &config.ManualConfig{Exclude: []string{"dns.interface"}, Sensitive: []string{"webserver.tls"}}},
+	})
+	handler := service.handler()
+
+	response := apiRequest(handler, http.MethodPost, "/api/v1/sync", `{"mode": "manual", "config": ["dns", "webserver"], "gravity": ["group"], "replicas": ["ph2"]}`)
+	require.Equal(t, http.StatusAccepted, response.Code)
+
+	job := waitJob(t, handler, decodeJob(t, response).ID)
+	assert.Equal(t, JobFailed, job.State)
+	assert.Equal(t, "primary unreachable", job.Error)
+	assert.Equal(t, SyncRequest{Mode: "manual", Config: []string{"dns", "webserver"}, Gravity: []string{"group"}, Replicas: []string{"ph2"}}, job.Request)
+}
+
+func TestApi_sync_invalid(t *testing.T) {
+	target := syncmock.NewTarget(t)
+	target.EXPECT().WithReplicas([]string{"ph9"}).Return(nil, errors.New("unknown replica ph9"))
+	service := newApiService(target, config.Config{FullSync: true})
+
+	tests := map[string]string{
+		`{"mode": "partial"}`:                   "invalid mode",
+		`{"config": ["dns"]}`:                   "require manual mode",
+		`{"mode": "manual"}`:                    "manual mode requires config or gravity",
+		`{"mode": "manual", "config": ["web"]}`: "unknown config section",
+		`{"replicas": ["ph9"]}`:                 "unknown replica ph9",
+		`{"sections": ["dns"]}`:                 "unknown field",
+	}
+
+	for body, expected := range tests {
+		response := apiRequest(service.handler(), http.MethodPost, "/api/v1/sync", body)
+		assert.Equal(t, http.StatusBadRequest, response.Code, body)
+		assert.Contains(t, response.Body.String(), expected, body)
+	}
+}
+
+func TestApi_sync_reject(t *testing.T) {
+	service := newApiService(syncmock.NewTarget(t), config.Config{FullSync: true})
+	service.syncMu.Lock()
+	defer service.syncMu.Unlock()
+
+	response := apiRequest(service.handler(), http.MethodPost, "/api/v1/sync", "")
+	assert.Equal(t, http.StatusConflict, response.Code)
+	assert.Contains(t, response.Body.String(), "a sync is already running")
+}
+
+func TestApi_sync_queue(t *testing.T) {
+	target := syncmock.NewTarget(t)
+	target.EXPECT().FullSync(mock.Anything).Return(&sync.SyncResult{}, nil).Twice()
+	service := newApiService(target, config.Config{FullSync: true, ApiSyncPolicy: config.SyncPolicyQueue})
+	handler := service.handler()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := service.startQueue(ctx)
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+
+	service.syncMu.Lock()
+	first := decodeJob(t, apiRequest(handler, http.MethodPost, "/api/v1/sync", ""))
+	second := decodeJob(t, apiRequest(handler, http.MethodPost, "/api/v1/sync", ""))
+	assert.Equal(t, JobQueued, first.State)
+	assert.Equal(t, JobQueued, second.State)
+	service.syncMu.Unlock()
+
+	assert.Equal(t, JobSucceeded, waitJob(t, handler, first.ID).State)
+	assert.Equal(t, JobSucceeded, waitJob(t, handler, second.ID).State)
+}
+
+func TestApi_sync_shutdownWaits(t *testing.T) {
+	release := make(chan struct{})
+	target := syncmock.NewTarget(t)
+	target.EXPECT().FullSync(mock.Anything).RunAndReturn(func(context.Context) (*sync.SyncResult, error) {
+		<-release
+		return &sync.SyncResult{}, nil
+	}).Once()
+	service := newApiService(target, config.Config{FullSync: true})
+
+	job := decodeJob(t, apiRequest(service.handler(), http.MethodPost, "/api/v1/sync", ""))
+	assert.Equal(t, JobRunning, job.State)
+
+	done := make(chan struct{})
+	go func() {
+		service.shutdown(context.Background(), false)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("shutdown did not wait for the running API sync")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not return after the API sync finished")
+	}
+	assert.Equal(t, JobSucceeded, waitJob(t, service.handler(), job.ID).State)
+}
+
+func TestApi_sync_queueStopped(t *testing.T) {
+	service := newApiService(syncmock.NewTarget(t), config.Config{FullSync: true, ApiSyncPolicy: config.SyncPolicyQueue})
+	handler := service.handler()
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := service.startQueue(ctx)
+
+	service.syncMu.Lock()
+	queued := decodeJob(t, apiRequest(handler, http.MethodPost, "/api/v1/sync", ""))
+	cancel()
+	service.syncMu.Unlock()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queue did not stop after the context was cancelled")
+	}
+
+	job := waitJob(t, handler, queued.ID)
+	assert.Equal(t, JobFailed, job.State)
+	assert.Equal(t, context.Canceled.Error(), job.Error)
+}
+
+func TestApi_sync_dryRun(t *testing.T) {
+	service := newApiService(syncmock.NewTarget(t), config.Config{FullSync: true, DryRun: true})
+
+	response := apiRequest(service.handler(), http.MethodPost, "/api/v1/sync", "")
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+func TestApi_getSync_notFound(t *testing.T) {
+	service := newApiService(syncmock.NewTarget(t), config.Config{})
+
+	response := apiRequest(service.handler(), http.MethodGet, "/api/v1/sync/unknown", "")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	gosync "sync"
+	"time"
+)
+
+const (
+	// jobHistorySize is the number of sync jobs kept for the API.
+	jobHistorySize = 50
+	// jobQueueSize is the number of sync jobs that can wait for a running sync.
+	jobQueueSize = 10
+)
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// SyncJob is a sync triggered through the API.
+type SyncJob struct {
+	ID         string          `json:"id"`
+	State      string          `json:"state"`
+	Request    SyncRequest     `json:"request"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Replicas   []ReplicaStatus `json:"replicas,omitempty"`
+}
+
+// jobs keeps the recent sync jobs, newest first, its zero value is ready to use.
+type jobs struct {
+	mu      gosync.Mutex
+	history []*SyncJob
+	queue   chan *syncPlan
+}
+
+func (j *jobs) add(request SyncRequest, state string) *SyncJob {
+	job := &SyncJob{
+		ID:        newJobID(),
+		State:     state,
+		Request:   request,
+		CreatedAt: time.Now(),
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.history = append([]*SyncJob{job}, j.history...)
+	if len(j.history) > jobHistorySize {
+		j.history = j.history[:jobHistorySize]
+	}
+	return job
+}
+
+// update changes a job while holding the lock, so that readers see consistent jobs.
+func (j *jobs) update(job *SyncJob, fn func(job *SyncJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(job)
+}
+
+func (j *jobs) get(id string) (SyncJob, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, job := range j.history {
+		if job.ID == id {
+			return *job, true
+		}
+	}
+	return SyncJob{}, false
+}
+
+func (j *jobs) list() []SyncJob {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	list := make([]SyncJob, len(j.history))
+	for i, job := range j.history {
+		list[i] = *job
+	}
+	return list
+}
+
+func newJobID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/metrics"
+	"github.com/rs/zerolog/log"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	readHeaderTimeout = 10 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+// startServer starts the HTTP server with the health, readiness, status and metrics endpoints in the background.
+func (service *Service) startServer() (*http.Server, error) {
+	listener, err := net.Listen("tcp", service.conf.ServerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("http server: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           service.handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().Err(err).Msg("HTTP server failed")
+		}
+	}()
+
+	log.Info().Str("addr", listener.Addr().String()).Msg("HTTP server started")
+	return server, nil
+}
+
+func (service *Service) stopServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to stop HTTP server")
+	}
+}
+
+func (service *Service) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", service.handleHealth)
+	mux.HandleFunc("GET /readyz", service.handleReady)
+	mux.HandleFunc("GET /status", service.handleStatus)
+	mux.Handle("GET /metrics", metrics.Handler())
+	service.registerApi(mux)
+	return mux
+}
+
+func (service *Service) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeText(w, http.StatusOK, "ok")
+}
+
+func (service *Service) handleReady(w http.ResponseWriter, _ *http.Request) {
+	if ready, reason := service.status.ready(service.conf.ReadyTolerance); !ready {
+		writeText(w, http.StatusServiceUnavailable, reason)
+		return
+	}
+	writeText(w, http.StatusOK, "ready")
+}
+
+func (service *Service) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, service.status.status(service.conf.ReadyTolerance))
+}
+
+func writeText(w http.ResponseWriter, code int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintln(w, text)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warn().Err(err).Msg("Failed to write response")
+	}
+}
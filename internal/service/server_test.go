@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	syncmock "github.com/lovelaze/nebula-sync/internal/mocks/sync"
+	"github.com/lovelaze/nebula-sync/internal/sync"
+	"github.com/lovelaze/nebula-sync/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func get(handler http.Handler, target string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	return recorder
+}
+
+func TestService_healthz(t *testing.T) {
+	service := Service{}
+
+	response := get(service.handler(), "/healthz")
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "ok\n", response.Body.String())
+}
+
+func TestService_readyz(t *testing.T) {
+	target := syncmock.NewTarget(t)
+	service := Service{
+		target: target,
+		conf:   config.Config{FullSync: true},
+	}
+	handler := service.handler()
+
+	response := get(handler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Equal(t, "config not loaded\n", response.Body.String())
+
+	service.status.setLoaded()
+	response = get(handler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Equal(t, "no sync completed\n", response.Body.String())
+
+	target.EXPECT().FullSync(mock.Anything).Return(nil, errors.New("primary unreachable")).Once()
+	require.Error(t, service.doSync(context.Background(), target))
+	response = get(handler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Equal(t, "last sync failed\n", response.Body.String())
+
+	target.EXPECT().FullSync(mock.Anything).Return(&sync.SyncResult{}, nil).Once()
+	require.NoError(t, service.doSync(context.Background(), target))
+	response = get(handler, "/readyz")
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestService_readyz_dryRun(t *testing.T) {
+	target := syncmock.NewTarget(t)
+	service := Service{
+		target: target,
+		conf:   config.Config{FullSync: true, DryRun: true, DryRunFormat: config.FormatText},
+	}
+	service.status.setLoaded()
+	handler := service.handler()
+
+	target.EXPECT().Diff(mock.Anything, (*config.SyncSettings)(nil)).Return(&sync.DiffResult{Replicas: []sync.ReplicaDiff{
+		{Replica: "http://ph2.example.com"},
+		{Replica: "http://ph3.example.com", Error: "connection refused"},
+	}}, nil).Once()
+	require.NoError(t, service.doSync(context.Background(), target))
+	response := get(handler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Equal(t, "last sync failed\n", response.Body.String())
+
+	lastRun := service.status.status(0).LastRun
+	require.NotNil(t, lastRun)
+	assert.Equal(t, "1 of 2 replica diffs failed", lastRun.Error)
+	assert.Equal(t, []ReplicaStatus{
+		{Replica: "http://ph2.example.com", Success: true},
+		{Replica: "http://ph3.example.com", Error: "connection refused"},
+	}, lastRun.Replicas)
+
+	target.EXPECT().Diff(mock.Anything, (*config.SyncSettings)(nil)).Return(&sync.DiffResult{Replicas: []sync.ReplicaDiff{
+		{Replica: "http://ph2.example.com"},
+	}}, nil).Once()
+	require.NoError(t, service.doSync(context.Background(), target))
+	response = get(handler, "/readyz")
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestService_readyz_tolerance(t *testing.T) {
+	service := Service{conf: config.Config{ReadyTolerance: time.Minute}}
+	service.status.setLoaded()
+	service.status.record(time.Now().Add(-2*time.Minute), &sync.SyncResult{}, nil)
+
+	response := get(service.handler(), "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	assert.Contains(t, response.Body.String(), "last successful sync 2m0s ago")
+
+	service.conf.ReadyTolerance = 5 * time.Minute
+	response = get(service.handler(), "/readyz")
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestService_status(t *testing.T) {
+	next := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	service := Service{}
+	service.status.setLoaded()
+	service.status.setNextRun(func() time.Time { return next })
+	service.status.record(start, &sync.SyncResult{
+		Duration: 3 * time.Second,
+		Replicas: []sync.ReplicaResult{
+			{Replica: "http://ph2.example.com", Duration: 2 * time.Second, Actions: []sync.ActionResult{
+				{Action: sync.ActionGravity, Duration: time.Second},
+				{Action: sync.ActionRestartDNS, Duration: time.Second, Err: errors.New("timeout")},
+			}},
+			{Replica: "http://ph3.example.com", Duration: time.Second, Err: errors.New("connection refused")},
+		},
+	}, errors.New("1 of 2 replicas failed"))
+
+	response := get(service.handler(), "/status")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/json", response.Header().Get("Content-Type"))
+
+	status := Status{}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &status))
+	assert.Equal(t, version.Version, status.Version)
+	assert.False(t, status.Ready)
+	assert.Equal(t, next, *status.NextRun)
+	require.NotNil(t, status.LastRun)
+	assert.Equal(t, start, status.LastRun.Time)
+	assert.Equal(t, "3s", status.LastRun.Duration)
+	assert.False(t, status.LastRun.Success)
+	assert.Equal(t, "1 of 2 replicas failed", status.LastRun.Error)
+	assert.Equal(t, []ReplicaStatus{
+		{Replica: "http://ph2.example.com", Success: true, Duration: "2s", Actions: []ActionStatus{
+			{Action: "gravity", Success: true, Duration: "1s"},
+			{Action: "restart_dns", Success: false, Duration: "1s", Error: "timeout"},
+		}},
+		{Replica: "http://ph3.example.com", Success: false, Duration: "1s", Error: "connection refused"},
+	}, status.LastRun.Replicas)
+}
+
+func TestService_startServer(t *testing.T) {
+	service := Service{conf: config.Config{ServerAddr: "127.0.0.1:0"}}
+
+	server, err := service.startServer()
+	require.NoError(t, err)
+	service.stopServer(server)
+}
@@ -1,75 +1,309 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/lovelaze/nebula-sync/internal/metrics"
+	"github.com/lovelaze/nebula-sync/internal/notify"
 	"github.com/lovelaze/nebula-sync/internal/pihole"
 	"github.com/lovelaze/nebula-sync/internal/sync"
 	"github.com/lovelaze/nebula-sync/version"
 	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
+	"io"
+	"os"
+	gosync "sync"
+	"time"
 )
 
 type Service struct {
 	target sync.Target
-	conf   config.Config
+	// clients are the Pi-hole clients of target, their sessions are closed when the service stops.
+	clients []pihole.Client
+	conf    config.Config
+	status  status
+	// syncMu is held while a sync runs, so that only one sync runs at a time.
+	syncMu   gosync.Mutex
+	jobs     jobs
+	notifier *notify.Dispatcher
+	// ctx is the context of Run, syncs started through the API run with it, so that they are cancelled on shutdown.
+	ctx context.Context
 }
 
-func Init() (*Service, error) {
+// Init loads the config from env vars and the config file, if configFile is not empty, and creates the service.
+func Init(configFile string) (*Service, error) {
 	conf := config.Config{}
-	if err := conf.Load(); err != nil {
+	if err := conf.LoadFile(configFile); err != nil {
 		return nil, err
 	}
 
-	primary := pihole.NewClient(conf.Primary)
+	clientOptions := pihole.Options{
+		Retry: pihole.RetryOptions{
+			MaxAttempts: conf.Retry.MaxAttempts,
+			BaseDelay:   conf.Retry.BaseDelay,
+			Jitter:      conf.Retry.Jitter,
+		},
+		Session: pihole.SessionOptions{
+			Reuse: conf.Session.Reuse,
+			Dir:   conf.Session.Dir,
+		},
+	}
+
+	clientOptions.Transport = metrics.Transport(conf.Primary.Url.String())
+	primaryClient, err := pihole.NewClient(conf.Primary, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("primary: %w", err)
+	}
+	primary := metrics.NewClient(primaryClient)
 	var replicas []pihole.Client
 	for _, replica := range conf.Replicas {
-		replicas = append(replicas, pihole.NewClient(replica))
+		clientOptions.Transport = metrics.Transport(replica.Url.String())
+		client, err := pihole.NewClient(replica, clientOptions)
+		if err != nil {
+			return nil, fmt.Errorf("replica: %w", err)
+		}
+		replicas = append(replicas, metrics.NewClient(client))
 	}
 
 	return &Service{
-		target: sync.NewTarget(primary, replicas),
-		conf:   conf,
+		target: metrics.NewTarget(sync.NewTarget(primary, replicas, sync.Options{
+			Concurrency:     conf.Concurrency,
+			FailFast:        conf.FailurePolicy == config.FailFast,
+			ChangeDetection: conf.ChangeDetect,
+			StateDir:        conf.StateDir,
+			Backup:          conf.Backup,
+			BackupDir:       conf.BackupDir,
+			BackupKeep:      conf.BackupKeep,
+			Verify:          conf.Verify,
+			GravityAPI:      conf.GravityStrategy == config.GravityAPI,
+		})),
+		clients:  append([]pihole.Client{primary}, replicas...),
+		conf:     conf,
+		notifier: notify.NewDispatcher(conf.Notify),
 	}, nil
 }
 
-func (service *Service) Run() error {
+// Run syncs once, or on the cron schedule until ctx is cancelled. Cancelling ctx cancels running syncs.
+func (service *Service) Run(ctx context.Context) error {
 	log.Info().Msgf("Starting nebula-sync %s", version.Version)
+	service.ctx = ctx
 	log.Debug().Str("config", service.conf.String()).Msgf("Settings")
+	service.status.setLoaded()
+	defer service.shutdown(ctx, service.conf.Cron == nil)
+
+	if service.conf.ServerAddr != "" {
+		if service.conf.ApiToken != "" && service.conf.ApiSyncPolicy == config.SyncPolicyQueue && !service.conf.DryRun {
+			// The queue stops once the server stopped, after a single run ctx is not cancelled.
+			queueCtx, stopQueue := context.WithCancel(ctx)
+			stopped := service.startQueue(queueCtx)
+			defer func() {
+				stopQueue()
+				<-stopped
+			}()
+		}
+
+		server, err := service.startServer()
+		if err != nil {
+			return err
+		}
+		defer service.stopServer(server)
+	}
 
 	if service.conf.Cron == nil {
-		return service.doSync(service.target)
+		return service.doSync(ctx, service.target)
 	} else {
-		return service.startCron(func() {
-			if err := service.doSync(service.target); err != nil {
+		if service.conf.BlockingSyncInterval > 0 && !service.conf.DryRun {
+			stopped := service.startBlockingSync(ctx, service.conf.BlockingSyncInterval)
+			defer func() { <-stopped }()
+		}
+		return service.startCron(ctx, func() {
+			if err := service.doSync(ctx, service.target); err != nil {
 				log.Error().Err(err).Msg("Sync failed")
 			}
 		})
 	}
 }
 
-func (service *Service) doSync(t sync.Target) (err error) {
-	if service.conf.FullSync {
-		err = t.FullSync()
+func (service *Service) doSync(ctx context.Context, t sync.Target) error {
+	service.syncMu.Lock()
+	defer service.syncMu.Unlock()
+
+	if service.conf.DryRun {
+		start := time.Now()
+		result, err := service.doDiff(ctx, t, os.Stdout, service.conf.DryRunFormat)
+		service.status.recordDiff(start, result, err)
+		return err
+	}
+
+	_, err := service.runSync(ctx, t, service.conf.FullSync, service.conf.SyncSettings)
+	return err
+}
+
+// startBlockingSync mirrors the blocking state of the primary to the replicas every interval until ctx is cancelled,
+// independent of the cron schedule. The returned channel is closed once it stopped.
+func (service *Service) startBlockingSync(ctx context.Context, interval time.Duration) <-chan struct{} {
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for ctx.Err() == nil {
+			if err := service.target.SyncBlocking(ctx); err != nil && ctx.Err() == nil {
+				log.Warn().Err(err).Msg("Blocking sync failed")
+			}
+			select {
+			case <-ctx.Done():
+			case <-ticker.C:
+			}
+		}
+	}()
+	return stopped
+}
+
+// shutdown waits for a running sync, such as an API sync that is not waited for otherwise, closes the sessions and waits
+// for the notifications to be sent. It runs once the server stopped, so that no sync starts after it.
+func (service *Service) shutdown(ctx context.Context, singleRun bool) {
+	service.syncMu.Lock()
+	service.closeSessions(ctx, singleRun)
+	service.syncMu.Unlock()
+	service.notifier.Wait()
+}
+
+// closeSessions deletes the sessions of all Pi-holes when the service stops. After a single run with persisted sessions,
+// they are kept for the next run instead.
+func (service *Service) closeSessions(ctx context.Context, singleRun bool) {
+	if singleRun && service.conf.Session.Reuse && service.conf.Session.Dir != "" {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for _, client := range service.clients {
+		if err := client.Close(ctx); err != nil {
+			log.Warn().Err(err).Str("client", client.String()).Msg("Failed to delete session")
+		}
+	}
+}
+
+// context returns the context of Run, or a background context if the service is not running.
+func (service *Service) context() context.Context {
+	if service.ctx == nil {
+		return context.Background()
+	}
+	return service.ctx
+}
+
+// runSync runs a sync and records its status, the caller must hold syncMu.
+func (service *Service) runSync(ctx context.Context, t sync.Target, fullSync bool, syncSettings *config.SyncSettings) (result *sync.SyncResult, err error) {
+	start := time.Now()
+	defer func() {
+		service.status.record(start, result, err)
+		service.notifier.SyncDone(syncMode(fullSync), result, err)
+	}()
+
+	if fullSync {
+		result, err = t.FullSync(ctx)
 	} else {
-		err = t.ManualSync(service.conf.SyncSettings)
+		result, err = t.ManualSync(ctx, syncSettings)
 	}
 
 	if err != nil {
-		return err
+		return result, err
 	}
 
-	log.Info().Msg("Sync complete")
+	if err = service.checkFailurePolicy(result); err != nil {
+		return result, err
+	}
+
+	log.Info().Int("replicas", len(result.Replicas)).Dur("duration", result.Duration).Msg("Sync complete")
+	return result, nil
+}
+
+// checkFailurePolicy returns an error if the failed replicas of result violate FAILURE_POLICY.
+func (service *Service) checkFailurePolicy(result *sync.SyncResult) error {
+	replicaErr := result.Err()
+	if replicaErr == nil {
+		return nil
+	}
+
+	failed, total := len(result.Failed()), len(result.Replicas)
+	if service.conf.FailurePolicy != config.FailQuorum {
+		return fmt.Errorf("%d of %d replicas failed: %w", failed, total, replicaErr)
+	}
+
+	// A sync of fewer replicas than the quorum, e.g. through the API, needs all of them to succeed.
+	quorum := min(service.conf.FailureQuorum, total)
+	if succeeded := len(result.Succeeded()); succeeded < quorum {
+		return fmt.Errorf("%d of %d replicas succeeded, quorum of %d not met: %w", succeeded, total, quorum, replicaErr)
+	}
+
+	log.Warn().Err(replicaErr).Int("failed", failed).Int("replicas", total).Int("quorum", quorum).Msg("Replicas failed, quorum met")
+	return nil
+}
+
+func syncMode(fullSync bool) string {
+	if fullSync {
+		return modeFull
+	}
+	return modeManual
+}
+
+// Diff writes the changes a sync would make to every replica, without changing anything.
+func (service *Service) Diff(ctx context.Context, w io.Writer, format string) error {
+	defer service.closeSessions(ctx, true)
+	_, err := service.doDiff(ctx, service.target, w, format)
 	return err
 }
 
-func (service *Service) startCron(cmd func()) error {
+// doDiff writes the changes a sync would make to w, and returns them along with any error.
+func (service *Service) doDiff(ctx context.Context, t sync.Target, w io.Writer, format string) (*sync.DiffResult, error) {
+	var syncSettings *config.SyncSettings
+	if !service.conf.FullSync {
+		syncSettings = service.conf.SyncSettings
+	}
+
+	result, err := t.Diff(ctx, syncSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, writeDiff(w, result, format)
+}
+
+func writeDiff(w io.Writer, result *sync.DiffResult, format string) error {
+	switch format {
+	case config.FormatJSON:
+		bytes, err := result.JSON()
+		if err != nil {
+			return fmt.Errorf("diff: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(bytes))
+		return err
+	case config.FormatText:
+		_, err := fmt.Fprint(w, result.String())
+		return err
+	default:
+		return config.ValidateFormat(format)
+	}
+}
+
+// startCron runs cmd on the cron schedule until ctx is cancelled, and waits for a running cmd to return.
+func (service *Service) startCron(ctx context.Context, cmd func()) error {
 	cron := cron.New()
 
-	if _, err := cron.AddFunc(*service.conf.Cron, cmd); err != nil {
+	id, err := cron.AddFunc(*service.conf.Cron, cmd)
+	if err != nil {
 		return fmt.Errorf("cron job: %w", err)
 	}
+	service.status.setNextRun(func() time.Time {
+		return cron.Entry(id).Next
+	})
+
+	cron.Start()
+	<-ctx.Done()
 
-	cron.Run()
+	log.Info().Msg("Shutting down")
+	<-cron.Stop().Done()
 	return nil
 }
@@ -1,11 +1,21 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"github.com/lovelaze/nebula-sync/internal/config"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
 	syncmock "github.com/lovelaze/nebula-sync/internal/mocks/sync"
+	"github.com/lovelaze/nebula-sync/internal/notify"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
 	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/lovelaze/nebula-sync/internal/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestRun_full(t *testing.T) {
@@ -18,17 +28,17 @@ func TestRun_full(t *testing.T) {
 	}
 
 	target := syncmock.NewTarget(t)
-	target.On("FullSync").Return(nil)
+	target.On("FullSync", mock.Anything).Return(&sync.SyncResult{}, nil)
 
 	service := Service{
 		target: target,
 		conf:   conf,
 	}
 
-	err := service.Run()
+	err := service.Run(context.Background())
 	require.NoError(t, err)
 
-	target.AssertCalled(t, "FullSync")
+	target.AssertCalled(t, "FullSync", mock.Anything)
 }
 
 func TestRun_manual(t *testing.T) {
@@ -41,15 +51,246 @@ func TestRun_manual(t *testing.T) {
 	}
 
 	target := syncmock.NewTarget(t)
-	target.On("ManualSync", (*config.SyncSettings)(nil)).Return(nil)
+	target.On("ManualSync", mock.Anything, (*config.SyncSettings)(nil)).Return(&sync.SyncResult{}, nil)
 
 	service := Service{
 		target: target,
 		conf:   conf,
 	}
 
-	err := service.Run()
+	err := service.Run(context.Background())
 	require.NoError(t, err)
 
-	target.AssertCalled(t, "ManualSync", (*config.SyncSettings)(nil))
+	target.AssertCalled(t, "ManualSync", mock.Anything, (*config.SyncSettings)(nil))
+}
+
+type slowNotifier struct {
+	events []notify.Event
+}
+
+func (n *slowNotifier) Notify(event notify.Event) error {
+	time.Sleep(50 * time.Millisecond)
+	n.events = append(n.events, event)
+	return nil
+}
+
+func (n *slowNotifier) String() string {
+	return "slow"
+}
+
+func TestRun_waitsForNotifications(t *testing.T) {
+	target := syncmock.NewTarget(t)
+	target.EXPECT().FullSync(mock.Anything).Return(&sync.SyncResult{}, nil)
+
+	notifier := &slowNotifier{}
+	service := Service{
+		target:   target,
+		conf:     config.Config{FullSync: true},
+		notifier: &notify.Dispatcher{},
+	}
+	service.notifier.Add(notifier, []string{config.NotifyAlways})
+
+	err := service.Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, notifier.events, 1)
+	assert.Equal(t, notify.EventSuccess, notifier.events[0].Event)
+}
+
+func TestRun_replicaFailure(t *testing.T) {
+	conf := config.Config{
+		Primary:      model.PiHole{},
+		Replicas:     []model.PiHole{},
+		FullSync:     true,
+		Cron:         nil,
+		SyncSettings: nil,
+	}
+
+	target := syncmock.NewTarget(t)
+	target.On("FullSync", mock.Anything).Return(&sync.SyncResult{
+		Replicas: []sync.ReplicaResult{
+			{Replica: "http://ph2.example.com", Err: errors.New("connection refused")},
+			{Replica: "http://ph3.example.com"},
+		},
+	}, nil)
+
+	service := Service{
+		target: target,
+		conf:   conf,
+	}
+
+	err := service.Run(context.Background())
+	require.ErrorContains(t, err, "1 of 2 replicas failed")
+}
+
+func TestRun_quorum(t *testing.T) {
+	tests := []struct {
+		name    string
+		quorum  int
+		wantErr string
+	}{
+		{name: "met", quorum: 2},
+		{name: "not met", quorum: 3, wantErr: "2 of 3 replicas succeeded, quorum of 3 not met"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := syncmock.NewTarget(t)
+			target.On("FullSync", mock.Anything).Return(&sync.SyncResult{
+				Replicas: []sync.ReplicaResult{
+					{Replica: "http://ph2.example.com", Err: errors.New("connection refused")},
+					{Replica: "http://ph3.example.com"},
+					{Replica: "http://ph4.example.com"},
+				},
+			}, nil)
+
+			service := Service{
+				target: target,
+				conf: config.Config{
+					FullSync:      true,
+					FailurePolicy: config.FailQuorum,
+					FailureQuorum: tt.quorum,
+				},
+			}
+
+			err := service.Run(context.Background())
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun_dryRun(t *testing.T) {
+	conf := config.Config{
+		Primary:      model.PiHole{},
+		Replicas:     []model.PiHole{},
+		FullSync:     true,
+		Cron:         nil,
+		SyncSettings: nil,
+		DryRun:       true,
+		DryRunFormat: config.FormatText,
+	}
+
+	target := syncmock.NewTarget(t)
+	target.On("Diff", mock.Anything, (*config.SyncSettings)(nil)).Return(&sync.DiffResult{}, nil)
+
+	service := Service{
+		target: target,
+		conf:   conf,
+	}
+
+	err := service.Run(context.Background())
+	require.NoError(t, err)
+
+	target.AssertNotCalled(t, "FullSync", mock.Anything)
+}
+
+func TestRun_cronShutdown(t *testing.T) {
+	cron := "* * * * *"
+	service := Service{
+		target: syncmock.NewTarget(t),
+		conf:   config.Config{Cron: &cron},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- service.Run(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+}
+
+func TestRun_blockingSync(t *testing.T) {
+	cron := "0 0 1 1 *"
+	target := syncmock.NewTarget(t)
+	service := Service{
+		target: target,
+		conf:   config.Config{Cron: &cron, BlockingSyncInterval: time.Millisecond},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	polls := 0
+	target.EXPECT().SyncBlocking(mock.Anything).RunAndReturn(func(context.Context) error {
+		polls++
+		if polls == 3 {
+			cancel()
+		}
+		return errors.New("connection refused")
+	})
+
+	require.NoError(t, service.Run(ctx))
+	assert.Equal(t, 3, polls)
+}
+
+func TestRun_closeSessions(t *testing.T) {
+	tests := []struct {
+		name    string
+		cron    bool
+		session config.Session
+		closed  bool
+	}{
+		{"single run", false, config.Session{Reuse: true}, true},
+		{"single run with persisted sessions", false, config.Session{Reuse: true, Dir: "/data/sessions"}, false},
+		{"cron with persisted sessions", true, config.Session{Reuse: true, Dir: "/data/sessions"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := config.Config{FullSync: true, Session: tt.session}
+			target := syncmock.NewTarget(t)
+			if tt.cron {
+				cron := "0 0 1 1 *"
+				conf.Cron = &cron
+			} else {
+				target.On("FullSync", mock.Anything).Return(&sync.SyncResult{}, nil)
+			}
+
+			client := piholemock.NewClient(t)
+			if tt.closed {
+				client.EXPECT().Close(mock.Anything).Return(nil)
+			}
+			service := Service{target: target, clients: []pihole.Client{client}, conf: conf}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.cron {
+				cancel()
+			}
+			defer cancel()
+			require.NoError(t, service.Run(ctx))
+		})
+	}
+}
+
+func TestService_Diff(t *testing.T) {
+	settings := &config.SyncSettings{Gravity: &config.ManualGravity{}, Config: &config.ManualConfig{DNS: true}}
+	conf := config.Config{
+		FullSync:     false,
+		SyncSettings: settings,
+	}
+
+	target := syncmock.NewTarget(t)
+	target.On("Diff", mock.Anything, settings).Return(&sync.DiffResult{Replicas: []sync.ReplicaDiff{
+		{Replica: "http://ph2.example.com", Changes: []sync.Change{
+			{Path: "dns.port", Type: sync.ChangeChanged, Old: 53, New: 5353},
+		}},
+	}}, nil)
+
+	service := Service{
+		target: target,
+		conf:   conf,
+	}
+
+	var out bytes.Buffer
+	err := service.Diff(context.Background(), &out, config.FormatJSON)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"replicas":[{"replica":"http://ph2.example.com","changes":[{"path":"dns.port","type":"changed","old":53,"new":5353}]}]}`, out.String())
 }
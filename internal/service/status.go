@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/sync"
+	"github.com/lovelaze/nebula-sync/version"
+	gosync "sync"
+	"time"
+)
+
+// Status is the state of the service as reported by /status.
+type Status struct {
+	Version string     `json:"version"`
+	Ready   bool       `json:"ready"`
+	LastRun *RunStatus `json:"lastRun,omitempty"`
+	NextRun *time.Time `json:"nextRun,omitempty"`
+}
+
+type RunStatus struct {
+	Time     time.Time       `json:"time"`
+	Duration string          `json:"duration"`
+	Success  bool            `json:"success"`
+	Error    string          `json:"error,omitempty"`
+	Replicas []ReplicaStatus `json:"replicas,omitempty"`
+}
+
+type ReplicaStatus struct {
+	Replica  string         `json:"replica"`
+	Success  bool           `json:"success"`
+	Duration string         `json:"duration,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Actions  []ActionStatus `json:"actions,omitempty"`
+}
+
+type ActionStatus struct {
+	Action   string `json:"action"`
+	Success  bool   `json:"success"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+// status keeps track of sync runs, its zero value is ready to use.
+type status struct {
+	mu          gosync.Mutex
+	loaded      bool
+	lastRun     *RunStatus
+	lastSuccess time.Time
+	nextRun     func() time.Time
+}
+
+func (s *status) setLoaded() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loaded = true
+}
+
+func (s *status) setNextRun(nextRun func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRun = nextRun
+}
+
+// record stores the outcome of a sync that started at start.
+func (s *status) record(start time.Time, result *sync.SyncResult, err error) {
+	run := &RunStatus{
+		Time:     start,
+		Duration: time.Since(start).String(),
+		Success:  err == nil,
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+
+	if result != nil {
+		run.Duration = result.Duration.String()
+		run.Replicas = replicaStatuses(result)
+	}
+	s.store(start, run)
+}
+
+// recordDiff stores the outcome of a DRY_RUN diff that started at start, it succeeds if every replica was compared.
+func (s *status) recordDiff(start time.Time, result *sync.DiffResult, err error) {
+	run := &RunStatus{
+		Time:     start,
+		Duration: time.Since(start).String(),
+	}
+
+	var failed int
+	if result != nil {
+		for _, replica := range result.Replicas {
+			run.Replicas = append(run.Replicas, ReplicaStatus{
+				Replica: replica.Replica,
+				Success: replica.Error == "",
+				Error:   replica.Error,
+			})
+			if replica.Error != "" {
+				failed++
+			}
+		}
+	}
+
+	switch {
+	case err != nil:
+		run.Error = err.Error()
+	case failed > 0:
+		run.Error = fmt.Sprintf("%d of %d replica diffs failed", failed, len(result.Replicas))
+	default:
+		run.Success = true
+	}
+	s.store(start, run)
+}
+
+func (s *status) store(start time.Time, run *RunStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRun = run
+	if run.Success {
+		s.lastSuccess = start
+	}
+}
+
+func replicaStatuses(result *sync.SyncResult) []ReplicaStatus {
+	if result == nil {
+		return nil
+	}
+
+	statuses := make([]ReplicaStatus, len(result.Replicas))
+	for i, replica := range result.Replicas {
+		statuses[i] = ReplicaStatus{
+			Replica:  replica.Replica,
+			Success:  replica.Success(),
+			Duration: replica.Duration.String(),
+		}
+		if replica.Err != nil {
+			statuses[i].Error = replica.Err.Error()
+		}
+		for _, action := range replica.Actions {
+			actionStatus := ActionStatus{
+				Action:   action.Action,
+				Success:  action.Success(),
+				Duration: action.Duration.String(),
+			}
+			if action.Err != nil {
+				actionStatus.Error = action.Err.Error()
+			}
+			statuses[i].Actions = append(statuses[i].Actions, actionStatus)
+		}
+	}
+	return statuses
+}
+
+// ready reports whether the config is loaded and the last sync succeeded, no longer than tolerance ago if it is not 0.
+func (s *status) ready(tolerance time.Duration) (bool, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case !s.loaded:
+		return false, "config not loaded"
+	case s.lastRun == nil:
+		return false, "no sync completed"
+	case !s.lastRun.Success:
+		return false, "last sync failed"
+	case tolerance > 0 && time.Since(s.lastSuccess) > tolerance:
+		return false, "last successful sync " + time.Since(s.lastSuccess).Truncate(time.Second).String() + " ago"
+	}
+	return true, ""
+}
+
+func (s *status) status(tolerance time.Duration) Status {
+	ready, _ := s.ready(tolerance)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		Version: version.Version,
+		Ready:   ready,
+	}
+	if s.lastRun != nil {
+		lastRun := *s.lastRun
+		status.LastRun = &lastRun
+	}
+	if s.nextRun != nil {
+		if next := s.nextRun(); !next.IsZero() {
+			status.NextRun = &next
+		}
+	}
+	return status
+}
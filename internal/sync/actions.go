@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"context"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// Post-sync actions, in the order they run.
+const (
+	ActionGravity      = "gravity"
+	ActionRestartDNS   = "restart_dns"
+	ActionFlushNetwork = "flush_network"
+	ActionFlushLogs    = "flush_logs"
+)
+
+// replicaChanges records what a sync changed on a replica, it decides which post-sync actions run in ActionChanged mode.
+type replicaChanges struct {
+	// lists is set if adlists were added, changed or removed, which takes a gravity run to apply.
+	lists bool
+	// config is set if the config of the replica changed.
+	config bool
+	// any is set if anything on the replica changed.
+	any bool
+}
+
+// imported records a teleporter import with teleporterRequest, nil meaning a full import.
+func (changes *replicaChanges) imported(teleporterRequest *model.PostTeleporterRequest) {
+	changes.any = true
+	if teleporterRequest == nil {
+		changes.lists = true
+		changes.config = true
+		return
+	}
+	changes.lists = changes.lists || teleporterRequest.Gravity.Adlist || teleporterRequest.Gravity.AdlistByGroup
+	changes.config = changes.config || teleporterRequest.Config
+}
+
+// patched records a config patch that changed the config.
+func (changes *replicaChanges) patched() {
+	changes.any = true
+	changes.config = true
+}
+
+func (changes *replicaChanges) merge(other replicaChanges) {
+	changes.lists = changes.lists || other.lists
+	changes.config = changes.config || other.config
+	changes.any = changes.any || other.any
+}
+
+type postSyncAction struct {
+	name    string
+	mode    string
+	changed bool
+	timeout time.Duration
+	run     func(ctx context.Context) error
+}
+
+// runActions runs the post-sync actions of the replica that are due after changes, each within its timeout.
+func runActions(ctx context.Context, replica pihole.Client, changes replicaChanges) []ActionResult {
+	postSync := replica.PiHole().PostSync
+	actions := []postSyncAction{
+		{ActionGravity, postSync.Gravity, changes.lists, postSync.GravityTimeout, func(ctx context.Context) error {
+			return replica.RunGravity(ctx, func(line string) {
+				log.Info().Str("replica", replica.String()).Str("action", ActionGravity).Msg(line)
+			})
+		}},
+		{ActionRestartDNS, postSync.RestartDNS, changes.config, postSync.Timeout, replica.RestartDNS},
+		{ActionFlushNetwork, postSync.FlushNetwork, changes.any, postSync.Timeout, replica.FlushNetwork},
+		{ActionFlushLogs, postSync.FlushLogs, changes.any, postSync.Timeout, replica.FlushLogs},
+	}
+
+	var results []ActionResult
+	for _, action := range actions {
+		if !action.due() {
+			continue
+		}
+		results = append(results, action.execute(ctx, replica))
+	}
+	return results
+}
+
+func (action *postSyncAction) due() bool {
+	switch action.mode {
+	case model.ActionAlways:
+		return true
+	case model.ActionChanged:
+		return action.changed
+	default:
+		return false
+	}
+}
+
+func (action *postSyncAction) execute(ctx context.Context, replica pihole.Client) ActionResult {
+	if action.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, action.timeout)
+		defer cancel()
+	}
+
+	log.Info().Str("replica", replica.String()).Str("action", action.name).Msg("Running post-sync action")
+	start := time.Now()
+	err := explain(action.run(ctx))
+	result := ActionResult{Action: action.name, Err: err, Duration: time.Since(start)}
+
+	if err != nil {
+		log.Error().Err(err).Str("replica", replica.String()).Str("action", action.name).Dur("duration", result.Duration).Msg("Post-sync action failed")
+	} else {
+		log.Info().Str("replica", replica.String()).Str("action", action.name).Dur("duration", result.Duration).Msg("Post-sync action done")
+	}
+	return result
+}
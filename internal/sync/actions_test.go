@@ -0,0 +1,104 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestReplicaChanges_imported(t *testing.T) {
+	changes := replicaChanges{}
+	changes.imported(&model.PostTeleporterRequest{Gravity: model.PostGravityRequest{Domainlist: true}})
+	assert.Equal(t, replicaChanges{any: true}, changes)
+
+	changes.imported(&model.PostTeleporterRequest{Gravity: model.PostGravityRequest{AdlistByGroup: true}})
+	assert.Equal(t, replicaChanges{lists: true, any: true}, changes)
+
+	changes = replicaChanges{}
+	changes.imported(nil)
+	assert.Equal(t, replicaChanges{lists: true, config: true, any: true}, changes)
+}
+
+func TestRunActions(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{PostSync: model.PostSync{
+		Gravity:      model.ActionChanged,
+		RestartDNS:   model.ActionAlways,
+		FlushNetwork: model.ActionNever,
+		FlushLogs:    model.ActionChanged,
+		Timeout:      time.Minute,
+	}})
+
+	replica.EXPECT().RestartDNS(mock.Anything).RunAndReturn(func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		return errors.New("connection reset")
+	}).Once()
+	replica.EXPECT().FlushLogs(mock.Anything).Return(nil).Once()
+
+	results := runActions(context.Background(), replica, replicaChanges{config: true, any: true})
+	require.Len(t, results, 2)
+	assert.Equal(t, ActionRestartDNS, results[0].Action)
+	assert.EqualError(t, results[0].Err, "connection reset")
+	assert.Equal(t, ActionFlushLogs, results[1].Action)
+	assert.True(t, results[1].Success())
+}
+
+func TestRunActions_gravity(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{PostSync: model.PostSync{Gravity: model.ActionChanged, RestartDNS: model.ActionChanged}})
+
+	replica.EXPECT().RunGravity(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, output func(string)) error {
+		output("[✓] Done.")
+		return nil
+	}).Once()
+
+	results := runActions(context.Background(), replica, replicaChanges{lists: true, any: true})
+	require.Len(t, results, 1)
+	assert.Equal(t, ActionGravity, results[0].Action)
+	assert.NoError(t, results[0].Err)
+
+	assert.Empty(t, runActions(context.Background(), replica, replicaChanges{}))
+}
+
+func TestTarget_ManualSync_gravityOnlyNoRestart(t *testing.T) {
+	primary := piholemock.NewClient(t)
+	replica := piholemock.NewClient(t)
+	target := NewTarget(primary, []pihole.Client{replica}, Options{})
+
+	settings := config.SyncSettings{
+		Gravity: &config.ManualGravity{Group: true},
+		Config:  &config.ManualConfig{},
+	}
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{PostSync: model.PostSync{RestartDNS: model.ActionChanged}})
+
+	primary.EXPECT().Authenticate(mock.Anything).Return(nil).Once()
+	primary.EXPECT().GetTeleporter(mock.Anything).Return([]byte{}, nil).Once()
+	primary.EXPECT().GetConfig(mock.Anything).Return(&model.ConfigResponse{Config: map[string]interface{}{
+		"dns": map[string]interface{}{"port": float64(53)},
+	}}, nil).Once()
+	primary.EXPECT().DeleteSession(mock.Anything).Return(nil).Once()
+
+	replica.EXPECT().Authenticate(mock.Anything).Return(nil).Once()
+	replica.EXPECT().PostTeleporter(mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	replica.EXPECT().DeleteSession(mock.Anything).Return(nil).Once()
+
+	result, err := target.ManualSync(context.Background(), &settings)
+	require.NoError(t, err)
+	require.NoError(t, result.Err())
+	assert.Empty(t, result.Replicas[0].Actions)
+	replica.AssertNotCalled(t, "PatchConfig", mock.Anything, mock.Anything)
+	replica.AssertNotCalled(t, "RestartDNS", mock.Anything)
+}
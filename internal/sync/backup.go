@@ -0,0 +1,119 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/fsutil"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	backupTimeFormat           = "20060102T150405.000000000Z"
+	defaultHealthCheckInterval = 2 * time.Second
+)
+
+var (
+	healthCheckAttempts = 5
+	healthCheckInterval = defaultHealthCheckInterval
+)
+
+// backupStore writes teleporter backups of replicas to dir, keeping the latest keep archives per replica.
+type backupStore struct {
+	dir  string
+	keep int
+}
+
+func (store *backupStore) save(replica string, archive []byte) (string, error) {
+	if store.dir == "" {
+		return "", nil
+	}
+
+	replicaDir := filepath.Join(store.dir, fsutil.URLName(replica))
+	if err := os.MkdirAll(replicaDir, 0o700); err != nil {
+		return "", fmt.Errorf("create backup dir: %w", err)
+	}
+
+	filename := filepath.Join(replicaDir, time.Now().UTC().Format(backupTimeFormat)+".zip")
+	if err := fsutil.WriteFileAtomic(filename, archive); err != nil {
+		return "", fmt.Errorf("write backup: %w", err)
+	}
+
+	if err := store.prune(replicaDir); err != nil {
+		log.Warn().Err(err).Str("replica", replica).Msg("Failed to prune backups")
+	}
+
+	return filename, nil
+}
+
+func (store *backupStore) prune(replicaDir string) error {
+	if store.keep <= 0 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(filepath.Join(replicaDir, "*.zip"))
+	if err != nil {
+		return err
+	}
+	if len(backups) <= store.keep {
+		return nil
+	}
+
+	sort.Strings(backups)
+	var errs []error
+	for _, backup := range backups[:len(backups)-store.keep] {
+		errs = append(errs, os.Remove(backup))
+	}
+	return errors.Join(errs...)
+}
+
+// backup fetches the current teleporter archive of a replica, so it can be restored if the import fails.
+func (target *target) backup(ctx context.Context, replica pihole.Client) ([]byte, error) {
+	archive, err := replica.GetTeleporter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get teleporter: %w", err)
+	}
+
+	filename, err := target.backups.save(replica.String(), archive)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug().Str("replica", replica.String()).Str("file", filename).Int("bytes", len(archive)).Msg("Backed up replica")
+	return archive, nil
+}
+
+// rollback imports the backup to the replica after err occurred, also if ctx was cancelled.
+func (target *target) rollback(ctx context.Context, replica pihole.Client, archive []byte, err error) error {
+	log.Warn().Err(err).Str("replica", replica.String()).Msg("Rolling back replica")
+
+	if rollbackErr := replica.PostTeleporter(context.WithoutCancel(ctx), archive, nil); rollbackErr != nil {
+		return errors.Join(err, fmt.Errorf("rollback: %w", rollbackErr))
+	}
+
+	return fmt.Errorf("%w (rolled back)", err)
+}
+
+// healthCheck verifies that the replica responds after an import, which may restart FTL.
+func healthCheck(ctx context.Context, replica pihole.Client) (err error) {
+	for attempt := 1; attempt <= healthCheckAttempts; attempt++ {
+		if _, err = replica.GetVersion(ctx); err == nil {
+			return nil
+		}
+
+		log.Debug().Err(err).Str("replica", replica.String()).Int("attempt", attempt).Msg("Health check failed")
+		if attempt < healthCheckAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(healthCheckInterval):
+			}
+		}
+	}
+	return err
+}
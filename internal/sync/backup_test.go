@@ -0,0 +1,113 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_backupStore_save(t *testing.T) {
+	dir := t.TempDir()
+	store := backupStore{dir: dir, keep: 2}
+
+	var files []string
+	for _, archive := range []string{"one", "two", "three"} {
+		filename, err := store.save("http://ph2.example.com:8080", []byte(archive))
+		require.NoError(t, err)
+		files = append(files, filename)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(dir, "ph2.example.com_8080", "*.zip"))
+	require.NoError(t, err)
+	assert.Equal(t, files[1:], backups)
+
+	content, err := os.ReadFile(files[2])
+	require.NoError(t, err)
+	assert.Equal(t, "three", string(content))
+}
+
+func Test_backupStore_save_noDir(t *testing.T) {
+	store := backupStore{}
+
+	filename, err := store.save("http://ph2.example.com", []byte("archive"))
+	assert.NoError(t, err)
+	assert.Empty(t, filename)
+}
+
+func Test_target_syncTeleporter_rollbackImport(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+
+	target := target{
+		Options: Options{Backup: true},
+		backups: &backupStore{},
+	}
+
+	teleporter := []byte("primary")
+	backup := []byte("replica")
+
+	replica.EXPECT().GetTeleporter(mock.Anything).Times(1).Return(backup, nil)
+	replica.EXPECT().PostTeleporter(mock.Anything, teleporter, (*model.PostTeleporterRequest)(nil)).Times(1).Return(errors.New("import failed"))
+	replica.EXPECT().PostTeleporter(mock.Anything, backup, (*model.PostTeleporterRequest)(nil)).Times(1).Return(nil)
+
+	_, err := target.syncTeleporter(context.Background(), replica, teleporter, nil, nil)
+	assert.EqualError(t, err, "import failed (rolled back)")
+}
+
+func Test_target_syncTeleporter_rollbackHealthCheck(t *testing.T) {
+	healthCheckAttempts = 2
+	healthCheckInterval = 0
+	t.Cleanup(func() {
+		healthCheckAttempts = 5
+		healthCheckInterval = defaultHealthCheckInterval
+	})
+
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+
+	target := target{
+		Options: Options{Backup: true},
+		backups: &backupStore{},
+	}
+
+	teleporter := []byte("primary")
+	backup := []byte("replica")
+
+	replica.EXPECT().GetTeleporter(mock.Anything).Times(1).Return(backup, nil)
+	replica.EXPECT().PostTeleporter(mock.Anything, teleporter, (*model.PostTeleporterRequest)(nil)).Times(1).Return(nil)
+	replica.EXPECT().GetVersion(mock.Anything).Times(2).Return(nil, errors.New("connection refused"))
+	replica.EXPECT().PostTeleporter(mock.Anything, backup, (*model.PostTeleporterRequest)(nil)).Times(1).Return(errors.New("still down"))
+
+	_, err := target.syncTeleporter(context.Background(), replica, teleporter, nil, nil)
+	assert.ErrorContains(t, err, "health check: connection refused")
+	assert.ErrorContains(t, err, "rollback: still down")
+}
+
+func Test_target_syncTeleporter_backup(t *testing.T) {
+	dir := t.TempDir()
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+
+	target := target{
+		Options: Options{Backup: true},
+		backups: &backupStore{dir: dir, keep: 1},
+	}
+
+	replica.EXPECT().GetTeleporter(mock.Anything).Times(1).Return([]byte("replica"), nil)
+	replica.EXPECT().PostTeleporter(mock.Anything, []byte("primary"), (*model.PostTeleporterRequest)(nil)).Times(1).Return(nil)
+	replica.EXPECT().GetVersion(mock.Anything).Times(1).Return(&model.VersionResponse{}, nil)
+
+	_, err := target.syncTeleporter(context.Background(), replica, []byte("primary"), nil, nil)
+	require.NoError(t, err)
+
+	backups, err := filepath.Glob(filepath.Join(dir, "ph2.example.com", "*.zip"))
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
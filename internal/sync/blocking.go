@@ -0,0 +1,78 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/rs/zerolog/log"
+	"math"
+	"time"
+)
+
+// blockingTimerTolerance is how far the timer of a replica may be off before it is set again. Timers drift apart by
+// the time between reading the primary and setting the replica, so they rarely match exactly.
+const blockingTimerTolerance = 5 * time.Second
+
+// SyncBlocking mirrors the blocking state of the primary, and the time left until it is toggled, to every replica.
+// Replicas that are already in the same state are not changed.
+func (target *target) SyncBlocking(ctx context.Context) error {
+	if err := target.Primary.Authenticate(ctx); err != nil {
+		return fmt.Errorf("primary: authenticate: %w", err)
+	}
+	primary, err := target.Primary.GetBlocking(ctx)
+	if deleteErr := target.Primary.DeleteSession(logoutContext(ctx)); deleteErr != nil {
+		log.Warn().Err(deleteErr).Str("client", target.Primary.String()).Msg("Failed to delete session")
+	}
+	if err != nil {
+		return fmt.Errorf("primary: get blocking: %w", err)
+	}
+	if primary.Blocking != model.BlockingEnabled && primary.Blocking != model.BlockingDisabled {
+		return fmt.Errorf("primary: blocking is %s", primary.Blocking)
+	}
+
+	var errs []error
+	for _, replica := range target.Replicas {
+		_, err := runReplica(ctx, replica, func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+			return nil, syncBlocking(ctx, replica, primary)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", replica.String(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func syncBlocking(ctx context.Context, replica pihole.Client, primary *model.Blocking) error {
+	current, err := replica.GetBlocking(ctx)
+	if err != nil {
+		return fmt.Errorf("get blocking: %w", err)
+	}
+	if blockingInSync(primary, current) {
+		return nil
+	}
+
+	request := &model.BlockingRequest{Blocking: primary.Blocking == model.BlockingEnabled, Timer: primary.Timer}
+	if _, err := replica.SetBlocking(ctx, request); err != nil {
+		return fmt.Errorf("set blocking: %w", err)
+	}
+
+	event := log.Info().Str("replica", replica.String()).Str("blocking", primary.Blocking)
+	if primary.Timer != nil {
+		event = event.Dur("timer", time.Duration(*primary.Timer*float64(time.Second)))
+	}
+	event.Msg("Blocking synced")
+	return nil
+}
+
+// blockingInSync reports whether replica is in the blocking state of primary, with a timer within blockingTimerTolerance.
+func blockingInSync(primary, replica *model.Blocking) bool {
+	if primary.Blocking != replica.Blocking {
+		return false
+	}
+	if primary.Timer == nil || replica.Timer == nil {
+		return primary.Timer == nil && replica.Timer == nil
+	}
+	return math.Abs(*primary.Timer-*replica.Timer) <= blockingTimerTolerance.Seconds()
+}
@@ -0,0 +1,74 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func timer(seconds float64) *float64 {
+	return &seconds
+}
+
+func TestBlockingInSync(t *testing.T) {
+	tests := []struct {
+		name     string
+		primary  model.Blocking
+		replica  model.Blocking
+		expected bool
+	}{
+		{"enabled", model.Blocking{Blocking: "enabled"}, model.Blocking{Blocking: "enabled"}, true},
+		{"state differs", model.Blocking{Blocking: "disabled"}, model.Blocking{Blocking: "enabled"}, false},
+		{"timer within tolerance", model.Blocking{Blocking: "disabled", Timer: timer(290)}, model.Blocking{Blocking: "disabled", Timer: timer(286.5)}, true},
+		{"timer differs", model.Blocking{Blocking: "disabled", Timer: timer(290)}, model.Blocking{Blocking: "disabled", Timer: timer(30)}, false},
+		{"timer missing", model.Blocking{Blocking: "disabled", Timer: timer(290)}, model.Blocking{Blocking: "disabled"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, blockingInSync(&tt.primary, &tt.replica))
+		})
+	}
+}
+
+func TestTarget_SyncBlocking(t *testing.T) {
+	primary := piholemock.NewClient(t)
+	changed := piholemock.NewClient(t)
+	unchanged := piholemock.NewClient(t)
+	failing := piholemock.NewClient(t)
+	target := NewTarget(primary, []pihole.Client{changed, unchanged, failing}, Options{})
+
+	for _, client := range []*piholemock.Client{primary, changed, unchanged, failing} {
+		client.EXPECT().Authenticate(mock.Anything).Return(nil).Once()
+		client.EXPECT().DeleteSession(mock.Anything).Return(nil).Once()
+	}
+	changed.EXPECT().String().Return("http://ph2.example.com")
+	failing.EXPECT().String().Return("http://ph4.example.com")
+
+	primary.EXPECT().GetBlocking(mock.Anything).Return(&model.Blocking{Blocking: "disabled", Timer: timer(290)}, nil).Once()
+	changed.EXPECT().GetBlocking(mock.Anything).Return(&model.Blocking{Blocking: "enabled"}, nil).Once()
+	changed.EXPECT().SetBlocking(mock.Anything, &model.BlockingRequest{Blocking: false, Timer: timer(290)}).
+		Return(&model.Blocking{Blocking: "disabled", Timer: timer(290)}, nil).Once()
+	unchanged.EXPECT().GetBlocking(mock.Anything).Return(&model.Blocking{Blocking: "disabled", Timer: timer(288)}, nil).Once()
+	failing.EXPECT().GetBlocking(mock.Anything).Return(nil, errors.New("connection refused")).Once()
+
+	err := target.SyncBlocking(context.Background())
+	assert.EqualError(t, err, "http://ph4.example.com: get blocking: connection refused")
+}
+
+func TestTarget_SyncBlocking_primaryFailed(t *testing.T) {
+	primary := piholemock.NewClient(t)
+	target := NewTarget(primary, []pihole.Client{piholemock.NewClient(t)}, Options{})
+
+	primary.EXPECT().Authenticate(mock.Anything).Return(nil).Once()
+	primary.EXPECT().GetBlocking(mock.Anything).Return(&model.Blocking{Blocking: "failed"}, nil).Once()
+	primary.EXPECT().DeleteSession(mock.Anything).Return(nil).Once()
+
+	err := target.SyncBlocking(context.Background())
+	assert.EqualError(t, err, "primary: blocking is failed")
+}
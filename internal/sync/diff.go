@@ -0,0 +1,228 @@
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/rs/zerolog/log"
+	"reflect"
+	"sort"
+	"strings"
+	gosync "sync"
+)
+
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeChanged ChangeType = "changed"
+)
+
+type Change struct {
+	Path string      `json:"path"`
+	Type ChangeType  `json:"type"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new"`
+}
+
+type ReplicaDiff struct {
+	Replica string   `json:"replica"`
+	Changes []Change `json:"changes"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type DiffResult struct {
+	Replicas []ReplicaDiff `json:"replicas"`
+}
+
+// Diff compares the config sections that a sync would patch with the current config of every replica.
+// A nil syncSettings compares the full config, as imported by a full sync. Nothing is written to the replicas.
+func (target *target) Diff(ctx context.Context, syncSettings *config.SyncSettings) (*DiffResult, error) {
+	log.Info().Int("replicas", len(target.Replicas)).Msg("Running diff")
+	if err := target.Primary.Authenticate(ctx); err != nil {
+		return nil, fmt.Errorf("authenticate: %w", explain(err))
+	}
+
+	configResponse, err := target.Primary.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get config: %w", explain(err))
+	}
+
+	var configRequest *model.PatchConfigRequest
+	if syncSettings != nil {
+		configRequest = createPatchConfigRequest(syncSettings.Config, configResponse)
+	}
+
+	var mu gosync.Mutex
+	changes := make(map[string][]Change, len(target.Replicas))
+	result := target.syncReplicas(ctx, func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+		desired, err := desiredConfig(replica.PiHole(), configResponse, configRequest)
+		if err != nil {
+			return nil, err
+		}
+
+		replicaConfig, err := replica.GetConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get config: %w", err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		changes[replica.String()] = diffConfig(desired, replicaConfig.Config)
+		return nil, nil
+	})
+
+	if err := target.Primary.DeleteSession(logoutContext(ctx)); err != nil {
+		return nil, fmt.Errorf("delete session: %w", err)
+	}
+
+	diffResult := DiffResult{}
+	for _, replica := range result.Replicas {
+		replicaDiff := ReplicaDiff{Replica: replica.Replica, Changes: changes[replica.Replica]}
+		if replicaDiff.Changes == nil {
+			replicaDiff.Changes = []Change{}
+		}
+		if replica.Err != nil {
+			replicaDiff.Error = replica.Err.Error()
+		}
+		diffResult.Replicas = append(diffResult.Replicas, replicaDiff)
+	}
+
+	return &diffResult, nil
+}
+
+// desiredConfig returns the config a sync would leave on the replica. If configRequest is nil, it is the full primary
+// config without model.SensitivePaths, which a full sync leaves host-specific unless overridden.
+func desiredConfig(piHole model.PiHole, configResponse *model.ConfigResponse, configRequest *model.PatchConfigRequest) (map[string]interface{}, error) {
+	var desired map[string]interface{}
+	if configRequest == nil {
+		bytes, err := json.Marshal(configResponse.Config)
+		if err != nil {
+			return nil, fmt.Errorf("marshal config: %w", err)
+		}
+		if err := json.Unmarshal(bytes, &desired); err != nil {
+			return nil, fmt.Errorf("unmarshal config: %w", err)
+		}
+		omitSensitive(nil, desired)
+	} else {
+		var err error
+		if desired, err = patchConfigSections(configRequest); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := overrideSections(desired, piHole); err != nil {
+		return nil, err
+	}
+	return desired, nil
+}
+
+// patchConfigSections converts a patch request to the generic shape returned by GetConfig, leaving out unselected sections.
+func patchConfigSections(patchRequest *model.PatchConfigRequest) (map[string]interface{}, error) {
+	bytes, err := json.Marshal(patchRequest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal patch config: %w", err)
+	}
+
+	sections := map[string]interface{}{}
+	if err := json.Unmarshal(bytes, &sections); err != nil {
+		return nil, fmt.Errorf("unmarshal patch config: %w", err)
+	}
+
+	for key, value := range sections {
+		if value == nil {
+			delete(sections, key)
+		}
+	}
+
+	return sections, nil
+}
+
+// diffConfig returns the leaves of desired that differ from current, sorted by path.
+func diffConfig(desired, current map[string]interface{}) []Change {
+	var changes []Change
+	diffValue(nil, desired, current, true, &changes)
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+func diffValue(path []string, desired, current interface{}, exists bool, changes *[]Change) {
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	currentMap, currentIsMap := current.(map[string]interface{})
+
+	if desiredIsMap && (currentIsMap || !exists) {
+		for key, value := range desiredMap {
+			currentValue, ok := currentMap[key]
+			diffValue(append(path[:len(path):len(path)], key), value, currentValue, ok, changes)
+		}
+		return
+	}
+
+	var change Change
+	if !exists {
+		change = Change{Path: strings.Join(path, "."), Type: ChangeAdded, New: desired}
+	} else if !reflect.DeepEqual(desired, current) {
+		change = Change{Path: strings.Join(path, "."), Type: ChangeChanged, Old: current, New: desired}
+	} else {
+		return
+	}
+
+	// Values of sensitive paths are compared, but not shown.
+	if model.IsSensitive(path) {
+		change.New = model.Redacted
+		if change.Type == ChangeChanged {
+			change.Old = model.Redacted
+		}
+	}
+	*changes = append(*changes, change)
+}
+
+func (result *DiffResult) HasChanges() bool {
+	for _, replica := range result.Replicas {
+		if len(replica.Changes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (result *DiffResult) JSON() ([]byte, error) {
+	return json.MarshalIndent(result, "", "  ")
+}
+
+func (result *DiffResult) String() string {
+	var sb strings.Builder
+	for _, replica := range result.Replicas {
+		switch {
+		case replica.Error != "":
+			fmt.Fprintf(&sb, "%s: error: %s\n", replica.Replica, replica.Error)
+		case len(replica.Changes) == 0:
+			fmt.Fprintf(&sb, "%s: no changes\n", replica.Replica)
+		default:
+			fmt.Fprintf(&sb, "%s: %d changes\n", replica.Replica, len(replica.Changes))
+		}
+
+		for _, change := range replica.Changes {
+			switch change.Type {
+			case ChangeAdded:
+				fmt.Fprintf(&sb, "  + %s: %s\n", change.Path, formatValue(change.New))
+			default:
+				fmt.Fprintf(&sb, "  ~ %s: %s -> %s\n", change.Path, formatValue(change.Old), formatValue(change.New))
+			}
+		}
+	}
+	return sb.String()
+}
+
+func formatValue(value interface{}) string {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(bytes)
+}
@@ -0,0 +1,191 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTarget_Diff(t *testing.T) {
+	primary := piholemock.NewClient(t)
+	replica := piholemock.NewClient(t)
+	failing := piholemock.NewClient(t)
+
+	target := NewTarget(primary, []pihole.Client{replica, failing}, Options{})
+
+	settings := config.SyncSettings{
+		Gravity: &config.ManualGravity{},
+		Config:  &config.ManualConfig{DNS: true},
+	}
+
+	primary.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	primary.EXPECT().GetConfig(mock.Anything).Times(1).Return(&model.ConfigResponse{Config: map[string]interface{}{
+		"dns":  map[string]interface{}{"upstreams": []interface{}{"1.1.1.1"}, "port": float64(53)},
+		"dhcp": map[string]interface{}{"active": true},
+	}}, nil)
+	primary.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{})
+	replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().GetConfig(mock.Anything).Times(1).Return(&model.ConfigResponse{Config: map[string]interface{}{
+		"dns":  map[string]interface{}{"upstreams": []interface{}{"8.8.8.8"}, "port": float64(53)},
+		"dhcp": map[string]interface{}{"active": false},
+	}}, nil)
+	replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	failing.EXPECT().String().Return("http://ph3.example.com")
+	failing.EXPECT().Authenticate(mock.Anything).Times(1).Return(errors.New("connection refused"))
+
+	result, err := target.Diff(context.Background(), &settings)
+	require.NoError(t, err)
+
+	require.Len(t, result.Replicas, 2)
+	assert.Equal(t, []Change{
+		{Path: "dns.upstreams", Type: ChangeChanged, Old: []interface{}{"8.8.8.8"}, New: []interface{}{"1.1.1.1"}},
+	}, result.Replicas[0].Changes)
+	assert.Empty(t, result.Replicas[0].Error)
+	assert.Empty(t, result.Replicas[1].Changes)
+	assert.Contains(t, result.Replicas[1].Error, "connection refused")
+	assert.True(t, result.HasChanges())
+}
+
+func TestTarget_Diff_sensitive(t *testing.T) {
+	tests := []struct {
+		name         string
+		syncSettings *config.SyncSettings
+		changes      []string
+	}{
+		{
+			// A nil syncSettings diffs the full config, as imported by a full sync.
+			name:    "full sync omits sensitive paths",
+			changes: []string{"dns.port"},
+		},
+		{
+			name: "manual sync redacts opted in paths",
+			syncSettings: &config.SyncSettings{Config: &config.ManualConfig{
+				DNS:       true,
+				Webserver: true,
+				Files:     true,
+				Sensitive: []string{"webserver.api.*", "webserver.tls.*", "files.*"},
+			}},
+			changes: []string{"dns.port", "files.gravity", "webserver.api.app_pwhash", "webserver.api.pwhash", "webserver.api.totp_secret", "webserver.tls.cert"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := piholemock.NewClient(t)
+			replica := piholemock.NewClient(t)
+			target := NewTarget(primary, []pihole.Client{replica}, Options{})
+
+			primary.EXPECT().Authenticate(mock.Anything).Return(nil)
+			primary.EXPECT().GetConfig(mock.Anything).Return(&model.ConfigResponse{Config: map[string]interface{}{
+				"dns": map[string]interface{}{"port": float64(5353)},
+				"webserver": map[string]interface{}{
+					"api": map[string]interface{}{"pwhash": "$BALLOON-SHA256$primary", "app_pwhash": "$BALLOON-SHA256$app", "totp_secret": "JBSWY3DPEHPK3PXP"},
+					"tls": map[string]interface{}{"cert": "/etc/pihole/primary.pem"},
+				},
+				"files": map[string]interface{}{"gravity": "/etc/pihole/gravity.db"},
+			}}, nil)
+			primary.EXPECT().DeleteSession(mock.Anything).Return(nil)
+
+			replica.EXPECT().String().Return("http://ph2.example.com")
+			replica.EXPECT().PiHole().Return(model.PiHole{})
+			replica.EXPECT().Authenticate(mock.Anything).Return(nil)
+			replica.EXPECT().GetConfig(mock.Anything).Return(&model.ConfigResponse{Config: map[string]interface{}{
+				"dns": map[string]interface{}{"port": float64(53)},
+				"webserver": map[string]interface{}{
+					"api": map[string]interface{}{"pwhash": "$BALLOON-SHA256$replica"},
+					"tls": map[string]interface{}{"cert": "/etc/pihole/replica.pem"},
+				},
+				"files": map[string]interface{}{"gravity": "/mnt/gravity.db"},
+			}}, nil)
+			replica.EXPECT().DeleteSession(mock.Anything).Return(nil)
+
+			result, err := target.Diff(context.Background(), tt.syncSettings)
+			require.NoError(t, err)
+
+			var paths []string
+			for _, change := range result.Replicas[0].Changes {
+				paths = append(paths, change.Path)
+			}
+			assert.Equal(t, tt.changes, paths)
+
+			bytes, err := result.JSON()
+			require.NoError(t, err)
+			for _, output := range []string{result.String(), string(bytes)} {
+				for _, secret := range []string{"BALLOON", "JBSWY3DPEHPK3PXP", ".pem", "gravity.db"} {
+					assert.NotContains(t, output, secret)
+				}
+			}
+		})
+	}
+}
+
+func Test_diffConfig(t *testing.T) {
+	desired := map[string]interface{}{
+		"dns": map[string]interface{}{
+			"hosts": []interface{}{"192.168.1.2 nas"},
+			"reply": map[string]interface{}{
+				"host": map[string]interface{}{"IPv4": "192.168.1.1"},
+			},
+			"port": float64(53),
+		},
+		"ntp": map[string]interface{}{"ipv4": map[string]interface{}{"active": true}},
+	}
+	current := map[string]interface{}{
+		"dns": map[string]interface{}{
+			"hosts": []interface{}{},
+			"reply": map[string]interface{}{
+				"host": map[string]interface{}{"IPv4": "192.168.1.1"},
+			},
+			"port":   float64(53),
+			"ignore": "me",
+		},
+	}
+
+	changes := diffConfig(desired, current)
+
+	assert.Equal(t, []Change{
+		{Path: "dns.hosts", Type: ChangeChanged, Old: []interface{}{}, New: []interface{}{"192.168.1.2 nas"}},
+		{Path: "ntp.ipv4.active", Type: ChangeAdded, New: true},
+	}, changes)
+}
+
+func TestDiffResult_String(t *testing.T) {
+	result := DiffResult{Replicas: []ReplicaDiff{
+		{Replica: "http://ph2.example.com", Changes: []Change{
+			{Path: "dns.port", Type: ChangeChanged, Old: float64(53), New: float64(5353)},
+			{Path: "ntp.ipv4.active", Type: ChangeAdded, New: true},
+		}},
+		{Replica: "http://ph3.example.com", Changes: []Change{}},
+		{Replica: "http://ph4.example.com", Changes: []Change{}, Error: "connection refused"},
+	}}
+
+	expected := "http://ph2.example.com: 2 changes\n" +
+		"  ~ dns.port: 53 -> 5353\n" +
+		"  + ntp.ipv4.active: true\n" +
+		"http://ph3.example.com: no changes\n" +
+		"http://ph4.example.com: error: connection refused\n"
+
+	assert.Equal(t, expected, result.String())
+}
+
+func Test_patchConfigSections(t *testing.T) {
+	sections, err := patchConfigSections(&model.PatchConfigRequest{Config: model.PatchConfig{
+		DNS: map[string]interface{}{"port": float64(53)},
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"dns": map[string]interface{}{"port": float64(53)},
+	}, sections)
+}
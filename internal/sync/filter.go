@@ -0,0 +1,120 @@
+package sync
+
+import (
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"slices"
+	"strings"
+)
+
+// pathFilter selects config leaves by dot-path patterns such as dns.*, !dns.interface or dhcp.hosts.
+// A pattern matches a path if it matches its leading segments, so dns.* also matches dns.reply.host.IPv4.
+// Excludes, including include patterns prefixed with !, take precedence over includes.
+// Protected paths are left out like excludes, unless an allowed pattern matches them.
+type pathFilter struct {
+	sections  []string
+	include   [][]string
+	exclude   [][]string
+	protected [][]string
+	allowed   [][]string
+}
+
+func newPathFilter(sections, include, exclude []string) *pathFilter {
+	filter := &pathFilter{sections: sections}
+
+	for _, pattern := range include {
+		if excluded, found := strings.CutPrefix(pattern, "!"); found {
+			filter.exclude = append(filter.exclude, strings.Split(excluded, "."))
+		} else {
+			filter.include = append(filter.include, strings.Split(pattern, "."))
+		}
+	}
+	for _, pattern := range exclude {
+		filter.exclude = append(filter.exclude, strings.Split(strings.TrimPrefix(pattern, "!"), "."))
+	}
+
+	return filter
+}
+
+// protect leaves out the model.SensitivePaths, other than those matched by one of the allowed patterns.
+func (filter *pathFilter) protect(allowed []string) *pathFilter {
+	filter.protected = splitPatterns(model.SensitivePaths)
+	filter.allowed = splitPatterns(allowed)
+	return filter
+}
+
+func (filter *pathFilter) selected(segments []string) bool {
+	for _, pattern := range filter.exclude {
+		if model.MatchPath(pattern, segments) {
+			return false
+		}
+	}
+	if filter.isProtected(segments) {
+		return false
+	}
+
+	if slices.Contains(filter.sections, segments[0]) {
+		return true
+	}
+
+	for _, pattern := range filter.include {
+		if model.MatchPath(pattern, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterSection returns the selected leaves of a config section, or nil if none are selected.
+func (filter *pathFilter) filterSection(section string, values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	if slices.Contains(filter.sections, section) && len(filter.exclude) == 0 && !filter.protects(section) {
+		return values
+	}
+
+	filtered := filter.filterMap([]string{section}, values)
+	if len(filtered) == 0 {
+		return nil
+	}
+	return filtered
+}
+
+func (filter *pathFilter) filterMap(prefix []string, values map[string]interface{}) map[string]interface{} {
+	filtered := map[string]interface{}{}
+	for key, value := range values {
+		segments := append(prefix[:len(prefix):len(prefix)], key)
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			if nestedFiltered := filter.filterMap(segments, nested); len(nestedFiltered) > 0 {
+				filtered[key] = nestedFiltered
+			}
+		} else if filter.selected(segments) {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+func (filter *pathFilter) isProtected(segments []string) bool {
+	for _, pattern := range filter.protected {
+		if model.MatchPath(pattern, segments) {
+			return !slices.ContainsFunc(filter.allowed, func(allowed []string) bool { return model.MatchPath(allowed, segments) })
+		}
+	}
+	return false
+}
+
+// protects reports whether paths of section may be protected, so that the section cannot be synced as a whole.
+func (filter *pathFilter) protects(section string) bool {
+	return slices.ContainsFunc(filter.protected, func(pattern []string) bool { return model.MatchPath(pattern[:1], []string{section}) })
+}
+
+func splitPatterns(patterns []string) [][]string {
+	split := make([][]string, len(patterns))
+	for i, pattern := range patterns {
+		split[i] = strings.Split(pattern, ".")
+	}
+	return split
+}
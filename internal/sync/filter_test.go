@@ -0,0 +1,98 @@
+package sync
+
+import (
+	"github.com/lovelaze/nebula-sync/internal/config"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_pathFilter_selected(t *testing.T) {
+	filter := newPathFilter([]string{"ntp"}, []string{"dns.*", "!dns.interface", "dhcp.host?"}, []string{"ntp.sync.rtc"})
+
+	assert.True(t, filter.selected([]string{"dns", "hosts"}))
+	assert.True(t, filter.selected([]string{"dns", "reply", "host", "IPv4"}))
+	assert.False(t, filter.selected([]string{"dns", "interface"}))
+	assert.True(t, filter.selected([]string{"dhcp", "hosts"}))
+	assert.False(t, filter.selected([]string{"dhcp", "active"}))
+	assert.True(t, filter.selected([]string{"ntp", "ipv4", "active"}))
+	assert.False(t, filter.selected([]string{"ntp", "sync", "rtc", "set"}))
+	assert.False(t, filter.selected([]string{"misc", "etc_dnsmasq_d"}))
+}
+
+func Test_createPatchConfigRequest_patterns(t *testing.T) {
+	configResponse := model.ConfigResponse{Config: map[string]interface{}{
+		"dns": map[string]interface{}{
+			"hosts":        []interface{}{"192.168.1.2 nas"},
+			"cnameRecords": []interface{}{},
+			"interface":    "eth0",
+			"reply": map[string]interface{}{
+				"host": map[string]interface{}{"IPv4": "192.168.1.1", "force4": false},
+			},
+		},
+		"dhcp": map[string]interface{}{"active": true, "hosts": []interface{}{}},
+		"ntp":  map[string]interface{}{"ipv4": map[string]interface{}{"active": true}},
+	}}
+
+	request := createPatchConfigRequest(&config.ManualConfig{
+		NTP:     true,
+		Include: []string{"dns.*", "dhcp.hosts"},
+		Exclude: []string{"dns.interface", "dns.reply.host.IPv4"},
+	}, &configResponse)
+
+	assert.Equal(t, model.PatchConfig{
+		DNS: map[string]interface{}{
+			"hosts":        []interface{}{"192.168.1.2 nas"},
+			"cnameRecords": []interface{}{},
+			"reply": map[string]interface{}{
+				"host": map[string]interface{}{"force4": false},
+			},
+		},
+		DHCP: map[string]interface{}{"hosts": []interface{}{}},
+		NTP:  map[string]interface{}{"ipv4": map[string]interface{}{"active": true}},
+	}, request.Config)
+}
+
+func Test_createPatchConfigRequest_missingSection(t *testing.T) {
+	request := createPatchConfigRequest(&config.ManualConfig{DNS: true}, &model.ConfigResponse{Config: map[string]interface{}{}})
+
+	assert.Nil(t, request.Config.DNS)
+}
+
+func Test_createPatchConfigRequest_sensitive(t *testing.T) {
+	configResponse := model.ConfigResponse{Config: map[string]interface{}{
+		"webserver": map[string]interface{}{
+			"port":      "80o,443os",
+			"session":   map[string]interface{}{"timeout": float64(1800)},
+			"interface": map[string]interface{}{"theme": "default-dark"},
+			"api": map[string]interface{}{
+				"pwhash":       "$BALLOON-SHA256$v=1$s=1024,t=32$...",
+				"app_pwhash":   "$BALLOON-SHA256$v=1$s=1024,t=32$...",
+				"max_sessions": float64(16),
+			},
+			"tls": map[string]interface{}{"cert": "/etc/pihole/tls.pem"},
+		},
+		"files": map[string]interface{}{
+			"gravity": "/etc/pihole/gravity.db",
+			"log":     map[string]interface{}{"ftl": "/var/log/pihole/FTL.log"},
+		},
+	}}
+
+	request := createPatchConfigRequest(&config.ManualConfig{Webserver: true, Files: true}, &configResponse)
+	assert.Equal(t, model.PatchConfig{
+		Webserver: map[string]interface{}{
+			"session":   map[string]interface{}{"timeout": float64(1800)},
+			"interface": map[string]interface{}{"theme": "default-dark"},
+			"api":       map[string]interface{}{"max_sessions": float64(16)},
+		},
+	}, request.Config)
+
+	// Include patterns do not opt in, only sensitive patterns do.
+	request = createPatchConfigRequest(&config.ManualConfig{
+		Include:   []string{"webserver.*", "files.log"},
+		Sensitive: []string{"webserver.tls", "files.log.*"},
+	}, &configResponse)
+	assert.Equal(t, map[string]interface{}{"cert": "/etc/pihole/tls.pem"}, request.Config.Webserver["tls"])
+	assert.NotContains(t, request.Config.Webserver, "port")
+	assert.Equal(t, map[string]interface{}{"log": map[string]interface{}{"ftl": "/var/log/pihole/FTL.log"}}, request.Config.Files)
+}
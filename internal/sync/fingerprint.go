@@ -0,0 +1,97 @@
+package sync
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"io"
+	"maps"
+	"path"
+)
+
+// teleporterFingerprint identifies the contents of a teleporter archive and the options it is imported with.
+type teleporterFingerprint struct {
+	Options string            `json:"options"`
+	Files   map[string]string `json:"files"`
+}
+
+func (fingerprint *teleporterFingerprint) Equal(other *teleporterFingerprint) bool {
+	if fingerprint == nil || other == nil {
+		return false
+	}
+	return fingerprint.Options == other.Options && maps.Equal(fingerprint.Files, other.Files)
+}
+
+// newTeleporterFingerprint hashes every file in the archive that the import would apply.
+// Files that are not imported with the given request are left out, so changes to them do not cause an import.
+func newTeleporterFingerprint(teleporter []byte, teleporterRequest *model.PostTeleporterRequest) (*teleporterFingerprint, error) {
+	options, err := json.Marshal(teleporterRequest)
+	if err != nil {
+		return nil, fmt.Errorf("marshal teleporter request: %w", err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(teleporter), int64(len(teleporter)))
+	if err != nil {
+		return nil, fmt.Errorf("read teleporter: %w", err)
+	}
+
+	files := make(map[string]string, len(reader.File))
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() || !teleporterFileImported(file.Name, teleporterRequest) {
+			continue
+		}
+
+		hash, err := hashZipFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read teleporter file %s: %w", file.Name, err)
+		}
+		files[file.Name] = hash
+	}
+
+	return &teleporterFingerprint{
+		Options: hashBytes(options),
+		Files:   files,
+	}, nil
+}
+
+func teleporterFileImported(name string, teleporterRequest *model.PostTeleporterRequest) bool {
+	if teleporterRequest == nil {
+		return true
+	}
+
+	switch path.Base(name) {
+	case "pihole.toml":
+		return teleporterRequest.Config
+	case "dhcp.leases":
+		return teleporterRequest.DHCPLeases
+	case "gravity.db":
+		gravity := teleporterRequest.Gravity
+		return gravity.Group || gravity.Adlist || gravity.AdlistByGroup || gravity.Domainlist ||
+			gravity.DomainlistByGroup || gravity.Client || gravity.ClientByGroup
+	default:
+		return true
+	}
+}
+
+func hashZipFile(file *zip.File) (string, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func hashBytes(b []byte) string {
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,75 @@
+package sync
+
+import (
+	"archive/zip"
+	"bytes"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_newTeleporterFingerprint(t *testing.T) {
+	teleporter := createTeleporter(t, map[string]string{
+		"etc/pihole/pihole.toml": "[dns]",
+		"etc/pihole/gravity.db":  "gravity",
+		"etc/hosts":              "127.0.0.1 localhost",
+	})
+
+	fingerprint, err := newTeleporterFingerprint(teleporter, nil)
+	require.NoError(t, err)
+	assert.Len(t, fingerprint.Files, 3)
+
+	same, err := newTeleporterFingerprint(createTeleporter(t, map[string]string{
+		"etc/hosts":              "127.0.0.1 localhost",
+		"etc/pihole/gravity.db":  "gravity",
+		"etc/pihole/pihole.toml": "[dns]",
+	}), nil)
+	require.NoError(t, err)
+	assert.True(t, fingerprint.Equal(same))
+
+	changed, err := newTeleporterFingerprint(createTeleporter(t, map[string]string{
+		"etc/pihole/pihole.toml": "[dhcp]",
+		"etc/pihole/gravity.db":  "gravity",
+		"etc/hosts":              "127.0.0.1 localhost",
+	}), nil)
+	require.NoError(t, err)
+	assert.False(t, fingerprint.Equal(changed))
+
+	withRequest, err := newTeleporterFingerprint(teleporter, &model.PostTeleporterRequest{Config: true})
+	require.NoError(t, err)
+	assert.False(t, fingerprint.Equal(withRequest))
+	assert.False(t, fingerprint.Equal(nil))
+}
+
+func Test_newTeleporterFingerprint_ignoresFilesNotImported(t *testing.T) {
+	request := &model.PostTeleporterRequest{Gravity: model.PostGravityRequest{Adlist: true}}
+
+	fingerprint, err := newTeleporterFingerprint(createTeleporter(t, map[string]string{
+		"etc/pihole/pihole.toml": "[dns]",
+		"etc/pihole/dhcp.leases": "lease1",
+		"etc/pihole/gravity.db":  "gravity",
+	}), request)
+	require.NoError(t, err)
+
+	assert.Len(t, fingerprint.Files, 1)
+	assert.Contains(t, fingerprint.Files, "etc/pihole/gravity.db")
+}
+
+func Test_newTeleporterFingerprint_invalidArchive(t *testing.T) {
+	_, err := newTeleporterFingerprint([]byte("not a zip"), nil)
+	assert.Error(t, err)
+}
+
+func createTeleporter(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, content := range files {
+		fileWriter, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = fileWriter.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
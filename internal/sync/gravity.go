@@ -0,0 +1,578 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/rs/zerolog/log"
+	"slices"
+)
+
+// defaultGroup is the id of the group Pi-hole assigns new items to, it cannot be deleted.
+const defaultGroup = 0
+
+// gravityTables selects what the API gravity sync applies. The *Groups fields sync the group memberships of the items,
+// without them new items are added to the default group and the memberships of existing items are kept.
+type gravityTables struct {
+	groups       bool
+	lists        bool
+	listGroups   bool
+	domains      bool
+	domainGroups bool
+	clients      bool
+	clientGroups bool
+}
+
+var allGravityTables = gravityTables{
+	groups:       true,
+	lists:        true,
+	listGroups:   true,
+	domains:      true,
+	domainGroups: true,
+	clients:      true,
+	clientGroups: true,
+}
+
+func newGravityTables(request model.PostGravityRequest) gravityTables {
+	return gravityTables{
+		groups:       request.Group,
+		lists:        request.Adlist,
+		listGroups:   request.AdlistByGroup,
+		domains:      request.Domainlist,
+		domainGroups: request.DomainlistByGroup,
+		clients:      request.Client,
+		clientGroups: request.ClientByGroup,
+	}
+}
+
+func (tables gravityTables) any() bool {
+	return tables != gravityTables{}
+}
+
+// gravity is the gravity of a Pi-hole as read through the API, tables that are not synced are left empty.
+type gravity struct {
+	groups  []model.Group
+	lists   []model.List
+	domains []model.Domain
+	clients []model.Client
+}
+
+// readGravity reads the selected gravity tables. Groups are always read, memberships are matched by group name.
+func readGravity(ctx context.Context, client pihole.Client, tables gravityTables) (*gravity, error) {
+	result := &gravity{}
+
+	groups, err := client.GetGroups(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get groups: %w", err)
+	}
+	result.groups = groups.Groups
+
+	if tables.lists || tables.listGroups {
+		lists, err := client.GetLists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get lists: %w", err)
+		}
+		result.lists = lists.Lists
+	}
+	if tables.domains || tables.domainGroups {
+		domains, err := client.GetDomains(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get domains: %w", err)
+		}
+		result.domains = domains.Domains
+	}
+	if tables.clients || tables.clientGroups {
+		clients, err := client.GetClients(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get clients: %w", err)
+		}
+		result.clients = clients.Clients
+	}
+
+	return result, nil
+}
+
+// primaryGravity reads the gravity of the primary if gravity is synced through the API, or returns nil otherwise.
+func (target *target) primaryGravity(ctx context.Context, tables gravityTables) (*gravity, error) {
+	if !target.Options.GravityAPI || !tables.any() {
+		return nil, nil
+	}
+
+	primaryGravity, err := readGravity(ctx, target.Primary, tables)
+	if err != nil {
+		return nil, fmt.Errorf("read gravity: %w", err)
+	}
+	return primaryGravity, nil
+}
+
+// syncGravity applies the differences between the gravity of the primary and the replica, and returns what changed.
+// With Options.Backup the replica is backed up before its first change, and restored if a later change fails.
+func (target *target) syncGravity(ctx context.Context, replica pihole.Client, primary *gravity, tables gravityTables) (replicaChanges, error) {
+	var backup []byte
+	delta := &gravityDelta{replica: replica}
+	if target.Options.Backup {
+		delta.beforeChange = func(ctx context.Context) error {
+			var err error
+			if backup, err = target.backup(ctx, replica); err != nil {
+				return fmt.Errorf("backup: %w", err)
+			}
+			return nil
+		}
+	}
+
+	log.Debug().Str("replica", replica.String()).Msg("Syncing gravity...")
+	if err := delta.apply(ctx, primary, tables); err != nil {
+		if backup != nil {
+			return replicaChanges{}, target.rollback(ctx, replica, backup, err)
+		}
+		return replicaChanges{}, err
+	}
+
+	if !delta.changed {
+		log.Info().Str("replica", replica.String()).Msg("Gravity unchanged")
+	}
+	return replicaChanges{lists: delta.listsChanged, any: delta.changed}, nil
+}
+
+// gravityDelta applies the gravity of the primary to a replica. Items are matched by their natural key, groups by name,
+// lists by address and type, domains by domain, type and kind, and clients by client id.
+type gravityDelta struct {
+	replica pihole.Client
+	// beforeChange is called once before the first change to the replica.
+	beforeChange func(ctx context.Context) error
+	changed      bool
+	// listsChanged is set if lists were added, updated or deleted.
+	listsChanged bool
+	// primaryGroups maps the group ids of the primary to group names.
+	primaryGroups map[int]string
+	// replicaGroups maps group names to the group ids of the replica.
+	replicaGroups map[string]int
+}
+
+// tableChanges counts the changes applied to a gravity table.
+type tableChanges struct {
+	added   int
+	updated int
+	deleted int
+}
+
+func (delta *gravityDelta) apply(ctx context.Context, primary *gravity, tables gravityTables) error {
+	current, err := readGravity(ctx, delta.replica, tables)
+	if err != nil {
+		return fmt.Errorf("read gravity: %w", err)
+	}
+
+	// Groups are created first and deleted last, so that memberships can refer to them.
+	groups, deletedGroups, err := delta.syncGroups(ctx, primary.groups, current.groups, tables.groups)
+	if err != nil {
+		return fmt.Errorf("sync groups: %w", err)
+	}
+
+	if tables.lists || tables.listGroups {
+		changes, err := delta.syncLists(ctx, primary.lists, current.lists, tables.lists, tables.listGroups)
+		if err != nil {
+			return fmt.Errorf("sync lists: %w", err)
+		}
+		delta.listsChanged = changes != tableChanges{}
+		delta.log("lists", changes)
+	}
+	if tables.domains || tables.domainGroups {
+		changes, err := delta.syncDomains(ctx, primary.domains, current.domains, tables.domains, tables.domainGroups)
+		if err != nil {
+			return fmt.Errorf("sync domains: %w", err)
+		}
+		delta.log("domains", changes)
+	}
+	if tables.clients || tables.clientGroups {
+		changes, err := delta.syncClients(ctx, primary.clients, current.clients, tables.clients, tables.clientGroups)
+		if err != nil {
+			return fmt.Errorf("sync clients: %w", err)
+		}
+		delta.log("clients", changes)
+	}
+
+	if len(deletedGroups) > 0 {
+		if err := delta.change(ctx); err != nil {
+			return err
+		}
+		if err := delta.replica.DeleteGroups(ctx, deletedGroups); err != nil {
+			return fmt.Errorf("delete groups: %w", err)
+		}
+		groups.deleted = len(deletedGroups)
+	}
+	if tables.groups {
+		delta.log("groups", groups)
+	}
+
+	return nil
+}
+
+// change is called before every change to the replica.
+func (delta *gravityDelta) change(ctx context.Context) error {
+	if delta.changed {
+		return nil
+	}
+	delta.changed = true
+	if delta.beforeChange != nil {
+		return delta.beforeChange(ctx)
+	}
+	return nil
+}
+
+func (delta *gravityDelta) log(table string, changes tableChanges) {
+	if changes == (tableChanges{}) {
+		return
+	}
+	log.Info().
+		Str("replica", delta.replica.String()).
+		Str("table", table).
+		Int("added", changes.added).
+		Int("updated", changes.updated).
+		Int("deleted", changes.deleted).
+		Msg("Gravity synced")
+}
+
+// syncGroups adds and updates the groups of the replica and maps the group ids of both Pi-holes. The groups to delete
+// are returned, they are deleted after the memberships of the other tables no longer refer to them.
+func (delta *gravityDelta) syncGroups(ctx context.Context, primary, current []model.Group, entries bool) (tableChanges, []string, error) {
+	delta.primaryGroups = make(map[int]string, len(primary))
+	for _, group := range primary {
+		delta.primaryGroups[group.Id] = group.Name
+	}
+	delta.replicaGroups = make(map[string]int, len(current))
+	for _, group := range current {
+		delta.replicaGroups[group.Name] = group.Id
+	}
+
+	var changes tableChanges
+	if !entries {
+		return changes, nil, nil
+	}
+
+	added, matched, deleted := matchGravity(primary, current, func(group model.Group) string { return group.Name })
+	for _, group := range added {
+		if err := delta.change(ctx); err != nil {
+			return changes, nil, err
+		}
+		created, err := delta.replica.CreateGroup(ctx, &model.GroupRequest{Name: group.Name, Comment: group.Comment, Enabled: group.Enabled})
+		if err != nil {
+			return changes, nil, fmt.Errorf("create group %s: %w", group.Name, err)
+		}
+		delta.replicaGroups[created.Name] = created.Id
+		changes.added++
+	}
+
+	for _, match := range matched {
+		if match.primary.Comment == match.replica.Comment && match.primary.Enabled == match.replica.Enabled {
+			continue
+		}
+		if err := delta.change(ctx); err != nil {
+			return changes, nil, err
+		}
+		request := &model.GroupRequest{Name: match.primary.Name, Comment: match.primary.Comment, Enabled: match.primary.Enabled}
+		if _, err := delta.replica.UpdateGroup(ctx, match.primary.Name, request); err != nil {
+			return changes, nil, fmt.Errorf("update group %s: %w", match.primary.Name, err)
+		}
+		changes.updated++
+	}
+
+	var names []string
+	for _, group := range deleted {
+		if group.Id != defaultGroup {
+			names = append(names, group.Name)
+		}
+	}
+	return changes, names, nil
+}
+
+func (delta *gravityDelta) syncLists(ctx context.Context, primary, current []model.List, entries, memberships bool) (tableChanges, error) {
+	var changes tableChanges
+	added, matched, deleted := matchGravity(primary, current, func(list model.List) model.ListKey {
+		return model.ListKey{Address: list.Address, Type: list.Type}
+	})
+
+	if entries {
+		for _, list := range added {
+			if err := delta.change(ctx); err != nil {
+				return changes, err
+			}
+			request := &model.ListRequest{
+				Address: list.Address,
+				Type:    list.Type,
+				Comment: list.Comment,
+				Groups:  delta.newGroups(list.Groups, memberships),
+				Enabled: list.Enabled,
+			}
+			if _, err := delta.replica.CreateList(ctx, request); err != nil {
+				return changes, fmt.Errorf("create list %s: %w", list.Address, err)
+			}
+			changes.added++
+		}
+	}
+
+	for _, match := range matched {
+		request := &model.ListRequest{
+			Type:    match.replica.Type,
+			Comment: match.replica.Comment,
+			Groups:  sortedGroups(match.replica.Groups),
+			Enabled: match.replica.Enabled,
+		}
+		if entries {
+			request.Comment = match.primary.Comment
+			request.Enabled = match.primary.Enabled
+		}
+		if memberships {
+			request.Groups = delta.remap(match.primary.Groups)
+		}
+		if request.Comment == match.replica.Comment && request.Enabled == match.replica.Enabled && slices.Equal(request.Groups, sortedGroups(match.replica.Groups)) {
+			continue
+		}
+
+		if err := delta.change(ctx); err != nil {
+			return changes, err
+		}
+		key := model.ListKey{Address: match.replica.Address, Type: match.replica.Type}
+		if _, err := delta.replica.UpdateList(ctx, key, request); err != nil {
+			return changes, fmt.Errorf("update list %s: %w", key.Address, err)
+		}
+		changes.updated++
+	}
+
+	if entries && len(deleted) > 0 {
+		if err := delta.change(ctx); err != nil {
+			return changes, err
+		}
+		keys := make([]model.ListKey, len(deleted))
+		for i, list := range deleted {
+			keys[i] = model.ListKey{Address: list.Address, Type: list.Type}
+		}
+		if err := delta.replica.DeleteLists(ctx, keys); err != nil {
+			return changes, fmt.Errorf("delete lists: %w", err)
+		}
+		changes.deleted = len(keys)
+	}
+
+	return changes, nil
+}
+
+func (delta *gravityDelta) syncDomains(ctx context.Context, primary, current []model.Domain, entries, memberships bool) (tableChanges, error) {
+	var changes tableChanges
+	added, matched, deleted := matchGravity(primary, current, domainKey)
+
+	if entries {
+		for _, domain := range added {
+			if err := delta.change(ctx); err != nil {
+				return changes, err
+			}
+			request := &model.DomainRequest{
+				Domain:  domain.Domain,
+				Type:    domain.Type,
+				Kind:    domain.Kind,
+				Comment: domain.Comment,
+				Groups:  delta.newGroups(domain.Groups, memberships),
+				Enabled: domain.Enabled,
+			}
+			if _, err := delta.replica.CreateDomain(ctx, request); err != nil {
+				return changes, fmt.Errorf("create domain %s: %w", domain.Domain, err)
+			}
+			changes.added++
+		}
+	}
+
+	for _, match := range matched {
+		request := &model.DomainRequest{
+			Type:    match.replica.Type,
+			Kind:    match.replica.Kind,
+			Comment: match.replica.Comment,
+			Groups:  sortedGroups(match.replica.Groups),
+			Enabled: match.replica.Enabled,
+		}
+		if entries {
+			request.Comment = match.primary.Comment
+			request.Enabled = match.primary.Enabled
+		}
+		if memberships {
+			request.Groups = delta.remap(match.primary.Groups)
+		}
+		if request.Comment == match.replica.Comment && request.Enabled == match.replica.Enabled && slices.Equal(request.Groups, sortedGroups(match.replica.Groups)) {
+			continue
+		}
+
+		if err := delta.change(ctx); err != nil {
+			return changes, err
+		}
+		key := domainKey(match.replica)
+		if _, err := delta.replica.UpdateDomain(ctx, key, request); err != nil {
+			return changes, fmt.Errorf("update domain %s: %w", key.Domain, err)
+		}
+		changes.updated++
+	}
+
+	if entries && len(deleted) > 0 {
+		if err := delta.change(ctx); err != nil {
+			return changes, err
+		}
+		keys := make([]model.DomainKey, len(deleted))
+		for i, domain := range deleted {
+			keys[i] = domainKey(domain)
+		}
+		if err := delta.replica.DeleteDomains(ctx, keys); err != nil {
+			return changes, fmt.Errorf("delete domains: %w", err)
+		}
+		changes.deleted = len(keys)
+	}
+
+	return changes, nil
+}
+
+func (delta *gravityDelta) syncClients(ctx context.Context, primary, current []model.Client, entries, memberships bool) (tableChanges, error) {
+	var changes tableChanges
+	added, matched, deleted := matchGravity(primary, current, func(client model.Client) string { return client.Client })
+
+	if entries {
+		for _, client := range added {
+			if err := delta.change(ctx); err != nil {
+				return changes, err
+			}
+			request := &model.ClientRequest{
+				Client:  client.Client,
+				Comment: client.Comment,
+				Groups:  delta.newGroups(client.Groups, memberships),
+			}
+			if _, err := delta.replica.CreateClient(ctx, request); err != nil {
+				return changes, fmt.Errorf("create client %s: %w", client.Client, err)
+			}
+			changes.added++
+		}
+	}
+
+	for _, match := range matched {
+		request := &model.ClientRequest{
+			Comment: match.replica.Comment,
+			Groups:  sortedGroups(match.replica.Groups),
+		}
+		if entries {
+			request.Comment = match.primary.Comment
+		}
+		if memberships {
+			request.Groups = delta.remap(match.primary.Groups)
+		}
+		if request.Comment == match.replica.Comment && slices.Equal(request.Groups, sortedGroups(match.replica.Groups)) {
+			continue
+		}
+
+		if err := delta.change(ctx); err != nil {
+			return changes, err
+		}
+		if _, err := delta.replica.UpdateClient(ctx, match.replica.Client, request); err != nil {
+			return changes, fmt.Errorf("update client %s: %w", match.replica.Client, err)
+		}
+		changes.updated++
+	}
+
+	if entries && len(deleted) > 0 {
+		if err := delta.change(ctx); err != nil {
+			return changes, err
+		}
+		ids := make([]string, len(deleted))
+		for i, client := range deleted {
+			ids[i] = client.Client
+		}
+		if err := delta.replica.DeleteClients(ctx, ids); err != nil {
+			return changes, fmt.Errorf("delete clients: %w", err)
+		}
+		changes.deleted = len(ids)
+	}
+
+	return changes, nil
+}
+
+// newGroups returns the groups of a new item, the remapped groups of the primary item if memberships are synced.
+func (delta *gravityDelta) newGroups(groups []int, memberships bool) []int {
+	if !memberships {
+		return []int{defaultGroup}
+	}
+	return delta.remap(groups)
+}
+
+// remap maps group ids of the primary to the ids of the groups with the same name on the replica.
+// Groups that do not exist on the replica are left out.
+func (delta *gravityDelta) remap(groups []int) []int {
+	remapped := make([]int, 0, len(groups))
+	for _, id := range groups {
+		name, ok := delta.primaryGroups[id]
+		if !ok {
+			continue
+		}
+		replicaID, ok := delta.replicaGroups[name]
+		if !ok {
+			log.Warn().Str("replica", delta.replica.String()).Str("group", name).Msg("Group missing on replica, membership skipped")
+			continue
+		}
+		remapped = append(remapped, replicaID)
+	}
+	slices.Sort(remapped)
+	return remapped
+}
+
+func sortedGroups(groups []int) []int {
+	sorted := slices.Clone(groups)
+	if sorted == nil {
+		sorted = []int{}
+	}
+	slices.Sort(sorted)
+	return sorted
+}
+
+func domainKey(domain model.Domain) model.DomainKey {
+	return model.DomainKey{Domain: domain.Domain, Type: domain.Type, Kind: domain.Kind}
+}
+
+// gravityMatch is an item that exists on the primary and the replica.
+type gravityMatch[T any] struct {
+	primary T
+	replica T
+}
+
+// matchGravity matches the items of the primary and the replica by key. It returns the primary items missing on the replica,
+// the items on both, and the replica items missing on the primary, each in the order of the Pi-hole they come from.
+func matchGravity[T any, K comparable](primary, replica []T, key func(T) K) (added []T, matched []gravityMatch[T], deleted []T) {
+	replicaItems := make(map[K]T, len(replica))
+	for _, item := range replica {
+		replicaItems[key(item)] = item
+	}
+	primaryKeys := make(map[K]bool, len(primary))
+	for _, item := range primary {
+		k := key(item)
+		primaryKeys[k] = true
+		if replicaItem, ok := replicaItems[k]; ok {
+			matched = append(matched, gravityMatch[T]{primary: item, replica: replicaItem})
+		} else {
+			added = append(added, item)
+		}
+	}
+	for _, item := range replica {
+		if !primaryKeys[key(item)] {
+			deleted = append(deleted, item)
+		}
+	}
+	return added, matched, deleted
+}
+
+// splitGravity returns the teleporter import of a sync with teleporterRequest, nil meaning a full import, and the gravity
+// tables synced through the API instead of the teleporter. The tables are empty unless Options.GravityAPI is set.
+func (target *target) splitGravity(teleporterRequest *model.PostTeleporterRequest) (*model.PostTeleporterRequest, gravityTables) {
+	if !target.Options.GravityAPI {
+		return teleporterRequest, gravityTables{}
+	}
+	if teleporterRequest == nil {
+		return &model.PostTeleporterRequest{Config: true, DHCPLeases: true}, allGravityTables
+	}
+	return &model.PostTeleporterRequest{Config: teleporterRequest.Config, DHCPLeases: teleporterRequest.DHCPLeases}, newGravityTables(teleporterRequest.Gravity)
+}
+
+// importsTeleporter reports whether a sync imports the teleporter, which is skipped if the API sync covers everything it would import.
+func (target *target) importsTeleporter(teleporterRequest *model.PostTeleporterRequest) bool {
+	return !target.Options.GravityAPI || teleporterRequest.Config || teleporterRequest.DHCPLeases
+}
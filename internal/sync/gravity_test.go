@@ -0,0 +1,157 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMatchGravity(t *testing.T) {
+	primary := []model.Client{{Client: "a", Comment: "new"}, {Client: "b"}}
+	replica := []model.Client{{Client: "c"}, {Client: "a", Comment: "old"}}
+
+	added, matched, deleted := matchGravity(primary, replica, func(client model.Client) string { return client.Client })
+	assert.Equal(t, []model.Client{{Client: "b"}}, added)
+	assert.Equal(t, []gravityMatch[model.Client]{{primary: primary[0], replica: replica[1]}}, matched)
+	assert.Equal(t, []model.Client{{Client: "c"}}, deleted)
+}
+
+func TestSplitGravity(t *testing.T) {
+	target := &target{}
+	request, tables := target.splitGravity(nil)
+	assert.Nil(t, request)
+	assert.False(t, tables.any())
+
+	target.Options.GravityAPI = true
+	request, tables = target.splitGravity(nil)
+	assert.Equal(t, &model.PostTeleporterRequest{Config: true, DHCPLeases: true}, request)
+	assert.Equal(t, allGravityTables, tables)
+	assert.True(t, target.importsTeleporter(request))
+
+	request, tables = target.splitGravity(&model.PostTeleporterRequest{Gravity: model.PostGravityRequest{Adlist: true, AdlistByGroup: true}})
+	assert.Equal(t, &model.PostTeleporterRequest{}, request)
+	assert.Equal(t, gravityTables{lists: true, listGroups: true}, tables)
+	assert.False(t, target.importsTeleporter(request))
+}
+
+func TestTarget_syncGravity(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	target := NewTarget(piholemock.NewClient(t), []pihole.Client{replica}, Options{GravityAPI: true}).(*target)
+
+	primary := &gravity{
+		groups: []model.Group{{Id: 0, Name: "Default", Enabled: true}, {Id: 1, Name: "kids", Enabled: true}, {Id: 2, Name: "iot", Enabled: true}},
+		lists: []model.List{
+			{Address: "https://a.example.com/list", Type: "block", Groups: []int{0, 1}, Enabled: true},
+			{Address: "https://b.example.com/list", Type: "block", Groups: []int{2}, Enabled: true},
+			{Address: "https://c.example.com/list", Type: "allow", Comment: "allowed", Groups: []int{0}, Enabled: true},
+		},
+		domains: []model.Domain{{Domain: "ads.example.com", Type: "deny", Kind: "exact", Groups: []int{2, 0}, Enabled: true}},
+	}
+
+	replica.EXPECT().GetGroups(mock.Anything).Return(&model.GroupsResponse{Groups: []model.Group{
+		{Id: 0, Name: "Default", Enabled: true}, {Id: 5, Name: "iot", Enabled: false}, {Id: 7, Name: "old", Enabled: true},
+	}}, nil)
+	replica.EXPECT().GetLists(mock.Anything).Return(&model.ListsResponse{Lists: []model.List{
+		{Address: "https://b.example.com/list", Type: "block", Groups: []int{5}, Enabled: true},
+		{Address: "https://c.example.com/list", Type: "allow", Groups: []int{0}, Enabled: true},
+		{Address: "https://d.example.com/list", Type: "block", Groups: []int{7}, Enabled: true},
+	}}, nil)
+	replica.EXPECT().GetDomains(mock.Anything).Return(&model.DomainsResponse{Domains: []model.Domain{
+		{Domain: "ads.example.com", Type: "deny", Kind: "exact", Groups: []int{0, 5}, Enabled: true},
+	}}, nil)
+
+	replica.EXPECT().CreateGroup(mock.Anything, &model.GroupRequest{Name: "kids", Enabled: true}).
+		Return(&model.Group{Id: 8, Name: "kids", Enabled: true}, nil).Once()
+	replica.EXPECT().UpdateGroup(mock.Anything, "iot", &model.GroupRequest{Name: "iot", Enabled: true}).
+		Return(&model.Group{Id: 5, Name: "iot", Enabled: true}, nil).Once()
+	replica.EXPECT().CreateList(mock.Anything, &model.ListRequest{Address: "https://a.example.com/list", Type: "block", Groups: []int{0, 8}, Enabled: true}).
+		Return(&model.List{}, nil).Once()
+	replica.EXPECT().UpdateList(mock.Anything, model.ListKey{Address: "https://c.example.com/list", Type: "allow"}, &model.ListRequest{Type: "allow", Comment: "allowed", Groups: []int{0}, Enabled: true}).
+		Return(&model.List{}, nil).Once()
+	replica.EXPECT().DeleteLists(mock.Anything, []model.ListKey{{Address: "https://d.example.com/list", Type: "block"}}).
+		Return(nil).Once()
+	replica.EXPECT().DeleteGroups(mock.Anything, []string{"old"}).
+		Return(nil).Once()
+
+	tables := gravityTables{groups: true, lists: true, listGroups: true, domains: true, domainGroups: true}
+	changes, err := target.syncGravity(context.Background(), replica, primary, tables)
+	require.NoError(t, err)
+	assert.Equal(t, replicaChanges{lists: true, any: true}, changes)
+}
+
+func TestTarget_syncGravity_unchanged(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	target := NewTarget(piholemock.NewClient(t), []pihole.Client{replica}, Options{GravityAPI: true, Backup: true}).(*target)
+
+	primary := &gravity{
+		groups:  []model.Group{{Id: 0, Name: "Default", Enabled: true}, {Id: 3, Name: "kids"}},
+		clients: []model.Client{{Client: "192.168.1.10", Groups: []int{3}}},
+	}
+	replica.EXPECT().GetGroups(mock.Anything).Return(&model.GroupsResponse{Groups: []model.Group{
+		{Id: 0, Name: "Default", Enabled: true}, {Id: 1, Name: "kids"},
+	}}, nil)
+	replica.EXPECT().GetClients(mock.Anything).Return(&model.ClientsResponse{Clients: []model.Client{
+		{Client: "192.168.1.10", Groups: []int{1}},
+	}}, nil)
+
+	// Nothing changes, so no backup is taken.
+	changes, err := target.syncGravity(context.Background(), replica, primary, gravityTables{groups: true, clients: true, clientGroups: true})
+	require.NoError(t, err)
+	assert.Equal(t, replicaChanges{}, changes)
+}
+
+func TestTarget_syncGravity_rollback(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	target := NewTarget(piholemock.NewClient(t), []pihole.Client{replica}, Options{GravityAPI: true, Backup: true}).(*target)
+
+	primary := &gravity{
+		groups:  []model.Group{{Id: 0, Name: "Default", Enabled: true}},
+		domains: []model.Domain{{Domain: "ads.example.com", Type: "deny", Kind: "exact", Groups: []int{0}, Enabled: true}},
+	}
+	replica.EXPECT().GetGroups(mock.Anything).Return(&model.GroupsResponse{Groups: []model.Group{{Id: 0, Name: "Default", Enabled: true}}}, nil)
+	replica.EXPECT().GetDomains(mock.Anything).Return(&model.DomainsResponse{}, nil)
+
+	backup := []byte("backup")
+	replica.EXPECT().GetTeleporter(mock.Anything).Return(backup, nil).Once()
+	replica.EXPECT().CreateDomain(mock.Anything, &model.DomainRequest{Domain: "ads.example.com", Type: "deny", Kind: "exact", Groups: []int{0}, Enabled: true}).
+		Return(nil, errors.New("boom")).Once()
+	replica.EXPECT().PostTeleporter(mock.Anything, backup, (*model.PostTeleporterRequest)(nil)).Return(nil).Once()
+
+	_, err := target.syncGravity(context.Background(), replica, primary, gravityTables{domains: true})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "create domain ads.example.com: boom (rolled back)")
+}
+
+func TestTarget_FullSync_gravityAPI(t *testing.T) {
+	primary := piholemock.NewClient(t)
+	replica := piholemock.NewClient(t)
+	target := NewTarget(primary, []pihole.Client{replica}, Options{GravityAPI: true})
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{})
+
+	for _, client := range []*piholemock.Client{primary, replica} {
+		client.EXPECT().Authenticate(mock.Anything).Return(nil).Once()
+		client.EXPECT().GetGroups(mock.Anything).Return(&model.GroupsResponse{}, nil).Once()
+		client.EXPECT().GetLists(mock.Anything).Return(&model.ListsResponse{}, nil).Once()
+		client.EXPECT().GetDomains(mock.Anything).Return(&model.DomainsResponse{}, nil).Once()
+		client.EXPECT().GetClients(mock.Anything).Return(&model.ClientsResponse{}, nil).Once()
+		client.EXPECT().DeleteSession(mock.Anything).Return(nil).Once()
+	}
+
+	primary.EXPECT().GetTeleporter(mock.Anything).Return([]byte{}, nil).Once()
+	replica.EXPECT().PostTeleporter(mock.Anything, []byte{}, &model.PostTeleporterRequest{Config: true, DHCPLeases: true}).Return(nil).Once()
+
+	result, err := target.FullSync(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, result.Err())
+}
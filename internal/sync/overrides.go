@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is available to override templates, e.g. {{ .URL.Hostname }} or {{ .Vars.subnet }}.
+type TemplateData struct {
+	Name string
+	URL  *url.URL
+	Vars map[string]string
+}
+
+// applyOverrides returns a copy of the patch request with the overrides of the replica set on top.
+func applyOverrides(configRequest *model.PatchConfigRequest, piHole model.PiHole) (*model.PatchConfigRequest, error) {
+	if len(piHole.Overrides) == 0 {
+		return configRequest, nil
+	}
+
+	sections, err := patchConfigSections(configRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := overrideSections(sections, piHole); err != nil {
+		return nil, err
+	}
+
+	return newPatchConfigRequest(sections)
+}
+
+// overrideSections sets the overrides of the replica in the config sections.
+func overrideSections(sections map[string]interface{}, piHole model.PiHole) error {
+	data := TemplateData{Name: piHole.Name, URL: piHole.Url, Vars: piHole.Vars}
+
+	paths := make([]string, 0, len(piHole.Overrides))
+	for path := range piHole.Overrides {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		value, err := renderOverride(path, piHole.Overrides[path], data)
+		if err != nil {
+			return err
+		}
+		if err := setPath(sections, strings.Split(path, "."), value); err != nil {
+			return fmt.Errorf("override %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func renderOverride(path string, value interface{}, data TemplateData) (interface{}, error) {
+	text, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+
+	tmpl, err := template.New(path).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("override %s: %w", path, err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return nil, fmt.Errorf("override %s: %w", path, err)
+	}
+	return sb.String(), nil
+}
+
+func setPath(values map[string]interface{}, segments []string, value interface{}) error {
+	for i, segment := range segments[:len(segments)-1] {
+		next, exists := values[segment]
+		if !exists || next == nil {
+			next = map[string]interface{}{}
+			values[segment] = next
+		}
+
+		nested, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not a section", strings.Join(segments[:i+1], "."))
+		}
+		values = nested
+	}
+
+	values[segments[len(segments)-1]] = value
+	return nil
+}
+
+func newPatchConfigRequest(sections map[string]interface{}) (*model.PatchConfigRequest, error) {
+	bytes, err := json.Marshal(sections)
+	if err != nil {
+		return nil, fmt.Errorf("marshal patch config: %w", err)
+	}
+
+	patchRequest := model.PatchConfigRequest{}
+	if err := json.Unmarshal(bytes, &patchRequest.Config); err != nil {
+		return nil, fmt.Errorf("unmarshal patch config: %w", err)
+	}
+	return &patchRequest, nil
+}
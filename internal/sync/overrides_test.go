@@ -0,0 +1,128 @@
+package sync
+
+import (
+	"context"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_applyOverrides(t *testing.T) {
+	piHole := model.NewPiHole("http://192.168.2.2", "password")
+	piHole.Name = "ph2"
+	piHole.Vars = map[string]string{"subnet": "192.168.2"}
+	piHole.Overrides = map[string]interface{}{
+		"dhcp.router":         "{{ .Vars.subnet }}.1",
+		"dhcp.start":          "{{ .Vars.subnet }}.100",
+		"dns.reply.host.IPv4": "{{ .URL.Hostname }}",
+		"dns.domain":          "{{ .Name }}.lan",
+		"dhcp.rapidCommit":    false,
+	}
+
+	configRequest := &model.PatchConfigRequest{Config: model.PatchConfig{
+		DNS:  map[string]interface{}{"domain": "lan", "reply": map[string]interface{}{"host": map[string]interface{}{"IPv4": "192.168.1.2"}}},
+		DHCP: map[string]interface{}{"router": "192.168.1.1", "active": true},
+	}}
+
+	request, err := applyOverrides(configRequest, piHole)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.PatchConfig{
+		DNS: map[string]interface{}{"domain": "ph2.lan", "reply": map[string]interface{}{"host": map[string]interface{}{"IPv4": "192.168.2.2"}}},
+		DHCP: map[string]interface{}{
+			"router":      "192.168.2.1",
+			"start":       "192.168.2.100",
+			"active":      true,
+			"rapidCommit": false,
+		},
+	}, request.Config)
+
+	assert.Equal(t, "192.168.1.1", configRequest.Config.DHCP["router"], "shared request must not be modified")
+}
+
+func Test_applyOverrides_none(t *testing.T) {
+	configRequest := &model.PatchConfigRequest{}
+
+	request, err := applyOverrides(configRequest, model.PiHole{})
+	require.NoError(t, err)
+	assert.Same(t, configRequest, request)
+}
+
+func Test_applyOverrides_missingVar(t *testing.T) {
+	piHole := model.PiHole{Overrides: map[string]interface{}{"dhcp.router": "{{ .Vars.subnet }}.1"}}
+
+	_, err := applyOverrides(&model.PatchConfigRequest{}, piHole)
+	assert.ErrorContains(t, err, "override dhcp.router")
+}
+
+func Test_applyOverrides_notASection(t *testing.T) {
+	piHole := model.PiHole{Overrides: map[string]interface{}{"dns.port.value": 53}}
+
+	_, err := applyOverrides(&model.PatchConfigRequest{Config: model.PatchConfig{
+		DNS: map[string]interface{}{"port": float64(53)},
+	}}, piHole)
+	assert.EqualError(t, err, "override dns.port.value: dns.port is not a section")
+}
+
+func TestTarget_ManualSync_overrides(t *testing.T) {
+	primary := piholemock.NewClient(t)
+	replica := piholemock.NewClient(t)
+
+	target := NewTarget(primary, []pihole.Client{replica}, Options{})
+
+	settings := config.SyncSettings{
+		Gravity: &config.ManualGravity{},
+		Config:  &config.ManualConfig{DHCP: true},
+	}
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{Overrides: map[string]interface{}{"dhcp.router": "192.168.2.1"}})
+
+	primary.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	primary.EXPECT().GetTeleporter(mock.Anything).Times(1).Return([]byte{}, nil)
+	primary.EXPECT().GetConfig(mock.Anything).Times(1).Return(&model.ConfigResponse{Config: map[string]interface{}{
+		"dhcp": map[string]interface{}{"router": "192.168.1.1", "active": true},
+	}}, nil)
+	primary.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().PostTeleporter(mock.Anything, mock.Anything, mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().PatchConfig(mock.Anything, &model.PatchConfigRequest{Config: model.PatchConfig{
+		DHCP: map[string]interface{}{"router": "192.168.2.1", "active": true},
+	}}).Times(1).Return(nil)
+	replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	result, err := target.ManualSync(context.Background(), &settings)
+	require.NoError(t, err)
+	assert.NoError(t, result.Err())
+}
+
+func TestTarget_FullSync_overrides(t *testing.T) {
+	primary := piholemock.NewClient(t)
+	replica := piholemock.NewClient(t)
+
+	target := NewTarget(primary, []pihole.Client{replica}, Options{})
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{Overrides: map[string]interface{}{"dhcp.router": "192.168.2.1"}})
+
+	primary.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	primary.EXPECT().GetTeleporter(mock.Anything).Times(1).Return([]byte{}, nil)
+	primary.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().PostTeleporter(mock.Anything, mock.Anything, mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().PatchConfig(mock.Anything, &model.PatchConfigRequest{Config: model.PatchConfig{
+		DHCP: map[string]interface{}{"router": "192.168.2.1"},
+	}}).Times(1).Return(nil)
+	replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	result, err := target.FullSync(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, result.Err())
+}
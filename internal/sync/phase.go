@@ -0,0 +1,69 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+)
+
+// Phase is the step of a sync in which an error occurred.
+type Phase string
+
+const (
+	PhaseAuth       Phase = "auth"
+	PhaseTeleporter Phase = "teleporter"
+	PhaseGravity    Phase = "gravity"
+	PhaseConfig     Phase = "config"
+	PhaseVerify     Phase = "verify"
+	PhaseLogout     Phase = "logout"
+)
+
+// PhaseError is an error that occurred in a phase of a sync, its message is the message of Err.
+type PhaseError struct {
+	Phase Phase
+	Err   error
+}
+
+func (e *PhaseError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PhaseError) Unwrap() error {
+	return e.Err
+}
+
+func phaseError(phase Phase, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PhaseError{Phase: phase, Err: explain(err)}
+}
+
+// explain adds the likely fix to errors of the Pi-hole API that are caused by the setup of a Pi-hole.
+func explain(err error) error {
+	var apiErr *pihole.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch {
+	case apiErr.BadPassword():
+		return fmt.Errorf("%w: check the password and 2FA settings of the Pi-hole", err)
+	case apiErr.RateLimited():
+		return fmt.Errorf("%w: Pi-hole is rate limiting requests or has no free API seats, wait for old sessions to expire or raise webserver.api.max_sessions", err)
+	case apiErr.ReadOnly():
+		return fmt.Errorf("%w: the config cannot be changed through the API, enable webserver.api.app_sudo for app passwords or exclude keys set by env vars on the Pi-hole", err)
+	case apiErr.Unsupported():
+		return fmt.Errorf("%w: %s is not supported by this Pi-hole, Pi-hole v6 or later is required", err, apiErr.Endpoint)
+	}
+	return err
+}
+
+// ErrorPhase returns the phase of the sync that the error occurred in, or an empty phase if it is unknown.
+func ErrorPhase(err error) Phase {
+	var phaseErr *PhaseError
+	if errors.As(err, &phaseErr) {
+		return phaseErr.Phase
+	}
+	return ""
+}
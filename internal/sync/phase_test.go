@@ -0,0 +1,61 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+func TestErrorPhase(t *testing.T) {
+	err := fmt.Errorf("ph2: %w", phaseError(PhaseTeleporter, errors.New("import failed")))
+
+	assert.Equal(t, PhaseTeleporter, ErrorPhase(err))
+	assert.EqualError(t, err, "ph2: import failed")
+	assert.Equal(t, Phase(""), ErrorPhase(errors.New("import failed")))
+	assert.Nil(t, phaseError(PhaseConfig, nil))
+}
+
+func Test_runReplica_phases(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().Authenticate(mock.Anything).Return(errors.New("invalid password")).Once()
+
+	_, err := runReplica(context.Background(), replica, func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) { return nil, nil })
+	assert.Equal(t, PhaseAuth, ErrorPhase(err))
+
+	replica.EXPECT().Authenticate(mock.Anything).Return(nil).Once()
+	replica.EXPECT().DeleteSession(mock.Anything).Return(errors.New("timeout")).Once()
+
+	_, err = runReplica(context.Background(), replica, func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) { return nil, nil })
+	assert.Equal(t, PhaseLogout, ErrorPhase(err))
+
+	result := ReplicaResult{Err: err}
+	assert.Equal(t, PhaseLogout, result.Phase())
+}
+
+func Test_explain(t *testing.T) {
+	tests := []struct {
+		err  *pihole.APIError
+		want string
+	}{
+		{&pihole.APIError{StatusCode: 401, Endpoint: "POST /api/auth"}, "check the password"},
+		{&pihole.APIError{StatusCode: 429, Key: "api_seats_exceeded", Endpoint: "POST /api/auth"}, "no free API seats"},
+		{&pihole.APIError{StatusCode: 403, Key: "forbidden", Endpoint: "PATCH /api/config"}, "webserver.api.app_sudo"},
+		{&pihole.APIError{StatusCode: 404, Key: "not_found", Endpoint: "GET /api/groups"}, "GET /api/groups is not supported by this Pi-hole"},
+	}
+
+	for _, tt := range tests {
+		err := phaseError(PhaseConfig, fmt.Errorf("ph2: %w", tt.err))
+		assert.ErrorContains(t, err, tt.want)
+
+		var apiErr *pihole.APIError
+		assert.ErrorAs(t, err, &apiErr)
+	}
+
+	err := &pihole.APIError{StatusCode: 500}
+	assert.Equal(t, error(err), explain(err))
+}
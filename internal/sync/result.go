@@ -0,0 +1,77 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSkipped is the error of replicas that were not synced because a previous replica failed with Options.FailFast.
+var ErrSkipped = errors.New("skipped after a previous replica failed")
+
+type SyncResult struct {
+	Replicas []ReplicaResult
+	Duration time.Duration
+}
+
+type ReplicaResult struct {
+	Replica  string
+	Err      error
+	Duration time.Duration
+	// Actions are the post-sync actions that ran on the replica.
+	Actions []ActionResult
+}
+
+// ActionResult is the outcome of a post-sync action, a failed action does not fail the replica.
+type ActionResult struct {
+	Action   string
+	Err      error
+	Duration time.Duration
+}
+
+func (result *ActionResult) Success() bool {
+	return result.Err == nil
+}
+
+func (result *ReplicaResult) Success() bool {
+	return result.Err == nil
+}
+
+// Phase returns the phase in which the replica failed, or an empty phase if it succeeded.
+func (result *ReplicaResult) Phase() Phase {
+	return ErrorPhase(result.Err)
+}
+
+// Skipped reports whether the replica was not synced because a previous replica failed.
+func (result *ReplicaResult) Skipped() bool {
+	return errors.Is(result.Err, ErrSkipped)
+}
+
+func (result *SyncResult) Succeeded() []ReplicaResult {
+	var succeeded []ReplicaResult
+	for _, replica := range result.Replicas {
+		if replica.Success() {
+			succeeded = append(succeeded, replica)
+		}
+	}
+	return succeeded
+}
+
+func (result *SyncResult) Failed() []ReplicaResult {
+	var failed []ReplicaResult
+	for _, replica := range result.Replicas {
+		if !replica.Success() {
+			failed = append(failed, replica)
+		}
+	}
+	return failed
+}
+
+// Err joins the errors of all failed replicas, it returns nil if every replica was synced.
+func (result *SyncResult) Err() error {
+	var errs []error
+	for _, replica := range result.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", replica.Replica, replica.Err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,31 @@
+package sync
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSyncResult_Err(t *testing.T) {
+	result := SyncResult{
+		Replicas: []ReplicaResult{
+			{Replica: "http://ph2.example.com"},
+			{Replica: "http://ph3.example.com", Err: errors.New("connection refused")},
+		},
+	}
+
+	assert.Len(t, result.Succeeded(), 1)
+	assert.Len(t, result.Failed(), 1)
+	assert.EqualError(t, result.Err(), "http://ph3.example.com: connection refused")
+}
+
+func TestSyncResult_Err_success(t *testing.T) {
+	result := SyncResult{
+		Replicas: []ReplicaResult{
+			{Replica: "http://ph2.example.com"},
+		},
+	}
+
+	assert.Empty(t, result.Failed())
+	assert.NoError(t, result.Err())
+}
@@ -0,0 +1,89 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/fsutil"
+	"github.com/rs/zerolog/log"
+	"os"
+	"path/filepath"
+	gosync "sync"
+)
+
+const stateFile = "state.json"
+
+type replicaState struct {
+	Teleporter *teleporterFingerprint `json:"teleporter,omitempty"`
+}
+
+// stateStore keeps what was last applied to each replica, persisted to dir if set.
+type stateStore struct {
+	dir      string
+	mu       gosync.Mutex
+	replicas map[string]replicaState
+}
+
+func newStateStore(dir string) *stateStore {
+	store := &stateStore{
+		dir:      dir,
+		replicas: make(map[string]replicaState),
+	}
+
+	if err := store.load(); err != nil {
+		log.Warn().Err(err).Msg("Failed to load sync state, starting without it")
+		store.replicas = make(map[string]replicaState)
+	}
+
+	return store
+}
+
+func (store *stateStore) teleporter(replica string) *teleporterFingerprint {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.replicas[replica].Teleporter
+}
+
+func (store *stateStore) setTeleporter(replica string, fingerprint *teleporterFingerprint) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	state := store.replicas[replica]
+	state.Teleporter = fingerprint
+	store.replicas[replica] = state
+
+	if err := store.save(); err != nil {
+		log.Warn().Err(err).Msg("Failed to save sync state")
+	}
+}
+
+func (store *stateStore) load() error {
+	if store.dir == "" {
+		return nil
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(store.dir, stateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("read state: %w", err)
+	}
+
+	if err := json.Unmarshal(bytes, &store.replicas); err != nil {
+		return fmt.Errorf("parse state: %w", err)
+	}
+	return nil
+}
+
+func (store *stateStore) save() error {
+	if store.dir == "" {
+		return nil
+	}
+
+	bytes, err := json.MarshalIndent(store.replicas, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal state: %w", err)
+	}
+
+	return fsutil.WriteFileAtomic(filepath.Join(store.dir, stateFile), bytes)
+}
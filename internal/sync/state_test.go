@@ -0,0 +1,36 @@
+package sync
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_stateStore_persist(t *testing.T) {
+	dir := t.TempDir()
+	fingerprint := &teleporterFingerprint{Options: "options", Files: map[string]string{"etc/hosts": "hash"}}
+
+	store := newStateStore(dir)
+	assert.Nil(t, store.teleporter("http://ph2.example.com"))
+	store.setTeleporter("http://ph2.example.com", fingerprint)
+
+	reloaded := newStateStore(dir)
+	assert.True(t, fingerprint.Equal(reloaded.teleporter("http://ph2.example.com")))
+	assert.Nil(t, reloaded.teleporter("http://ph3.example.com"))
+}
+
+func Test_stateStore_inMemory(t *testing.T) {
+	store := newStateStore("")
+	store.setTeleporter("http://ph2.example.com", &teleporterFingerprint{})
+
+	assert.NotNil(t, store.teleporter("http://ph2.example.com"))
+}
+
+func Test_stateStore_corrupt(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, stateFile), []byte("{"), 0o600))
+
+	store := newStateStore(dir)
+	assert.Nil(t, store.teleporter("http://ph2.example.com"))
+}
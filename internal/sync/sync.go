@@ -1,164 +1,568 @@
 package sync
 
 import (
+	"context"
 	"fmt"
 	"github.com/lovelaze/nebula-sync/internal/config"
 	"github.com/lovelaze/nebula-sync/internal/pihole"
 	"github.com/lovelaze/nebula-sync/internal/pihole/model"
 	"github.com/rs/zerolog/log"
+	"slices"
+	"strings"
+	gosync "sync"
+	"sync/atomic"
+	"time"
 )
 
 type Target interface {
-	FullSync() error
-	ManualSync(syncSettings *config.SyncSettings) error
+	FullSync(ctx context.Context) (*SyncResult, error)
+	ManualSync(ctx context.Context, syncSettings *config.SyncSettings) (*SyncResult, error)
+	Diff(ctx context.Context, syncSettings *config.SyncSettings) (*DiffResult, error)
+	SyncBlocking(ctx context.Context) error
+	WithReplicas(replicas []string) (Target, error)
+}
+
+type Options struct {
+	// Concurrency is the maximum number of replicas synced at the same time, 0 means no limit.
+	Concurrency int
+	// FailFast syncs no replica if one fails to authenticate, and stops the sync once a replica failed,
+	// the replicas not started are reported as skipped.
+	FailFast bool
+	// ChangeDetection skips teleporter imports and config patches that would not change a replica.
+	ChangeDetection bool
+	// StateDir persists what was last applied to each replica, kept in memory only if empty.
+	StateDir string
+	// Backup takes a teleporter backup of each replica before importing, and restores it if the import fails.
+	Backup bool
+	// BackupDir keeps backups on disk, in memory only if empty.
+	BackupDir string
+	// BackupKeep is the number of backups kept per replica in BackupDir, 0 keeps all.
+	BackupKeep int
+	// Verify re-reads synced config and gravity from each replica and fails the replica on mismatches.
+	Verify bool
+	// GravityAPI syncs gravity through the REST API, applying only the items that differ, instead of importing the teleporter.
+	GravityAPI bool
 }
 
 type target struct {
 	Primary  pihole.Client
 	Replicas []pihole.Client
+	Options  Options
+	state    *stateStore
+	backups  *backupStore
 }
 
-func NewTarget(primary pihole.Client, replicas []pihole.Client) Target {
+func NewTarget(primary pihole.Client, replicas []pihole.Client, options Options) Target {
 	return &target{
 		Primary:  primary,
 		Replicas: replicas,
+		Options:  options,
+		state:    newStateStore(options.StateDir),
+		backups:  &backupStore{dir: options.BackupDir, keep: options.BackupKeep},
+	}
+}
+
+// WithReplicas returns a target that syncs only the given replicas, by name or url, and shares the state and backups of this target.
+func (target *target) WithReplicas(replicas []string) (Target, error) {
+	filtered := make([]pihole.Client, 0, len(replicas))
+	for _, name := range replicas {
+		i := slices.IndexFunc(target.Replicas, func(replica pihole.Client) bool {
+			return replica.PiHole().Name == name || strings.TrimSuffix(replica.String(), "/") == strings.TrimSuffix(name, "/")
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("unknown replica %s", name)
+		}
+		if !slices.Contains(filtered, target.Replicas[i]) {
+			filtered = append(filtered, target.Replicas[i])
+		}
 	}
+
+	filteredTarget := *target
+	filteredTarget.Replicas = filtered
+	return &filteredTarget, nil
 }
 
-func (target *target) FullSync() error {
+func (target *target) FullSync(ctx context.Context) (*SyncResult, error) {
 	log.Info().Int("replicas", len(target.Replicas)).Msg("Running full sync")
-	if err := target.authenticate(); err != nil {
-		return fmt.Errorf("authenticate: %w", err)
+	if err := target.Primary.Authenticate(ctx); err != nil {
+		return nil, phaseError(PhaseAuth, fmt.Errorf("authenticate: %w", err))
+	}
+
+	teleporterRequest, tables := target.splitGravity(nil)
+
+	teleporter, err := target.Primary.GetTeleporter(ctx)
+	if err != nil {
+		return nil, phaseError(PhaseTeleporter, fmt.Errorf("get teleporter: %w", err))
+	}
+
+	fingerprint, err := target.fingerprint(teleporter, teleporterRequest)
+	if err != nil {
+		return nil, phaseError(PhaseTeleporter, err)
+	}
+
+	primaryGravity, err := target.primaryGravity(ctx, tables)
+	if err != nil {
+		return nil, phaseError(PhaseGravity, err)
+	}
+
+	gravityCounts, err := target.primaryGravityCounts(ctx, nil)
+	if err != nil {
+		return nil, phaseError(PhaseVerify, err)
 	}
 
-	if err := target.syncTeleporters(nil); err != nil {
-		return fmt.Errorf("sync teleporters: %w", err)
+	primaryConfig, err := target.primaryConfig(ctx)
+	if err != nil {
+		return nil, phaseError(PhaseVerify, err)
 	}
 
-	if err := target.deleteSessions(); err != nil {
-		return fmt.Errorf("delete sessions: %w", err)
+	result := target.syncReplicas(ctx, func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+		changes := replicaChanges{}
+		imported, err := target.syncTeleporter(ctx, replica, teleporter, teleporterRequest, fingerprint)
+		if err != nil {
+			return nil, phaseError(PhaseTeleporter, fmt.Errorf("sync teleporter: %w", err))
+		}
+		if imported {
+			changes.imported(teleporterRequest)
+		}
+		if primaryGravity != nil {
+			gravityChanges, err := target.syncGravity(ctx, replica, primaryGravity, tables)
+			if err != nil {
+				return nil, phaseError(PhaseGravity, fmt.Errorf("sync gravity: %w", err))
+			}
+			changes.merge(gravityChanges)
+		}
+		if piHole := replica.PiHole(); len(piHole.Overrides) > 0 {
+			overridesRequest, err := applyOverrides(&model.PatchConfigRequest{}, piHole)
+			if err != nil {
+				return nil, phaseError(PhaseConfig, err)
+			}
+			changed, err := target.syncConfig(ctx, replica, overridesRequest)
+			if err != nil {
+				return nil, phaseError(PhaseConfig, fmt.Errorf("sync overrides: %w", err))
+			}
+			if changed {
+				changes.patched()
+			}
+		}
+		if target.Options.Verify {
+			if err := verifyFullConfig(ctx, replica, primaryConfig); err != nil {
+				return nil, phaseError(PhaseVerify, fmt.Errorf("verify: %w", err))
+			}
+			if err := verifyGravity(ctx, replica, gravityCounts); err != nil {
+				return nil, phaseError(PhaseVerify, fmt.Errorf("verify: %w", err))
+			}
+		}
+		return runActions(ctx, replica, changes), nil
+	})
+
+	if err := target.Primary.DeleteSession(logoutContext(ctx)); err != nil {
+		return result, phaseError(PhaseLogout, fmt.Errorf("delete session: %w", err))
 	}
 
-	return nil
+	return result, nil
 }
 
-func (target *target) ManualSync(syncSettings *config.SyncSettings) error {
+func (target *target) ManualSync(ctx context.Context, syncSettings *config.SyncSettings) (*SyncResult, error) {
 	log.Info().Int("replicas", len(target.Replicas)).Msg("Running manual sync")
 
-	if err := target.authenticate(); err != nil {
-		return fmt.Errorf("authentication: %w", err)
+	if err := target.Primary.Authenticate(ctx); err != nil {
+		return nil, phaseError(PhaseAuth, fmt.Errorf("authenticate: %w", err))
 	}
 
-	if err := target.syncTeleporters(syncSettings.Gravity); err != nil {
-		return fmt.Errorf("sync teleporters: %w", err)
+	gravityRequest := createPostTeleporterRequest(syncSettings.Gravity)
+	teleporterRequest, tables := target.splitGravity(gravityRequest)
+	importTeleporter := target.importsTeleporter(teleporterRequest)
+
+	var teleporter []byte
+	var fingerprint *teleporterFingerprint
+	if importTeleporter {
+		var err error
+		if teleporter, err = target.Primary.GetTeleporter(ctx); err != nil {
+			return nil, phaseError(PhaseTeleporter, fmt.Errorf("get teleporter: %w", err))
+		}
+		if fingerprint, err = target.fingerprint(teleporter, teleporterRequest); err != nil {
+			return nil, phaseError(PhaseTeleporter, err)
+		}
 	}
 
-	if err := target.syncConfigs(syncSettings.Config); err != nil {
-		return fmt.Errorf("sync configs: %w", err)
+	primaryGravity, err := target.primaryGravity(ctx, tables)
+	if err != nil {
+		return nil, phaseError(PhaseGravity, err)
+	}
+
+	configResponse, err := target.Primary.GetConfig(ctx)
+	if err != nil {
+		return nil, phaseError(PhaseConfig, fmt.Errorf("get config: %w", err))
 	}
+	configRequest := createPatchConfigRequest(syncSettings.Config, configResponse)
 
-	if err := target.deleteSessions(); err != nil {
-		return fmt.Errorf("delete sessions: %w", err)
+	gravityCounts, err := target.primaryGravityCounts(ctx, gravityRequest)
+	if err != nil {
+		return nil, phaseError(PhaseVerify, err)
 	}
 
-	return nil
+	result := target.syncReplicas(ctx, func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+		changes := replicaChanges{}
+		if importTeleporter {
+			imported, err := target.syncTeleporter(ctx, replica, teleporter, teleporterRequest, fingerprint)
+			if err != nil {
+				return nil, phaseError(PhaseTeleporter, fmt.Errorf("sync teleporter: %w", err))
+			}
+			if imported {
+				changes.imported(teleporterRequest)
+			}
+		}
+		if primaryGravity != nil {
+			gravityChanges, err := target.syncGravity(ctx, replica, primaryGravity, tables)
+			if err != nil {
+				return nil, phaseError(PhaseGravity, fmt.Errorf("sync gravity: %w", err))
+			}
+			changes.merge(gravityChanges)
+		}
+		replicaRequest, err := applyOverrides(configRequest, replica.PiHole())
+		if err != nil {
+			return nil, phaseError(PhaseConfig, err)
+		}
+		changed, err := target.syncConfig(ctx, replica, replicaRequest)
+		if err != nil {
+			return nil, phaseError(PhaseConfig, fmt.Errorf("sync config: %w", err))
+		}
+		if changed {
+			changes.patched()
+		}
+		if target.Options.Verify {
+			if err := verifyConfig(ctx, replica, replicaRequest); err != nil {
+				return nil, phaseError(PhaseVerify, fmt.Errorf("verify: %w", err))
+			}
+			if err := verifyGravity(ctx, replica, gravityCounts); err != nil {
+				return nil, phaseError(PhaseVerify, fmt.Errorf("verify: %w", err))
+			}
+		}
+		return runActions(ctx, replica, changes), nil
+	})
+
+	if err := target.Primary.DeleteSession(logoutContext(ctx)); err != nil {
+		return result, phaseError(PhaseLogout, fmt.Errorf("delete session: %w", err))
+	}
+
+	return result, nil
 }
 
-func (target *target) authenticate() (err error) {
-	log.Info().Msg("Authenticating clients...")
-	if err := target.Primary.Authenticate(); err != nil {
-		return err
+// replicaSync syncs a replica and returns the results of the post-sync actions that ran on it.
+type replicaSync func(ctx context.Context, replica pihole.Client) ([]ActionResult, error)
+
+// syncReplicas runs syncFunc against every replica, at most Options.Concurrency at a time.
+// Each replica is authenticated and logged out on its own, so a failing replica does not affect the others,
+// unless Options.FailFast is set. Then every replica is authenticated before any is synced, and none is synced if
+// one fails to authenticate. After the first failure replicas not yet started are skipped and running ones cancelled.
+func (target *target) syncReplicas(ctx context.Context, syncFunc replicaSync) *SyncResult {
+	start := time.Now()
+	results := make([]ReplicaResult, len(target.Replicas))
+
+	authenticated := false
+	if target.Options.FailFast {
+		if !target.authenticateReplicas(ctx, results) {
+			return &SyncResult{Replicas: results, Duration: time.Since(start)}
+		}
+		authenticated = true
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		syncFunc = cancelOnFailure(syncFunc, cancel)
 	}
 
-	for _, replica := range target.Replicas {
-		if err := replica.Authenticate(); err != nil {
-			return err
+	concurrency := target.Options.Concurrency
+	if concurrency <= 0 || concurrency > len(target.Replicas) {
+		concurrency = len(target.Replicas)
+	}
+	semaphore := make(chan struct{}, concurrency)
+
+	var wg gosync.WaitGroup
+	var failed atomic.Bool
+	for i, replica := range target.Replicas {
+		semaphore <- struct{}{}
+		if target.Options.FailFast && failed.Load() {
+			<-semaphore
+			results[i] = ReplicaResult{Replica: replica.String(), Err: ErrSkipped}
+			log.Warn().Str("replica", results[i].Replica).Msg("Replica skipped after a previous failure")
+			deleteSession(ctx, replica)
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			<-semaphore
+			results[i] = ReplicaResult{Replica: replica.String(), Err: err}
+			log.Warn().Err(err).Str("replica", results[i].Replica).Msg("Replica skipped, sync cancelled")
+			if authenticated {
+				deleteSession(ctx, replica)
+			}
+			continue
 		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			results[i] = syncReplica(ctx, replica, syncFunc, authenticated)
+			if !results[i].Success() {
+				failed.Store(true)
+			}
+		}()
 	}
+	wg.Wait()
 
-	return err
+	return &SyncResult{
+		Replicas: results,
+		Duration: time.Since(start),
+	}
 }
 
-func (target *target) deleteSessions() (err error) {
-	log.Info().Msg("Invalidating sessions...")
-	if err := target.Primary.DeleteSession(); err != nil {
-		return err
+// authenticateReplicas authenticates every replica, one at a time. If a replica fails, the replicas authenticated so far
+// are logged out, results records the failure and the skipped replicas, and false is returned.
+func (target *target) authenticateReplicas(ctx context.Context, results []ReplicaResult) bool {
+	for i, replica := range target.Replicas {
+		err := replica.Authenticate(ctx)
+		if err == nil {
+			continue
+		}
+
+		for j, other := range target.Replicas {
+			switch {
+			case j < i:
+				deleteSession(ctx, other)
+				results[j] = ReplicaResult{Replica: other.String(), Err: ErrSkipped}
+			case j == i:
+				results[j] = ReplicaResult{Replica: other.String(), Err: phaseError(PhaseAuth, fmt.Errorf("authenticate: %w", err))}
+				log.Error().Err(err).Str("replica", results[j].Replica).Msg("Replica failed to authenticate, no replica is synced")
+			default:
+				results[j] = ReplicaResult{Replica: other.String(), Err: ErrSkipped}
+			}
+		}
+		return false
 	}
+	return true
+}
 
-	for _, replica := range target.Replicas {
-		if err := replica.DeleteSession(); err != nil {
-			return err
+// cancelOnFailure wraps syncFunc to call cancel when it fails.
+func cancelOnFailure(syncFunc replicaSync, cancel context.CancelFunc) replicaSync {
+	return func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+		actions, err := syncFunc(ctx, replica)
+		if err != nil {
+			cancel()
 		}
+		return actions, err
+	}
+}
+
+// syncReplica runs syncFunc against replica, authenticating it first unless it is already authenticated.
+func syncReplica(ctx context.Context, replica pihole.Client, syncFunc replicaSync, authenticated bool) ReplicaResult {
+	start := time.Now()
+	var actions []ActionResult
+	var err error
+	if authenticated {
+		actions, err = runSession(ctx, replica, syncFunc)
+	} else {
+		actions, err = runReplica(ctx, replica, syncFunc)
+	}
+	result := ReplicaResult{
+		Replica:  replica.String(),
+		Err:      err,
+		Duration: time.Since(start),
+		Actions:  actions,
+	}
+
+	if err != nil {
+		log.Error().Err(err).Str("replica", result.Replica).Dur("duration", result.Duration).Msg("Replica failed")
+	} else {
+		log.Info().Str("replica", result.Replica).Dur("duration", result.Duration).Msg("Replica done")
 	}
 
-	return err
+	return result
 }
 
-func (target *target) syncTeleporters(manualGravity *config.ManualGravity) error {
-	log.Info().Msg("Syncing Teleporters...")
-	conf, err := target.Primary.GetTeleporter()
+func runReplica(ctx context.Context, replica pihole.Client, syncFunc replicaSync) ([]ActionResult, error) {
+	if err := replica.Authenticate(ctx); err != nil {
+		return nil, phaseError(PhaseAuth, fmt.Errorf("authenticate: %w", err))
+	}
+	return runSession(ctx, replica, syncFunc)
+}
+
+// runSession runs syncFunc against an authenticated replica and logs it out.
+func runSession(ctx context.Context, replica pihole.Client, syncFunc replicaSync) ([]ActionResult, error) {
+	actions, err := syncFunc(ctx, replica)
 	if err != nil {
-		return err
+		deleteSession(ctx, replica)
+		return actions, err
 	}
 
-	var teleporterRequest *model.PostTeleporterRequest = nil
-	if manualGravity != nil {
-		teleporterRequest = createPostTeleporterRequest(manualGravity)
+	if err := replica.DeleteSession(logoutContext(ctx)); err != nil {
+		return actions, phaseError(PhaseLogout, fmt.Errorf("delete session: %w", err))
 	}
 
-	for _, replica := range target.Replicas {
-		if err := replica.PostTeleporter(conf, teleporterRequest); err != nil {
-			return err
-		}
+	return actions, nil
+}
+
+// deleteSession logs out replica, a failure is only logged as the replica already failed or was skipped.
+func deleteSession(ctx context.Context, replica pihole.Client) {
+	if err := replica.DeleteSession(logoutContext(ctx)); err != nil {
+		log.Warn().Err(err).Str("replica", replica.String()).Msg("Failed to delete session")
 	}
+}
 
-	return err
+// logoutContext returns a context for deleting a session that is not cancelled with ctx,
+// so that sessions are cleaned up after a cancelled sync. The request timeout still applies.
+func logoutContext(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
 }
 
-func (target *target) syncConfigs(manualConfig *config.ManualConfig) error {
-	log.Info().Msg("Syncing configs...")
-	configResponse, err := target.Primary.GetConfig()
+// fingerprint returns the fingerprint of the teleporter archive, or nil if change detection is disabled.
+func (target *target) fingerprint(teleporter []byte, teleporterRequest *model.PostTeleporterRequest) (*teleporterFingerprint, error) {
+	if !target.Options.ChangeDetection {
+		return nil, nil
+	}
+
+	fingerprint, err := newTeleporterFingerprint(teleporter, teleporterRequest)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("fingerprint teleporter: %w", err)
+	}
+	return fingerprint, nil
+}
+
+// syncTeleporter imports the teleporter to the replica, it reports false if change detection skipped the import.
+func (target *target) syncTeleporter(ctx context.Context, replica pihole.Client, teleporter []byte, teleporterRequest *model.PostTeleporterRequest, fingerprint *teleporterFingerprint) (bool, error) {
+	if fingerprint != nil && fingerprint.Equal(target.state.teleporter(replica.String())) {
+		log.Info().Str("replica", replica.String()).Msg("Teleporter unchanged, skipping import")
+		return false, nil
+	}
+
+	var backup []byte
+	if target.Options.Backup {
+		var err error
+		if backup, err = target.backup(ctx, replica); err != nil {
+			return false, fmt.Errorf("backup: %w", err)
+		}
+	}
+
+	log.Debug().Str("replica", replica.String()).Msg("Syncing teleporter...")
+	if err := replica.PostTeleporter(ctx, teleporter, teleporterRequest); err != nil {
+		if backup != nil {
+			return false, target.rollback(ctx, replica, backup, err)
+		}
+		return false, err
+	}
+
+	if backup != nil {
+		if err := healthCheck(ctx, replica); err != nil {
+			return false, target.rollback(ctx, replica, backup, fmt.Errorf("health check: %w", err))
+		}
+	}
+
+	if fingerprint != nil {
+		target.state.setTeleporter(replica.String(), fingerprint)
 	}
+	return true, nil
+}
 
-	configRequest := createPatchConfigRequest(manualConfig, configResponse)
+// syncConfig patches the config of the replica and reports whether it differed, nothing is patched without config
+// sections. The config of the replica is compared first with change detection, which skips an unchanged patch, or if a
+// post-sync action runs only on changes. Otherwise every patch is reported as a change.
+func (target *target) syncConfig(ctx context.Context, replica pihole.Client, configRequest *model.PatchConfigRequest) (bool, error) {
+	desired, err := patchConfigSections(configRequest)
+	if err != nil {
+		return false, err
+	}
+	if len(desired) == 0 {
+		log.Debug().Str("replica", replica.String()).Msg("No config sections to sync")
+		return false, nil
+	}
 
-	for _, replica := range target.Replicas {
-		if err := replica.PatchConfig(configRequest); err != nil {
-			return err
+	changed := true
+	if target.Options.ChangeDetection || replica.PiHole().PostSync.OnChange() {
+		if changed, err = configChanged(ctx, replica, desired); err != nil {
+			return false, err
+		}
+		if !changed && target.Options.ChangeDetection {
+			log.Info().Str("replica", replica.String()).Msg("Config unchanged, skipping patch")
+			return false, nil
 		}
 	}
 
-	return err
+	log.Debug().Str("replica", replica.String()).Msg("Syncing config...")
+	if err := replica.PatchConfig(ctx, configRequest); err != nil {
+		return false, err
+	}
+	return changed, nil
+}
+
+// configChanged compares the desired config sections with the current config of the replica.
+func configChanged(ctx context.Context, replica pihole.Client, desired map[string]interface{}) (bool, error) {
+	current, err := replica.GetConfig(ctx)
+	if err != nil {
+		return false, fmt.Errorf("get config: %w", err)
+	}
+
+	changes := diffConfig(desired, current.Config)
+	for _, change := range changes {
+		log.Debug().Str("replica", replica.String()).Str("path", change.Path).Msg("Config changed")
+	}
+
+	return len(changes) > 0, nil
 }
 
 func createPatchConfigRequest(config *config.ManualConfig, configResponse *model.ConfigResponse) *model.PatchConfigRequest {
 	patchConfig := model.PatchConfig{}
 
+	sections := map[string]*map[string]interface{}{
+		"dns":       &patchConfig.DNS,
+		"dhcp":      &patchConfig.DHCP,
+		"ntp":       &patchConfig.NTP,
+		"resolver":  &patchConfig.Resolver,
+		"database":  &patchConfig.Database,
+		"webserver": &patchConfig.Webserver,
+		"files":     &patchConfig.Files,
+		"misc":      &patchConfig.Misc,
+		"debug":     &patchConfig.Debug,
+	}
+
+	filter := newPathFilter(selectedSections(config), config.Include, config.Exclude).protect(config.Sensitive)
+	for section, patchSection := range sections {
+		values, _ := configResponse.Config[section].(map[string]interface{})
+		*patchSection = filter.filterSection(section, values)
+	}
+
+	return &model.PatchConfigRequest{Config: patchConfig}
+}
+
+func selectedSections(config *config.ManualConfig) []string {
+	var sections []string
 	if config.DNS {
-		patchConfig.DNS = configResponse.Config["dns"].(map[string]interface{})
+		sections = append(sections, "dns")
 	}
 	if config.DHCP {
-		patchConfig.DHCP = configResponse.Config["dhcp"].(map[string]interface{})
+		sections = append(sections, "dhcp")
 	}
 	if config.NTP {
-		patchConfig.NTP = configResponse.Config["ntp"].(map[string]interface{})
+		sections = append(sections, "ntp")
 	}
 	if config.Resolver {
-		patchConfig.Resolver = configResponse.Config["resolver"].(map[string]interface{})
+		sections = append(sections, "resolver")
 	}
 	if config.Database {
-		patchConfig.Database = configResponse.Config["database"].(map[string]interface{})
+		sections = append(sections, "database")
+	}
+	if config.Webserver {
+		sections = append(sections, "webserver")
+	}
+	if config.Files {
+		sections = append(sections, "files")
 	}
 	if config.Misc {
-		patchConfig.Misc = configResponse.Config["misc"].(map[string]interface{})
+		sections = append(sections, "misc")
 	}
 	if config.Debug {
-		patchConfig.Debug = configResponse.Config["debug"].(map[string]interface{})
+		sections = append(sections, "debug")
 	}
-
-	return &model.PatchConfigRequest{Config: patchConfig}
+	return sections
 }
 
 func createPostTeleporterRequest(gravity *config.ManualGravity) *model.PostTeleporterRequest {
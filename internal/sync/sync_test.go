@@ -1,6 +1,9 @@
 package sync
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/lovelaze/nebula-sync/internal/config"
 	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
 	"github.com/lovelaze/nebula-sync/internal/pihole"
@@ -8,57 +11,66 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestTarget_FullSync(t *testing.T) {
 	primary := piholemock.NewClient(t)
 	replica := piholemock.NewClient(t)
 
-	target := NewTarget(primary, []pihole.Client{replica})
+	target := NewTarget(primary, []pihole.Client{replica}, Options{})
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{})
 
 	primary.
 		EXPECT().
-		Authenticate().
+		Authenticate(mock.Anything).
 		Times(1).
 		Return(nil)
 	replica.
 		EXPECT().
-		Authenticate().
+		Authenticate(mock.Anything).
 		Times(1).
 		Return(nil)
 
 	primary.
 		EXPECT().
-		GetTeleporter().
+		GetTeleporter(mock.Anything).
 		Times(1).
 		Return([]byte{}, nil)
 	replica.
 		EXPECT().
-		PostTeleporter(mock.Anything, mock.Anything).
+		PostTeleporter(mock.Anything, mock.Anything, mock.Anything).
 		Times(1).
 		Return(nil)
 
 	primary.
 		EXPECT().
-		DeleteSession().
+		DeleteSession(mock.Anything).
 		Times(1).
 		Return(nil)
 	replica.
 		EXPECT().
-		DeleteSession().
+		DeleteSession(mock.Anything).
 		Times(1).
 		Return(nil)
 
-	err := target.FullSync()
+	result, err := target.FullSync(context.Background())
 	require.NoError(t, err)
+	assert.NoError(t, result.Err())
 }
 
 func TestTarget_ManualSync(t *testing.T) {
 	primary := piholemock.NewClient(t)
 	replica := piholemock.NewClient(t)
 
-	target := NewTarget(primary, []pihole.Client{replica})
+	target := NewTarget(primary, []pihole.Client{replica}, Options{})
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{})
 
 	settings := config.SyncSettings{
 		Gravity: &config.ManualGravity{
@@ -86,109 +98,248 @@ func TestTarget_ManualSync(t *testing.T) {
 
 	primary.
 		EXPECT().
-		Authenticate().
+		Authenticate(mock.Anything).
 		Times(1).
 		Return(nil)
 	replica.
 		EXPECT().
-		Authenticate().
+		Authenticate(mock.Anything).
 		Times(1).
 		Return(nil)
 
 	primary.
 		EXPECT().
-		GetTeleporter().
+		GetTeleporter(mock.Anything).
 		Times(1).
 		Return([]byte{}, nil)
 	replica.
 		EXPECT().
-		PostTeleporter(mock.Anything, mock.Anything).
+		PostTeleporter(mock.Anything, mock.Anything, mock.Anything).
 		Times(1).
 		Return(nil)
 
 	primary.
 		EXPECT().
-		GetConfig().
+		GetConfig(mock.Anything).
 		Times(1).
 		Return(&model.ConfigResponse{Config: make(map[string]interface{})}, nil)
-	replica.
-		EXPECT().
-		PatchConfig(mock.Anything).
-		Times(1).
-		Return(nil)
 
 	primary.
 		EXPECT().
-		DeleteSession().
+		DeleteSession(mock.Anything).
 		Times(1).
 		Return(nil)
 	replica.
 		EXPECT().
-		DeleteSession().
+		DeleteSession(mock.Anything).
 		Times(1).
 		Return(nil)
 
-	err := target.ManualSync(&settings)
+	result, err := target.ManualSync(context.Background(), &settings)
 	require.NoError(t, err)
+	assert.NoError(t, result.Err())
+	replica.AssertNotCalled(t, "PatchConfig", mock.Anything, mock.Anything)
 }
 
-func Test_target_authenticate(t *testing.T) {
+func TestTarget_FullSync_replicaFailure(t *testing.T) {
 	primary := piholemock.NewClient(t)
+	failing := piholemock.NewClient(t)
 	replica := piholemock.NewClient(t)
 
-	target := target{
-		Primary:  primary,
-		Replicas: []pihole.Client{replica},
-	}
+	target := NewTarget(primary, []pihole.Client{failing, replica}, Options{Concurrency: 1})
 
-	primary.
-		EXPECT().
-		Authenticate().
-		Times(1).
-		Return(nil)
-	replica.
-		EXPECT().
-		Authenticate().
-		Times(1).
-		Return(nil)
+	primary.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	primary.EXPECT().GetTeleporter(mock.Anything).Times(1).Return([]byte{}, nil)
+	primary.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
 
-	err := target.authenticate()
-	assert.NoError(t, err)
+	failing.EXPECT().Authenticate(mock.Anything).Times(1).Return(errors.New("connection refused"))
+	failing.EXPECT().String().Return("http://ph2.example.com")
+
+	replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().PostTeleporter(mock.Anything, mock.Anything, mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().String().Return("http://ph3.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{})
+
+	result, err := target.FullSync(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, result.Replicas, 2)
+	assert.Equal(t, "http://ph2.example.com", result.Replicas[0].Replica)
+	assert.ErrorContains(t, result.Replicas[0].Err, "connection refused")
+	assert.Equal(t, "http://ph3.example.com", result.Replicas[1].Replica)
+	assert.True(t, result.Replicas[1].Success())
 }
 
-func Test_target_deleteSessions(t *testing.T) {
+func TestTarget_FullSync_failFast(t *testing.T) {
 	primary := piholemock.NewClient(t)
 	replica := piholemock.NewClient(t)
+	failing := piholemock.NewClient(t)
 
-	target := target{
-		Primary:  primary,
-		Replicas: []pihole.Client{replica},
+	target := NewTarget(primary, []pihole.Client{replica, failing}, Options{Concurrency: 4, FailFast: true})
+
+	primary.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	primary.EXPECT().GetTeleporter(mock.Anything).Times(1).Return([]byte{}, nil)
+	primary.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+
+	failing.EXPECT().Authenticate(mock.Anything).Times(1).Return(errors.New("connection refused"))
+	failing.EXPECT().String().Return("http://ph3.example.com")
+
+	result, err := target.FullSync(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, result.Replicas, 2)
+	assert.True(t, result.Replicas[0].Skipped())
+	assert.ErrorContains(t, result.Replicas[1].Err, "connection refused")
+	replica.AssertNotCalled(t, "PostTeleporter", mock.Anything, mock.Anything, mock.Anything)
+	replica.AssertNotCalled(t, "PatchConfig", mock.Anything, mock.Anything)
+}
+
+func Test_target_syncReplicas_failFastCancel(t *testing.T) {
+	failing := piholemock.NewClient(t)
+	running := piholemock.NewClient(t)
+	for _, replica := range []*piholemock.Client{failing, running} {
+		replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+		replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
 	}
+	failing.EXPECT().String().Return("http://ph2.example.com")
+	running.EXPECT().String().Return("http://ph3.example.com")
 
-	primary.
-		EXPECT().
-		DeleteSession().
-		Times(1).
-		Return(nil)
-	replica.
-		EXPECT().
-		DeleteSession().
-		Times(1).
-		Return(nil)
+	target := target{
+		Replicas: []pihole.Client{failing, running},
+		Options:  Options{Concurrency: 2, FailFast: true},
+	}
 
-	err := target.deleteSessions()
-	assert.NoError(t, err)
+	result := target.syncReplicas(context.Background(), func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+		if replica == failing {
+			return nil, errors.New("patch failed")
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	require.Len(t, result.Replicas, 2)
+	assert.EqualError(t, result.Replicas[0].Err, "patch failed")
+	assert.ErrorIs(t, result.Replicas[1].Err, context.Canceled)
 }
 
-func Test_target_syncTeleporters(t *testing.T) {
+func TestTarget_WithReplicas(t *testing.T) {
 	primary := piholemock.NewClient(t)
-	replica := piholemock.NewClient(t)
+	ph2 := piholemock.NewClient(t)
+	ph3 := piholemock.NewClient(t)
+	ph2.EXPECT().PiHole().Return(model.PiHole{Name: "ph2"}).Maybe()
+	ph2.EXPECT().String().Return("http://ph2.example.com").Maybe()
+	ph3.EXPECT().PiHole().Return(model.PiHole{Name: "ph3.example.com"}).Maybe()
+	ph3.EXPECT().String().Return("http://ph3.example.com").Maybe()
+
+	all := NewTarget(primary, []pihole.Client{ph2, ph3}, Options{Concurrency: 2})
+
+	filtered, err := all.WithReplicas([]string{"http://ph3.example.com/", "ph2", "ph3.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []pihole.Client{ph3, ph2}, filtered.(*target).Replicas)
+	assert.Equal(t, 2, filtered.(*target).Options.Concurrency)
+	assert.Len(t, all.(*target).Replicas, 2)
+
+	_, err = all.WithReplicas([]string{"ph4"})
+	assert.ErrorContains(t, err, "unknown replica ph4")
+}
+
+func Test_target_syncReplicas(t *testing.T) {
+	replicas := make([]pihole.Client, 5)
+	for i := range replicas {
+		replica := piholemock.NewClient(t)
+		replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+		replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+		replica.EXPECT().String().Return(fmt.Sprintf("http://ph%d.example.com", i))
+		replicas[i] = replica
+	}
+
+	target := target{
+		Replicas: replicas,
+		Options:  Options{Concurrency: 2},
+	}
+
+	var running, maxRunning atomic.Int32
+	result := target.syncReplicas(context.Background(), func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	})
+
+	assert.NoError(t, result.Err())
+	assert.Len(t, result.Replicas, 5)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(2))
+}
+
+func Test_target_syncReplicas_failFast(t *testing.T) {
+	replicas := make([]pihole.Client, 3)
+	for i := range replicas {
+		replica := piholemock.NewClient(t)
+		replica.EXPECT().String().Return(fmt.Sprintf("http://ph%d.example.com", i))
+		if i == 0 {
+			replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(errors.New("connection refused"))
+		}
+		replicas[i] = replica
+	}
 
 	target := target{
-		Primary:  primary,
-		Replicas: []pihole.Client{replica},
+		Replicas: replicas,
+		Options:  Options{Concurrency: 1, FailFast: true},
 	}
 
+	result := target.syncReplicas(context.Background(), func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+		return nil, nil
+	})
+
+	require.Len(t, result.Replicas, 3)
+	assert.ErrorContains(t, result.Replicas[0].Err, "connection refused")
+	assert.True(t, result.Replicas[1].Skipped())
+	assert.True(t, result.Replicas[2].Skipped())
+	assert.Empty(t, result.Succeeded())
+}
+
+func Test_target_syncReplicas_cancelled(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+
+	target := target{Replicas: []pihole.Client{replica}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := target.syncReplicas(ctx, func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+		return nil, nil
+	})
+
+	require.Len(t, result.Replicas, 1)
+	assert.ErrorIs(t, result.Replicas[0].Err, context.Canceled)
+}
+
+func Test_runReplica_syncError(t *testing.T) {
+	replica := piholemock.NewClient(t)
+
+	replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	_, err := runReplica(context.Background(), replica, func(ctx context.Context, replica pihole.Client) ([]ActionResult, error) {
+		return nil, errors.New("patch failed")
+	})
+	assert.EqualError(t, err, "patch failed")
+}
+
+func Test_syncTeleporter(t *testing.T) {
+	replica := piholemock.NewClient(t)
+
 	manualGravity := config.ManualGravity{
 		DHCPLeases:        false,
 		Group:             false,
@@ -200,30 +351,22 @@ func Test_target_syncTeleporters(t *testing.T) {
 		ClientByGroup:     false,
 	}
 
-	primary.
-		EXPECT().
-		GetTeleporter().
-		Times(1).
-		Return([]byte{}, nil)
+	replica.EXPECT().String().Return("http://ph2.example.com")
 	replica.
 		EXPECT().
-		PostTeleporter([]byte{}, createPostTeleporterRequest(&manualGravity)).
+		PostTeleporter(mock.Anything, []byte{}, createPostTeleporterRequest(&manualGravity)).
 		Times(1).
 		Return(nil)
 
-	err := target.syncTeleporters(&manualGravity)
+	target := target{}
+	imported, err := target.syncTeleporter(context.Background(), replica, []byte{}, createPostTeleporterRequest(&manualGravity), nil)
 	assert.NoError(t, err)
+	assert.True(t, imported)
 }
 
-func Test_target_syncConfigs(t *testing.T) {
-	primary := piholemock.NewClient(t)
+func Test_syncConfig(t *testing.T) {
 	replica := piholemock.NewClient(t)
 
-	target := target{
-		Primary:  primary,
-		Replicas: []pihole.Client{replica},
-	}
-
 	configResponse := model.ConfigResponse{Config: make(map[string]interface{})}
 
 	manualConfig := config.ManualConfig{
@@ -238,17 +381,98 @@ func Test_target_syncConfigs(t *testing.T) {
 		Debug:     false,
 	}
 
-	primary.
-		EXPECT().
-		GetConfig().
-		Times(1).
-		Return(&configResponse, nil)
-	replica.
-		EXPECT().
-		PatchConfig(createPatchConfigRequest(&manualConfig, &configResponse)).
-		Times(1).
-		Return(nil)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+
+	target := target{}
+	patched, err := target.syncConfig(context.Background(), replica, createPatchConfigRequest(&manualConfig, &configResponse))
+	assert.NoError(t, err)
+	assert.False(t, patched)
+	replica.AssertNotCalled(t, "PatchConfig", mock.Anything, mock.Anything)
+}
+
+func Test_target_syncConfig_postSyncChanged(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{PostSync: model.PostSync{RestartDNS: model.ActionChanged}})
+
+	configResponse := model.ConfigResponse{Config: map[string]interface{}{
+		"dns": map[string]interface{}{"port": float64(53)},
+	}}
+	configRequest := createPatchConfigRequest(&config.ManualConfig{DNS: true}, &configResponse)
+
+	replica.EXPECT().GetConfig(mock.Anything).Times(1).Return(&configResponse, nil)
+	replica.EXPECT().PatchConfig(mock.Anything, configRequest).Times(1).Return(nil)
+
+	target := target{}
+	changed, err := target.syncConfig(context.Background(), replica, configRequest)
+	assert.NoError(t, err)
+	assert.False(t, changed, "an unchanged config is patched without change detection, but not reported as changed")
+}
+
+func Test_target_syncTeleporter_unchanged(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+
+	teleporter := createTeleporter(t, map[string]string{"etc/hosts": "127.0.0.1 localhost"})
+	fingerprint, err := newTeleporterFingerprint(teleporter, nil)
+	require.NoError(t, err)
+
+	target := target{
+		Options: Options{ChangeDetection: true},
+		state:   newStateStore(""),
+	}
+
+	replica.EXPECT().PostTeleporter(mock.Anything, teleporter, (*model.PostTeleporterRequest)(nil)).Times(1).Return(nil)
+
+	imported, err := target.syncTeleporter(context.Background(), replica, teleporter, nil, fingerprint)
+	require.NoError(t, err)
+	assert.True(t, imported)
+	imported, err = target.syncTeleporter(context.Background(), replica, teleporter, nil, fingerprint)
+	require.NoError(t, err)
+	assert.False(t, imported)
+}
+
+func Test_target_syncConfig_unchanged(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+
+	target := target{
+		Options: Options{ChangeDetection: true},
+		state:   newStateStore(""),
+	}
+
+	configResponse := model.ConfigResponse{Config: map[string]interface{}{
+		"dns": map[string]interface{}{"port": float64(53)},
+	}}
+	configRequest := createPatchConfigRequest(&config.ManualConfig{DNS: true}, &configResponse)
+
+	replica.EXPECT().GetConfig(mock.Anything).Times(1).Return(&configResponse, nil)
+
+	patched, err := target.syncConfig(context.Background(), replica, configRequest)
+	assert.NoError(t, err)
+	assert.False(t, patched)
+}
+
+func Test_target_syncConfig_changed(t *testing.T) {
+	replica := piholemock.NewClient(t)
+	replica.EXPECT().String().Return("http://ph2.example.com")
+
+	target := target{
+		Options: Options{ChangeDetection: true},
+		state:   newStateStore(""),
+	}
+
+	configResponse := model.ConfigResponse{Config: map[string]interface{}{
+		"dns": map[string]interface{}{"port": float64(53)},
+	}}
+	configRequest := createPatchConfigRequest(&config.ManualConfig{DNS: true}, &configResponse)
+
+	replica.EXPECT().GetConfig(mock.Anything).Times(1).Return(&model.ConfigResponse{Config: map[string]interface{}{
+		"dns": map[string]interface{}{"port": float64(5353)},
+	}}, nil)
+	replica.EXPECT().PatchConfig(mock.Anything, configRequest).Times(1).Return(nil)
 
-	err := target.syncConfigs(&manualConfig)
+	patched, err := target.syncConfig(context.Background(), replica, configRequest)
 	assert.NoError(t, err)
+	assert.True(t, patched)
 }
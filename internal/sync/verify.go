@@ -0,0 +1,187 @@
+package sync
+
+import (
+	"context"
+	"fmt"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/rs/zerolog/log"
+	"sort"
+	"strings"
+)
+
+const (
+	gravityGroups  = "groups"
+	gravityLists   = "lists"
+	gravityDomains = "domains"
+	gravityClients = "clients"
+)
+
+// VerifyError lists the values that differ on a replica after a sync, Old is the value found on the replica.
+type VerifyError struct {
+	Mismatches []Change
+}
+
+func (e *VerifyError) Error() string {
+	mismatches := make([]string, len(e.Mismatches))
+	for i, mismatch := range e.Mismatches {
+		mismatches[i] = fmt.Sprintf("%s (expected %s, got %s)", mismatch.Path, formatValue(mismatch.New), formatValue(mismatch.Old))
+	}
+	return fmt.Sprintf("verification failed: %s", strings.Join(mismatches, ", "))
+}
+
+type gravityCounts map[string]int
+
+// verifiedGravity returns the gravity tables that an import with the request replaces, nil means a full import.
+func verifiedGravity(teleporterRequest *model.PostTeleporterRequest) []string {
+	if teleporterRequest == nil {
+		return []string{gravityGroups, gravityLists, gravityDomains, gravityClients}
+	}
+
+	var kinds []string
+	if teleporterRequest.Gravity.Group {
+		kinds = append(kinds, gravityGroups)
+	}
+	if teleporterRequest.Gravity.Adlist {
+		kinds = append(kinds, gravityLists)
+	}
+	if teleporterRequest.Gravity.Domainlist {
+		kinds = append(kinds, gravityDomains)
+	}
+	if teleporterRequest.Gravity.Client {
+		kinds = append(kinds, gravityClients)
+	}
+	return kinds
+}
+
+func countGravity(ctx context.Context, client pihole.Client, kinds []string) (gravityCounts, error) {
+	counts := make(gravityCounts, len(kinds))
+	for _, kind := range kinds {
+		var count int
+		switch kind {
+		case gravityGroups:
+			response, err := client.GetGroups(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("get groups: %w", err)
+			}
+			count = len(response.Groups)
+		case gravityLists:
+			response, err := client.GetLists(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("get lists: %w", err)
+			}
+			count = len(response.Lists)
+		case gravityDomains:
+			response, err := client.GetDomains(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("get domains: %w", err)
+			}
+			count = len(response.Domains)
+		case gravityClients:
+			response, err := client.GetClients(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("get clients: %w", err)
+			}
+			count = len(response.Clients)
+		}
+		counts[kind] = count
+	}
+	return counts, nil
+}
+
+// primaryGravityCounts counts the primary gravity tables a sync is verified against, or returns nil if verification is disabled.
+func (target *target) primaryGravityCounts(ctx context.Context, teleporterRequest *model.PostTeleporterRequest) (gravityCounts, error) {
+	if !target.Options.Verify {
+		return nil, nil
+	}
+
+	counts, err := countGravity(ctx, target.Primary, verifiedGravity(teleporterRequest))
+	if err != nil {
+		return nil, fmt.Errorf("count gravity: %w", err)
+	}
+	return counts, nil
+}
+
+func verifyGravity(ctx context.Context, replica pihole.Client, expected gravityCounts) error {
+	kinds := make([]string, 0, len(expected))
+	for kind := range expected {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	actual, err := countGravity(ctx, replica, kinds)
+	if err != nil {
+		return err
+	}
+
+	var mismatches []Change
+	for _, kind := range kinds {
+		if actual[kind] != expected[kind] {
+			mismatches = append(mismatches, Change{Path: "gravity." + kind, Type: ChangeChanged, Old: actual[kind], New: expected[kind]})
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return &VerifyError{Mismatches: mismatches}
+	}
+
+	log.Debug().Str("replica", replica.String()).Any("counts", actual).Msg("Gravity verified")
+	return nil
+}
+
+func verifyConfig(ctx context.Context, replica pihole.Client, configRequest *model.PatchConfigRequest) error {
+	desired, err := patchConfigSections(configRequest)
+	if err != nil {
+		return err
+	}
+	return verifySections(ctx, replica, desired)
+}
+
+// verifyFullConfig checks the config of a replica after a full sync against the full primary config with the overrides of
+// the replica. model.SensitivePaths are host-specific and not compared, unless overridden.
+func verifyFullConfig(ctx context.Context, replica pihole.Client, primaryConfig *model.ConfigResponse) error {
+	desired, err := desiredConfig(replica.PiHole(), primaryConfig, nil)
+	if err != nil {
+		return err
+	}
+	return verifySections(ctx, replica, desired)
+}
+
+func verifySections(ctx context.Context, replica pihole.Client, desired map[string]interface{}) error {
+	current, err := replica.GetConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("get config: %w", err)
+	}
+
+	if mismatches := diffConfig(desired, current.Config); len(mismatches) > 0 {
+		return &VerifyError{Mismatches: mismatches}
+	}
+
+	log.Debug().Str("replica", replica.String()).Msg("Config verified")
+	return nil
+}
+
+// omitSensitive removes the values of model.SensitivePaths from config sections.
+func omitSensitive(prefix []string, sections map[string]interface{}) {
+	for key, value := range sections {
+		path := append(prefix[:len(prefix):len(prefix)], key)
+		if model.IsSensitive(path) {
+			delete(sections, key)
+		} else if nested, ok := value.(map[string]interface{}); ok {
+			omitSensitive(path, nested)
+		}
+	}
+}
+
+// primaryConfig gets the primary config a full sync is verified against, or returns nil if verification is disabled.
+func (target *target) primaryConfig(ctx context.Context) (*model.ConfigResponse, error) {
+	if !target.Options.Verify {
+		return nil, nil
+	}
+
+	configResponse, err := target.Primary.GetConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get config: %w", err)
+	}
+	return configResponse, nil
+}
@@ -0,0 +1,127 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"github.com/lovelaze/nebula-sync/internal/config"
+	piholemock "github.com/lovelaze/nebula-sync/internal/mocks/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole"
+	"github.com/lovelaze/nebula-sync/internal/pihole/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTarget_ManualSync_verify(t *testing.T) {
+	primary := piholemock.NewClient(t)
+	replica := piholemock.NewClient(t)
+
+	target := NewTarget(primary, []pihole.Client{replica}, Options{Verify: true})
+
+	settings := config.SyncSettings{
+		Gravity: &config.ManualGravity{Group: true},
+		Config:  &config.ManualConfig{DNS: true},
+	}
+	primaryConfig := &model.ConfigResponse{Config: map[string]interface{}{
+		"dns": map[string]interface{}{"port": float64(53), "interface": "eth0"},
+	}}
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{})
+
+	primary.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	primary.EXPECT().GetTeleporter(mock.Anything).Times(1).Return([]byte{}, nil)
+	primary.EXPECT().GetConfig(mock.Anything).Times(1).Return(primaryConfig, nil)
+	primary.EXPECT().GetGroups(mock.Anything).Times(1).Return(&model.GroupsResponse{Groups: []model.Group{{Name: "Default"}, {Name: "Kids"}}}, nil)
+	primary.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().PostTeleporter(mock.Anything, mock.Anything, mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().PatchConfig(mock.Anything, mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().GetConfig(mock.Anything).Times(1).Return(&model.ConfigResponse{Config: map[string]interface{}{
+		"dns": map[string]interface{}{"port": float64(53), "interface": "eth1"},
+	}}, nil)
+	replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	result, err := target.ManualSync(context.Background(), &settings)
+	require.NoError(t, err)
+
+	var verifyErr *VerifyError
+	require.True(t, errors.As(result.Replicas[0].Err, &verifyErr))
+	assert.Equal(t, []Change{{Path: "dns.interface", Type: ChangeChanged, Old: "eth1", New: "eth0"}}, verifyErr.Mismatches)
+	assert.EqualError(t, result.Replicas[0].Err, `verify: verification failed: dns.interface (expected "eth0", got "eth1")`)
+}
+
+func TestTarget_FullSync_verify(t *testing.T) {
+	primary := piholemock.NewClient(t)
+	replica := piholemock.NewClient(t)
+
+	target := NewTarget(primary, []pihole.Client{replica}, Options{Verify: true})
+
+	primaryConfig := &model.ConfigResponse{Config: map[string]interface{}{
+		"dns":       map[string]interface{}{"port": float64(53), "interface": "eth0"},
+		"webserver": map[string]interface{}{"api": map[string]interface{}{"pwhash": "primary-hash"}},
+	}}
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().PiHole().Return(model.PiHole{Overrides: map[string]interface{}{"dns.interface": "eth1"}})
+
+	primary.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	primary.EXPECT().GetTeleporter(mock.Anything).Times(1).Return([]byte{}, nil)
+	primary.EXPECT().GetConfig(mock.Anything).Times(1).Return(primaryConfig, nil)
+	primary.EXPECT().GetGroups(mock.Anything).Times(1).Return(&model.GroupsResponse{}, nil)
+	primary.EXPECT().GetLists(mock.Anything).Times(1).Return(&model.ListsResponse{}, nil)
+	primary.EXPECT().GetDomains(mock.Anything).Times(1).Return(&model.DomainsResponse{}, nil)
+	primary.EXPECT().GetClients(mock.Anything).Times(1).Return(&model.ClientsResponse{}, nil)
+	primary.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	replica.EXPECT().Authenticate(mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().PostTeleporter(mock.Anything, mock.Anything, mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().PatchConfig(mock.Anything, mock.Anything).Times(1).Return(nil)
+	replica.EXPECT().GetConfig(mock.Anything).Times(1).Return(&model.ConfigResponse{Config: map[string]interface{}{
+		"dns":       map[string]interface{}{"port": float64(5353), "interface": "eth1"},
+		"webserver": map[string]interface{}{"api": map[string]interface{}{"pwhash": "replica-hash"}},
+	}}, nil)
+	replica.EXPECT().DeleteSession(mock.Anything).Times(1).Return(nil)
+
+	result, err := target.FullSync(context.Background())
+	require.NoError(t, err)
+
+	var verifyErr *VerifyError
+	require.True(t, errors.As(result.Replicas[0].Err, &verifyErr))
+	assert.Equal(t, []Change{{Path: "dns.port", Type: ChangeChanged, Old: float64(5353), New: float64(53)}}, verifyErr.Mismatches)
+	assert.Equal(t, "primary-hash", primaryConfig.Config["webserver"].(map[string]interface{})["api"].(map[string]interface{})["pwhash"])
+}
+
+func Test_verifyGravity(t *testing.T) {
+	replica := piholemock.NewClient(t)
+
+	replica.EXPECT().GetGroups(mock.Anything).Times(1).Return(&model.GroupsResponse{Groups: []model.Group{{Name: "Default"}}}, nil)
+	replica.EXPECT().GetLists(mock.Anything).Times(1).Return(&model.ListsResponse{Lists: []model.List{{Address: "https://example.com/list.txt"}}}, nil)
+
+	err := verifyGravity(context.Background(), replica, gravityCounts{gravityGroups: 2, gravityLists: 1})
+
+	var verifyErr *VerifyError
+	require.True(t, errors.As(err, &verifyErr))
+	assert.Equal(t, []Change{{Path: "gravity.groups", Type: ChangeChanged, Old: 1, New: 2}}, verifyErr.Mismatches)
+}
+
+func Test_verifyGravity_match(t *testing.T) {
+	replica := piholemock.NewClient(t)
+
+	replica.EXPECT().String().Return("http://ph2.example.com")
+	replica.EXPECT().GetDomains(mock.Anything).Times(1).Return(&model.DomainsResponse{Domains: []model.Domain{{Domain: "example.com"}}}, nil)
+	replica.EXPECT().GetClients(mock.Anything).Times(1).Return(&model.ClientsResponse{}, nil)
+
+	err := verifyGravity(context.Background(), replica, gravityCounts{gravityDomains: 1, gravityClients: 0})
+	assert.NoError(t, err)
+}
+
+func Test_verifiedGravity(t *testing.T) {
+	assert.Equal(t, []string{gravityGroups, gravityLists, gravityDomains, gravityClients}, verifiedGravity(nil))
+	assert.Equal(t, []string{gravityLists, gravityClients}, verifiedGravity(&model.PostTeleporterRequest{
+		Gravity: model.PostGravityRequest{Adlist: true, Client: true, ClientByGroup: true},
+	}))
+	assert.Empty(t, verifiedGravity(&model.PostTeleporterRequest{}))
+}